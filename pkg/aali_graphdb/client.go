@@ -24,12 +24,16 @@ package aali_graphdb
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 
+	"github.com/ansys/aali-sharedtypes/pkg/logging"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -52,28 +56,53 @@ func DefaultClient(address string) (*Client, error) {
 	return NewClient(address, http.DefaultClient)
 }
 
-func (client Client) post(u string, body any) (*http.Response, error) {
-	jsonReq, err := json.Marshal(body)
-	if err != nil {
-		return nil, err
-
+// startSpan starts the span of a client call as child of the span of ctx.
+func startSpan(ctx context.Context, operation string, db string) (context.Context, trace.Span) {
+	attributes := []attribute.KeyValue{attribute.String("db.system", "aali-graphdb"), attribute.String("db.operation.name", operation)}
+	if db != "" {
+		attributes = append(attributes, attribute.String("db.namespace", db))
 	}
-	req, err := http.NewRequest(http.MethodPost, u, bytes.NewBuffer(jsonReq))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("accept", "*/*")
+	return logging.StartSpan(ctx, "graphdb."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+}
+
+func (client Client) do(ctx context.Context, method string, u string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
 	if err != nil {
 		return nil, err
 	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("accept", "*/*")
+	}
+	logging.InjectHTTPHeaders(logging.FromContext(ctx), req.Header)
 
 	return client.httpClient.Do(req)
 }
 
+func (client Client) post(ctx context.Context, u string, body any) (*http.Response, error) {
+	jsonReq, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+
+	}
+	return client.do(ctx, http.MethodPost, u, bytes.NewBuffer(jsonReq))
+}
+
 func (client Client) GetHealth() (bool, error) {
+	return client.GetHealthCtx(context.Background())
+}
+
+// GetHealthCtx is GetHealth using ctx: the call is canceled with it, traced as child of its span and sends its
+// log context (see logging.NewContext) and trace context as headers.
+func (client Client) GetHealthCtx(ctx context.Context) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "GetHealth", "")
+	defer func() { logging.EndSpan(span, err) }()
+
 	url, err := url.JoinPath(client.address, "health")
 	if err != nil {
 		return false, err
 	}
-	resp, err := client.httpClient.Get(url)
+	resp, err := client.do(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return false, err
 	}
@@ -95,11 +124,19 @@ type getDatabasesResponse struct {
 }
 
 func (client Client) GetDatabases() ([]string, error) {
+	return client.GetDatabasesCtx(context.Background())
+}
+
+// GetDatabasesCtx is GetDatabases using ctx, see GetHealthCtx.
+func (client Client) GetDatabasesCtx(ctx context.Context) (_ []string, err error) {
+	ctx, span := startSpan(ctx, "GetDatabases", "")
+	defer func() { logging.EndSpan(span, err) }()
+
 	url, err := url.JoinPath(client.address, "databases")
 	if err != nil {
 		return nil, err
 	}
-	resp, err := client.httpClient.Get(url)
+	resp, err := client.do(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -123,12 +160,20 @@ func (client Client) GetDatabases() ([]string, error) {
 }
 
 func (client Client) CreateDatabase(name string) error {
+	return client.CreateDatabaseCtx(context.Background(), name)
+}
+
+// CreateDatabaseCtx is CreateDatabase using ctx, see GetHealthCtx.
+func (client Client) CreateDatabaseCtx(ctx context.Context, name string) (err error) {
+	ctx, span := startSpan(ctx, "CreateDatabase", name)
+	defer func() { logging.EndSpan(span, err) }()
+
 	u, err := url.JoinPath(client.address, "databases")
 	if err != nil {
 		return err
 	}
 
-	resp, err := client.post(u, map[string]any{"name": name, "in_memory": false})
+	resp, err := client.post(ctx, u, map[string]any{"name": name, "in_memory": false})
 	if err != nil {
 		return err
 	}
@@ -145,15 +190,19 @@ func (client Client) CreateDatabase(name string) error {
 }
 
 func (client Client) DeleteDatabase(name string) error {
+	return client.DeleteDatabaseCtx(context.Background(), name)
+}
+
+// DeleteDatabaseCtx is DeleteDatabase using ctx, see GetHealthCtx.
+func (client Client) DeleteDatabaseCtx(ctx context.Context, name string) (err error) {
+	ctx, span := startSpan(ctx, "DeleteDatabase", name)
+	defer func() { logging.EndSpan(span, err) }()
+
 	u, err := url.JoinPath(client.address, "databases", name)
 	if err != nil {
 		return err
 	}
-	req, err := http.NewRequest("DELETE", u, nil)
-	if err != nil {
-		return err
-	}
-	resp, err := client.httpClient.Do(req)
+	resp, err := client.do(ctx, http.MethodDelete, u, nil)
 	if err != nil {
 		return err
 	}
@@ -173,6 +222,14 @@ type cypherQueryResponse[T any] struct {
 }
 
 func CypherQueryReadGeneric[T any](client *Client, db string, cypher string, parameters Parameters) ([]T, error) {
+	return CypherQueryReadGenericCtx[T](context.Background(), client, db, cypher, parameters)
+}
+
+// CypherQueryReadGenericCtx is CypherQueryReadGeneric using ctx, see GetHealthCtx.
+func CypherQueryReadGenericCtx[T any](ctx context.Context, client *Client, db string, cypher string, parameters Parameters) (_ []T, err error) {
+	ctx, span := startSpan(ctx, "CypherQueryRead", db)
+	defer func() { logging.EndSpan(span, err) }()
+
 	u, err := url.JoinPath(client.address, "databases", db, "read")
 	if err != nil {
 		return nil, err
@@ -186,7 +243,7 @@ func CypherQueryReadGeneric[T any](client *Client, db string, cypher string, par
 		}
 	}
 
-	resp, err := client.post(u, map[string]any{"cypher": cypher, "parameters": params})
+	resp, err := client.post(ctx, u, map[string]any{"cypher": cypher, "parameters": params})
 	if err != nil {
 		return nil, err
 	}
@@ -214,7 +271,20 @@ func (client *Client) CypherQueryRead(db string, cypher string, parameters Param
 	return CypherQueryReadGeneric[map[string]any](client, db, cypher, parameters)
 }
 
+// CypherQueryReadCtx is CypherQueryRead using ctx, see GetHealthCtx.
+func (client *Client) CypherQueryReadCtx(ctx context.Context, db string, cypher string, parameters Parameters) ([]map[string]any, error) {
+	return CypherQueryReadGenericCtx[map[string]any](ctx, client, db, cypher, parameters)
+}
+
 func CypherQueryWriteGeneric[T any](client *Client, db string, cypher string, parameters Parameters) ([]T, error) {
+	return CypherQueryWriteGenericCtx[T](context.Background(), client, db, cypher, parameters)
+}
+
+// CypherQueryWriteGenericCtx is CypherQueryWriteGeneric using ctx, see GetHealthCtx.
+func CypherQueryWriteGenericCtx[T any](ctx context.Context, client *Client, db string, cypher string, parameters Parameters) (_ []T, err error) {
+	ctx, span := startSpan(ctx, "CypherQueryWrite", db)
+	defer func() { logging.EndSpan(span, err) }()
+
 	u, err := url.JoinPath(client.address, "databases", db, "write")
 	if err != nil {
 		return nil, err
@@ -228,7 +298,7 @@ func CypherQueryWriteGeneric[T any](client *Client, db string, cypher string, pa
 		}
 	}
 
-	resp, err := client.post(u, map[string]any{"cypher": cypher, "parameters": params})
+	resp, err := client.post(ctx, u, map[string]any{"cypher": cypher, "parameters": params})
 	if err != nil {
 		return nil, err
 	}
@@ -256,6 +326,11 @@ func (client *Client) CypherQueryWrite(db string, cypher string, parameters Para
 	return CypherQueryWriteGeneric[map[string]any](client, db, cypher, parameters)
 }
 
+// CypherQueryWriteCtx is CypherQueryWrite using ctx, see GetHealthCtx.
+func (client *Client) CypherQueryWriteCtx(ctx context.Context, db string, cypher string, parameters Parameters) ([]map[string]any, error) {
+	return CypherQueryWriteGenericCtx[map[string]any](ctx, client, db, cypher, parameters)
+}
+
 type ParameterMap map[string]Value
 
 type Parameters interface {
@@ -27,14 +27,20 @@ import (
 	"flag"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"cloud.google.com/go/civil"
+	"github.com/ansys/aali-sharedtypes/pkg/logging"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // StdoutLogConsumer is a LogConsumer that prints the log to stdout
@@ -409,3 +415,36 @@ func TestErrorsReturned(t *testing.T) {
 		assert.Equal(t, expected, fmt.Sprint(err))
 	})
 }
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	logCtx := &logging.ContextMap{}
+	logCtx.Set(logging.InstructionGuid, "instruction-1")
+	parentCtx, parent := logging.StartSpan(logging.NewContext(context.Background(), logCtx), "agent")
+	client, err := DefaultClient(server.URL)
+	require.NoError(t, err)
+
+	_, err = client.CypherQueryReadCtx(parentCtx, "db", "MATCH (n) RETURN n", nil)
+	require.Error(t, err)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "graphdb.CypherQueryRead", spans[0].Name)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
+	assert.Equal(t, "instruction-1", headers.Get("x-aali-instruction-guid"))
+	assert.Contains(t, headers.Get("traceparent"), spans[0].SpanContext.SpanID().String())
+}
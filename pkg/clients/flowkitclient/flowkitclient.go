@@ -34,6 +34,8 @@ import (
 	"github.com/ansys/aali-sharedtypes/pkg/typeconverters"
 
 	"github.com/ansys/aali-sharedtypes/pkg/config"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
@@ -64,8 +66,8 @@ func ListFunctionsAndSaveToInteralStates(ctx *logging.ContextMap) error {
 	}
 	defer conn.Close()
 
-	// Create a context with a cancel
-	ctxWithCancel, cancel := context.WithCancel(context.Background())
+	// Create a context with a cancel, carrying the log context to propagate it to the server
+	ctxWithCancel, cancel := context.WithCancel(logging.NewContext(context.Background(), ctx))
 	defer cancel()
 
 	// Call ListFunctions
@@ -124,11 +126,18 @@ func ListFunctionsAndSaveToInteralStates(ctx *logging.ContextMap) error {
 // Returns:
 //   - map[string]sharedtypes.FilledInputOutput: the outputs of the function
 //   - error: an error message if the gRPC call fails
-func RunFunction(ctx *logging.ContextMap, functionName string, inputs map[string]sharedtypes.FilledInputOutput) (map[string]sharedtypes.FilledInputOutput, error) {
+func RunFunction(ctx *logging.ContextMap, functionName string, inputs map[string]sharedtypes.FilledInputOutput) (_ map[string]sharedtypes.FilledInputOutput, err error) {
+	// Trace the call; the span ends after the panic recovery, so a panic is recorded as an error
+	spanCtx, span := logging.StartSpan(logging.NewContext(context.Background(), ctx), "flowkit.RunFunction",
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attribute.String("aali.function.name", functionName)))
+	defer func() {
+		logging.EndSpan(span, err)
+	}()
 	defer func() {
 		r := recover()
 		if r != nil {
 			logging.Log.Errorf(ctx, "Panic occured in RunFunction: %v", r)
+			err = fmt.Errorf("panic occured in RunFunction: %v", r)
 		}
 	}()
 
@@ -139,8 +148,8 @@ func RunFunction(ctx *logging.ContextMap, functionName string, inputs map[string
 	}
 	defer conn.Close()
 
-	// Create a context with a cancel
-	ctxWithCancel, cancel := context.WithCancel(context.Background())
+	// Create a context with a cancel, carrying the log context and the span to propagate them to the server
+	ctxWithCancel, cancel := context.WithCancel(spanCtx)
 	defer cancel()
 
 	// Get function definition
@@ -211,11 +220,21 @@ func RunFunction(ctx *logging.ContextMap, functionName string, inputs map[string
 // Returns:
 //   - *chan string: a channel to stream the output
 //   - error: an error message if the gRPC call fails
-func StreamFunction(ctx *logging.ContextMap, functionName string, inputs map[string]sharedtypes.FilledInputOutput) (*chan string, error) {
+func StreamFunction(ctx *logging.ContextMap, functionName string, inputs map[string]sharedtypes.FilledInputOutput) (_ *chan string, err error) {
+	// Trace the call; the span ends with the stream, or here if the stream was not started, after the panic recovery
+	spanCtx, span := logging.StartSpan(logging.NewContext(context.Background(), ctx), "flowkit.StreamFunction",
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attribute.String("aali.function.name", functionName)))
+	streaming := false
+	defer func() {
+		if !streaming {
+			logging.EndSpan(span, err)
+		}
+	}()
 	defer func() {
 		r := recover()
 		if r != nil {
-			logging.Log.Errorf(ctx, "Panic occured in RunFunction: %v", r)
+			logging.Log.Errorf(ctx, "Panic occured in StreamFunction: %v", r)
+			err = fmt.Errorf("panic occured in StreamFunction: %v", r)
 		}
 	}()
 
@@ -225,8 +244,8 @@ func StreamFunction(ctx *logging.ContextMap, functionName string, inputs map[str
 		return nil, fmt.Errorf("unable to connect to external function gRPC: %v", err)
 	}
 
-	// Create a context with a cancel
-	ctxWithCancel, cancel := context.WithCancel(context.Background())
+	// Create a context with a cancel, carrying the log context and the span to propagate them to the server
+	ctxWithCancel, cancel := context.WithCancel(spanCtx)
 
 	// Get function definition
 	functionDef, ok := AvailableFunctions[functionName]
@@ -278,7 +297,8 @@ func StreamFunction(ctx *logging.ContextMap, functionName string, inputs map[str
 	streamChannel := make(chan string, 400)
 
 	// Receive the stream from the server
-	go receiveStreamFromServer(ctx, stream, &streamChannel, conn, cancel)
+	streaming = true
+	go receiveStreamFromServer(ctx, stream, &streamChannel, conn, cancel, span)
 
 	return &streamChannel, nil
 }
@@ -288,12 +308,20 @@ func StreamFunction(ctx *logging.ContextMap, functionName string, inputs map[str
 // Parameters:
 //   - stream: the stream from the server
 //   - streamChannel: the channel to send the stream to
-func receiveStreamFromServer(ctx *logging.ContextMap, stream aaliflowkitgrpc.ExternalFunctions_StreamFunctionClient, streamChannel *chan string, conn *grpc.ClientConn, cancel context.CancelFunc) {
+//   - span: the span of the call, ended when the stream ends
+func receiveStreamFromServer(ctx *logging.ContextMap, stream aaliflowkitgrpc.ExternalFunctions_StreamFunctionClient, streamChannel *chan string, conn *grpc.ClientConn, cancel context.CancelFunc, span trace.Span) {
+	// Close the connection and the channel and end the span when the stream ends, also on a panic
+	var streamErr error
 	defer func() {
 		r := recover()
 		if r != nil {
 			logging.Log.Errorf(ctx, "Panic occured in receiveStreamFromServer: %v", r)
+			streamErr = fmt.Errorf("panic occured in receiveStreamFromServer: %v", r)
 		}
+		conn.Close()
+		cancel()
+		close(*streamChannel)
+		logging.EndSpan(span, streamErr)
 	}()
 
 	// Receive the stream from the server
@@ -301,6 +329,7 @@ func receiveStreamFromServer(ctx *logging.ContextMap, stream aaliflowkitgrpc.Ext
 		res, err := stream.Recv()
 		if err != nil && err != io.EOF {
 			logging.Log.Errorf(ctx, "error receiving stream: %v", err)
+			streamErr = err
 		}
 
 		// Send the stream to the channel
@@ -311,11 +340,6 @@ func receiveStreamFromServer(ctx *logging.ContextMap, stream aaliflowkitgrpc.Ext
 			break
 		}
 	}
-
-	// Close the channel
-	conn.Close()
-	cancel()
-	close(*streamChannel)
 }
 
 // createClient creates a client to the external functions gRPC
@@ -325,8 +349,9 @@ func receiveStreamFromServer(ctx *logging.ContextMap, stream aaliflowkitgrpc.Ext
 //   - connection: the connection to the external functions gRPC
 //   - err: an error message if the client creation fails
 func createClient() (client aaliflowkitgrpc.ExternalFunctionsClient, connection *grpc.ClientConn, err error) {
-	// check if EXTERNALFUNCTIONS_ENDPOINT is set
-	if config.GlobalConfig.EXTERNALFUNCTIONS_ENDPOINT == "" {
+	// check if EXTERNALFUNCTIONS_ENDPOINT is set; the active config includes config reloads
+	activeConfig := config.Current()
+	if activeConfig.EXTERNALFUNCTIONS_ENDPOINT == "" {
 		return nil, nil, fmt.Errorf("config variable 'EXTERNALFUNCTIONS_ENDPOINT' is not set")
 	}
 
@@ -334,16 +359,16 @@ func createClient() (client aaliflowkitgrpc.ExternalFunctionsClient, connection
 	var scheme string
 	var address string
 	switch {
-	case strings.HasPrefix(config.GlobalConfig.EXTERNALFUNCTIONS_ENDPOINT, "https://"):
+	case strings.HasPrefix(activeConfig.EXTERNALFUNCTIONS_ENDPOINT, "https://"):
 		scheme = "https"
-		address = strings.TrimPrefix(config.GlobalConfig.EXTERNALFUNCTIONS_ENDPOINT, scheme+"://")
-	case strings.HasPrefix(config.GlobalConfig.EXTERNALFUNCTIONS_ENDPOINT, "http://"):
+		address = strings.TrimPrefix(activeConfig.EXTERNALFUNCTIONS_ENDPOINT, scheme+"://")
+	case strings.HasPrefix(activeConfig.EXTERNALFUNCTIONS_ENDPOINT, "http://"):
 		scheme = "http"
-		address = strings.TrimPrefix(config.GlobalConfig.EXTERNALFUNCTIONS_ENDPOINT, scheme+"://")
+		address = strings.TrimPrefix(activeConfig.EXTERNALFUNCTIONS_ENDPOINT, scheme+"://")
 	default:
 		// legacy support for endpoint definition without http or https in front
 		scheme = "http"
-		address = config.GlobalConfig.EXTERNALFUNCTIONS_ENDPOINT
+		address = activeConfig.EXTERNALFUNCTIONS_ENDPOINT
 	}
 
 	// Set up the gRPC dial options
@@ -357,9 +382,13 @@ func createClient() (client aaliflowkitgrpc.ExternalFunctionsClient, connection
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
 
+	// Propagate the log context of the calls as metadata
+	opts = append(opts, grpc.WithChainUnaryInterceptor(logging.UnaryClientInterceptor()))
+	opts = append(opts, grpc.WithChainStreamInterceptor(logging.StreamClientInterceptor()))
+
 	// Add the API key if it is set
-	if config.GlobalConfig.FLOWKIT_API_KEY != "" {
-		opts = append(opts, grpc.WithUnaryInterceptor(apiKeyInterceptor(config.GlobalConfig.FLOWKIT_API_KEY)))
+	if activeConfig.FLOWKIT_API_KEY != "" {
+		opts = append(opts, grpc.WithChainUnaryInterceptor(apiKeyInterceptor(activeConfig.FLOWKIT_API_KEY)))
 	}
 
 	// Set max message size to 1GB
@@ -393,9 +422,8 @@ func apiKeyInterceptor(apiKey string) grpc.UnaryClientInterceptor {
 		invoker grpc.UnaryInvoker,
 		opts ...grpc.CallOption,
 	) error {
-		// Add API key to the context metadata
-		md := metadata.Pairs("x-api-key", apiKey)
-		ctx = metadata.NewOutgoingContext(ctx, md)
+		// Add API key to the context metadata, keeping metadata added by other interceptors
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", apiKey)
 
 		// Invoke the RPC with the modified context
 		return invoker(ctx, method, req, reply, cc, opts...)
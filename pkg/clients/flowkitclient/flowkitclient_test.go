@@ -0,0 +1,191 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package flowkitclient
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/ansys/aali-sharedtypes/pkg/aaliflowkitgrpc"
+	"github.com/ansys/aali-sharedtypes/pkg/config"
+	"github.com/ansys/aali-sharedtypes/pkg/logging"
+	"github.com/ansys/aali-sharedtypes/pkg/sharedtypes"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// testServer is an external functions server echoing the inputs of the calls.
+type testServer struct {
+	aaliflowkitgrpc.UnimplementedExternalFunctionsServer
+	traceparent chan string
+}
+
+// RunFunction returns the inputs as outputs.
+func (server *testServer) RunFunction(ctx context.Context, request *aaliflowkitgrpc.FunctionInputs) (*aaliflowkitgrpc.FunctionOutputs, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	server.traceparent <- firstValue(md.Get("traceparent"))
+	outputs := []*aaliflowkitgrpc.FunctionOutput{}
+	for _, input := range request.Inputs {
+		outputs = append(outputs, &aaliflowkitgrpc.FunctionOutput{Name: input.Name, GoType: input.GoType, Value: input.Value})
+	}
+	return &aaliflowkitgrpc.FunctionOutputs{Name: request.Name, Outputs: outputs}, nil
+}
+
+// StreamFunction streams the values of the inputs.
+func (server *testServer) StreamFunction(request *aaliflowkitgrpc.FunctionInputs, stream grpc.ServerStreamingServer[aaliflowkitgrpc.StreamOutput]) error {
+	for i, input := range request.Inputs {
+		err := stream.Send(&aaliflowkitgrpc.StreamOutput{MessageCounter: int32(i), IsLast: i == len(request.Inputs)-1, Value: input.Value})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// firstValue returns the first of the metadata values, or an empty string if there is none.
+func firstValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// startTestServer starts an external functions server, points the config to it and records the spans in memory.
+func startTestServer(t *testing.T) (*testServer, *tracetest.InMemoryExporter) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &testServer{traceparent: make(chan string, 10)}
+	grpcServer := grpc.NewServer()
+	aaliflowkitgrpc.RegisterExternalFunctionsServer(grpcServer, server)
+	go func() { _ = grpcServer.Serve(listener) }()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previousProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+
+	previousConfig, previousLog, previousFunctions := config.GlobalConfig, logging.Log, AvailableFunctions
+	config.GlobalConfig = &config.Config{EXTERNALFUNCTIONS_ENDPOINT: "http://" + listener.Addr().String()}
+	logging.Log, err = logging.NewLogger(logging.Config{LogLevel: "fatal", ErrorFileLocation: t.TempDir() + "/error.log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	AvailableFunctions = map[string]*sharedtypes.FunctionDefinition{
+		"echo": {Name: "echo", Inputs: []sharedtypes.FunctionInput{{Name: "first", GoType: "string"}, {Name: "second", GoType: "string"}}},
+	}
+
+	t.Cleanup(func() {
+		grpcServer.Stop()
+		otel.SetTracerProvider(previousProvider)
+		_ = provider.Shutdown(context.Background())
+		_ = logging.Log.Close()
+		config.GlobalConfig, logging.Log, AvailableFunctions = previousConfig, previousLog, previousFunctions
+	})
+	return server, exporter
+}
+
+// echoInputs returns the inputs of the echo function.
+func echoInputs() map[string]sharedtypes.FilledInputOutput {
+	return map[string]sharedtypes.FilledInputOutput{
+		"first":  {Name: "first", GoType: "string", Value: "a"},
+		"second": {Name: "second", GoType: "string", Value: "b"},
+	}
+}
+
+// TestRunFunctionTracing tests the span of RunFunction and its propagation to the server
+func TestRunFunctionTracing(t *testing.T) {
+	server, exporter := startTestServer(t)
+
+	outputs, err := RunFunction(&logging.ContextMap{}, "echo", echoInputs())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outputs["second"].Value != "b" {
+		t.Errorf("Expected echoed outputs, got %v", outputs)
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "flowkit.RunFunction" || spans[0].Status.Code == codes.Error {
+		t.Fatalf("Expected a successful flowkit.RunFunction span, got %+v", spans)
+	}
+	traceparent := <-server.traceparent
+	if !strings.Contains(traceparent, spans[0].SpanContext.SpanID().String()) {
+		t.Errorf("Expected the span to be propagated to the server, got traceparent %q", traceparent)
+	}
+
+	// A panic is recorded as an error
+	exporter.Reset()
+	AvailableFunctions["broken"] = nil
+	_, err = RunFunction(&logging.ContextMap{}, "broken", echoInputs())
+	if err == nil {
+		t.Errorf("Expected the panic to be returned as an error")
+	}
+	spans = exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Status.Code != codes.Error {
+		t.Errorf("Expected a failed span, got %+v", spans)
+	}
+}
+
+// TestStreamFunctionTracing tests that the span of StreamFunction ends with the stream
+func TestStreamFunctionTracing(t *testing.T) {
+	_, exporter := startTestServer(t)
+
+	stream, err := StreamFunction(&logging.ContextMap{}, "echo", echoInputs())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	values := []string{}
+	for value := range *stream {
+		values = append(values, value)
+	}
+	if len(values) != 2 || values[0] != "a" || values[1] != "b" {
+		t.Errorf("Expected the streamed values, got %v", values)
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "flowkit.StreamFunction" || spans[0].Status.Code == codes.Error {
+		t.Fatalf("Expected a successful flowkit.StreamFunction span, got %+v", spans)
+	}
+
+	// Calls failing before the stream starts end the span with the error, also on a panic
+	exporter.Reset()
+	_, err = StreamFunction(&logging.ContextMap{}, "unknown", echoInputs())
+	if err == nil {
+		t.Errorf("Expected an error for an unknown function")
+	}
+	AvailableFunctions["broken"] = nil
+	_, err = StreamFunction(&logging.ContextMap{}, "broken", echoInputs())
+	if err == nil {
+		t.Errorf("Expected the panic to be returned as an error")
+	}
+	spans = exporter.GetSpans()
+	if len(spans) != 2 || spans[0].Status.Code != codes.Error || spans[1].Status.Code != codes.Error {
+		t.Errorf("Expected two failed spans, got %+v", spans)
+	}
+}
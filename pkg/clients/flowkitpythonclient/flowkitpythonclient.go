@@ -24,6 +24,7 @@ package flowkitpythonclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -31,8 +32,11 @@ import (
 
 	"github.com/ansys/aali-sharedtypes/pkg/clients/flowkitclient"
 	"github.com/ansys/aali-sharedtypes/pkg/config"
+	"github.com/ansys/aali-sharedtypes/pkg/logging"
 	"github.com/ansys/aali-sharedtypes/pkg/sharedtypes"
 	"github.com/ansys/aali-sharedtypes/pkg/typeconverters"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ListFunctionsAndSaveToInteralStates calls the FlowKit-Python API and saves the functions to internal states
@@ -49,15 +53,16 @@ func ListFunctionsAndSaveToInteralStates() (err error) {
 		}
 	}()
 
-	// Create a new HTTP GET request
-	req, err := http.NewRequest("GET", config.GlobalConfig.FLOWKIT_PYTHON_ENDPOINT, nil)
+	// Create a new HTTP GET request; the active config includes config reloads
+	activeConfig := config.Current()
+	req, err := http.NewRequest("GET", activeConfig.FLOWKIT_PYTHON_ENDPOINT, nil)
 	if err != nil {
 		errorMessage := fmt.Errorf("error creating GET request: %v", err)
 		return errorMessage
 	}
 
 	// Add the required header
-	req.Header.Set("api-key", config.GlobalConfig.FLOWKIT_PYTHON_API_KEY)
+	req.Header.Set("api-key", activeConfig.FLOWKIT_PYTHON_API_KEY)
 	req.Header.Set("Content-Type", "application/json")
 
 	// Create a client and make the request
@@ -158,6 +163,28 @@ func ListFunctionsAndSaveToInteralStates() (err error) {
 //   - map[string]sharedtypes.FilledInputOutput: the outputs of the function
 //   - error: an error message if the API call fails
 func RunFunction(functionName string, inputs map[string]sharedtypes.FilledInputOutput) (outputs map[string]sharedtypes.FilledInputOutput, err error) {
+	return RunFunctionWithContext(nil, functionName, inputs)
+}
+
+// RunFunctionWithContext calls the external function server and returns the outputs
+// The call is traced as a child of the span stored in the log context, and the log context
+// and trace context are sent to the server as headers
+//
+// Parameters:
+//   - ctx: the log context of the call; may be nil
+//   - functionName: the name of the function to run
+//   - inputs: the inputs to the function
+//
+// Returns:
+//   - map[string]sharedtypes.FilledInputOutput: the outputs of the function
+//   - error: an error message if the API call fails
+func RunFunctionWithContext(ctx *logging.ContextMap, functionName string, inputs map[string]sharedtypes.FilledInputOutput) (outputs map[string]sharedtypes.FilledInputOutput, err error) {
+	// Trace the call; the span ends after the panic recovery, so a panic is recorded as an error
+	spanCtx, span := logging.StartSpan(logging.NewContext(context.Background(), ctx), "flowkitpython.RunFunction",
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attribute.String("aali.function.name", functionName)))
+	defer func() {
+		logging.EndSpan(span, err)
+	}()
 	defer func() {
 		r := recover()
 		if r != nil {
@@ -165,8 +192,9 @@ func RunFunction(functionName string, inputs map[string]sharedtypes.FilledInputO
 		}
 	}()
 
-	// check if endpoint is set
-	if config.GlobalConfig.FLOWKIT_PYTHON_ENDPOINT == "" {
+	// check if endpoint is set; the active config includes config reloads
+	activeConfig := config.Current()
+	if activeConfig.FLOWKIT_PYTHON_ENDPOINT == "" {
 		return nil, fmt.Errorf("config variable 'FLOWKIT_PYTHON_ENDPOINT' is not set")
 	}
 
@@ -193,15 +221,16 @@ func RunFunction(functionName string, inputs map[string]sharedtypes.FilledInputO
 	}
 
 	// Create a new HTTP POST request
-	req, err := http.NewRequest("POST", config.GlobalConfig.FLOWKIT_PYTHON_ENDPOINT+functionDefinition.Path, bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(spanCtx, "POST", activeConfig.FLOWKIT_PYTHON_ENDPOINT+functionDefinition.Path, bytes.NewBuffer(reqBody))
 	if err != nil {
 		errorMessage := fmt.Errorf("error creating POST request: %v", err)
 		return nil, errorMessage
 	}
 
-	// Add the required header
-	req.Header.Set("api-key", config.GlobalConfig.FLOWKIT_PYTHON_API_KEY)
+	// Add the required header, and the log and trace context
+	req.Header.Set("api-key", activeConfig.FLOWKIT_PYTHON_API_KEY)
 	req.Header.Set("Content-Type", "application/json")
+	logging.InjectHTTPHeaders(logging.FromContext(spanCtx), req.Header)
 
 	// Create a client and make the request
 	client := &http.Client{}
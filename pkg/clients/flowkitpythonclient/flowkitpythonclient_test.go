@@ -0,0 +1,96 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package flowkitpythonclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ansys/aali-sharedtypes/pkg/clients/flowkitclient"
+	"github.com/ansys/aali-sharedtypes/pkg/config"
+	"github.com/ansys/aali-sharedtypes/pkg/logging"
+	"github.com/ansys/aali-sharedtypes/pkg/sharedtypes"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestRunFunctionTracing tests the span of RunFunctionWithContext and its propagation to the server
+func TestRunFunctionTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previousProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"result": "done"})
+	}))
+
+	previousConfig, previousFunctions := config.GlobalConfig, flowkitclient.AvailableFunctions
+	config.GlobalConfig = &config.Config{FLOWKIT_PYTHON_ENDPOINT: server.URL}
+	flowkitclient.AvailableFunctions = map[string]*sharedtypes.FunctionDefinition{
+		"python": {Name: "python", Path: "/run", Outputs: []sharedtypes.FunctionOutput{{Name: "result", GoType: "string"}}},
+	}
+	t.Cleanup(func() {
+		server.Close()
+		otel.SetTracerProvider(previousProvider)
+		_ = provider.Shutdown(context.Background())
+		config.GlobalConfig, flowkitclient.AvailableFunctions = previousConfig, previousFunctions
+	})
+
+	logCtx := &logging.ContextMap{}
+	logCtx.Set(logging.InstructionGuid, "instruction-1")
+	outputs, err := RunFunctionWithContext(logCtx, "python", map[string]sharedtypes.FilledInputOutput{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outputs["result"].Value != "done" {
+		t.Errorf("Expected the outputs of the server, got %v", outputs)
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "flowkitpython.RunFunction" || spans[0].Status.Code == codes.Error {
+		t.Fatalf("Expected a successful flowkitpython.RunFunction span, got %+v", spans)
+	}
+	if headers.Get("x-aali-instruction-guid") != "instruction-1" || !strings.Contains(headers.Get("traceparent"), spans[0].SpanContext.SpanID().String()) {
+		t.Errorf("Expected the log and trace context to be sent to the server, got %v", headers)
+	}
+
+	// A panic is recorded as an error
+	exporter.Reset()
+	flowkitclient.AvailableFunctions["broken"] = nil
+	_, err = RunFunctionWithContext(logCtx, "broken", map[string]sharedtypes.FilledInputOutput{})
+	if err == nil {
+		t.Errorf("Expected the panic to be returned as an error")
+	}
+	spans = exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Status.Code != codes.Error {
+		t.Errorf("Expected a failed span, got %+v", spans)
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+////////////////////////////////
+// Deprecated key aliases
+////////////////////////////////
+
+// CanonicalKey returns the current YAML key of a config field for a deprecated alias.
+// Aliases are declared with the `alias` tag of a field; the JSON form of an alias is the alias without underscores.
+//
+// Parameters:
+//   - key: A YAML key or JSON tag, possibly a deprecated alias.
+//
+// Returns:
+//   - string: The canonical YAML key of the field, or key itself if it is no alias.
+//   - bool: True if key is a deprecated alias.
+func CanonicalKey(key string) (string, bool) {
+	configType := reflect.TypeOf(Config{})
+	for i := 0; i < configType.NumField(); i++ {
+		for _, alias := range fieldAliases(configType.Field(i)) {
+			if key == alias || key == jsonAlias(alias) {
+				return yamlKey(configType.Field(i)), true
+			}
+		}
+	}
+	return key, false
+}
+
+// fieldAliases returns the deprecated YAML keys of a config field.
+//
+// Parameters:
+//   - field: The config field.
+//
+// Returns:
+//   - []string: The aliases from the `alias` tag.
+func fieldAliases(field reflect.StructField) []string {
+	tag := field.Tag.Get("alias")
+	if tag == "" {
+		return nil
+	}
+	return strings.Split(tag, ",")
+}
+
+// canonicalProperty returns the field name for a property name given by a service, e.g. a required property,
+// and logs a warning if the name is a deprecated alias.
+//
+// Parameters:
+//   - property: The field name or a deprecated alias.
+//   - source: Where the property name was given, for the warning.
+//
+// Returns:
+//   - string: The field name.
+func canonicalProperty(property string, source string) string {
+	canonical, deprecated := CanonicalKey(property)
+	if deprecated {
+		log.Printf("Warning: %v: %v", source, deprecationMessage(property, canonical))
+	}
+	return canonical
+}
+
+// isDeprecatedField reports whether a config field is the deprecated copy of a renamed field.
+// These fields are kept for code reading the old Go field names; they are no config keys and have no flags.
+//
+// Parameters:
+//   - field: The config field.
+//
+// Returns:
+//   - bool: True if the field has a `deprecated` tag.
+func isDeprecatedField(field reflect.StructField) bool {
+	return field.Tag.Get("deprecated") != ""
+}
+
+// syncDeprecatedFields copies the value of every renamed field into its deprecated copy.
+//
+// Parameters:
+//   - config: The configuration to update.
+func syncDeprecatedFields(config *Config) {
+	configValue := reflect.ValueOf(config).Elem()
+	configType := configValue.Type()
+	for i := 0; i < configType.NumField(); i++ {
+		canonical := configType.Field(i).Tag.Get("deprecated")
+		if canonical != "" {
+			configValue.Field(i).Set(configValue.FieldByName(canonical))
+		}
+	}
+}
+
+// jsonAlias returns the JSON tag form of a YAML alias, following the convention of the JSON tags of Config.
+//
+// Parameters:
+//   - alias: The YAML alias.
+//
+// Returns:
+//   - string: The alias without underscores.
+func jsonAlias(alias string) string {
+	return strings.ReplaceAll(alias, "_", "")
+}
+
+// deprecationMessage returns the message for the use of a deprecated alias.
+//
+// Parameters:
+//   - alias: The alias.
+//   - canonical: The key to use instead.
+//
+// Returns:
+//   - string: The message.
+func deprecationMessage(alias string, canonical string) string {
+	return fmt.Sprintf("'%v' is deprecated, use '%v' instead", alias, canonical)
+}
+
+// warnDeprecatedSecretKey logs a warning if a secret key of a provider or key vault matched a config field by an alias.
+//
+// Parameters:
+//   - source: The provider or key vault the secret comes from.
+//   - key: The secret key.
+//   - fieldName: The name of the field the key matched.
+func warnDeprecatedSecretKey(source string, key string, fieldName string) {
+	field, ok := reflect.TypeOf(Config{}).FieldByName(fieldName)
+	if !ok {
+		return
+	}
+	normalized := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(key), "-", "_"))
+	for _, alias := range fieldAliases(field) {
+		if normalized == alias || normalized == jsonAlias(alias) {
+			log.Printf("Warning: %v: %v", source, deprecationMessage(key, yamlKey(field)))
+			return
+		}
+	}
+}
+
+// resolveAliases renames the deprecated keys of a config file to their canonical keys and records a warning for each.
+// If a file sets both an alias and its canonical key, the canonical key wins.
+//
+// Parameters:
+//   - fileName: The name of the file.
+//   - values: The top level values of the file; updated in place.
+//   - positions: The position of each key in the file; updated in place.
+func (layered *configFiles) resolveAliases(fileName string, values map[string]interface{}, positions map[string]keyPosition) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		suffix := ""
+		if strings.HasSuffix(key, appendSuffix) {
+			suffix = appendSuffix
+		}
+		canonical, deprecated := CanonicalKey(strings.TrimSuffix(key, appendSuffix))
+		if !deprecated {
+			continue
+		}
+		position := positions[key]
+		warning := &ParseError{File: fileName, Line: position.line, Column: position.column, Message: deprecationMessage(key, canonical+suffix)}
+		if _, exists := values[canonical+suffix]; exists {
+			warning.Message += fmt.Sprintf("; it is ignored because '%v' is also set", canonical+suffix)
+		} else {
+			values[canonical+suffix] = values[key]
+			positions[canonical+suffix] = position
+		}
+		layered.warnings = append(layered.warnings, warning.Error())
+		delete(values, key)
+		delete(positions, key)
+	}
+}
+
+// MarshalJSON encodes a config as JSON. For one release the value of every field with a deprecated alias
+// is also written under the JSON form of the alias (e.g. ANSYSDISCOCRYPTPRIVATKEY), so that consumers
+// of the JSON output can migrate to the new keys.
+//
+// Returns:
+//   - []byte: The JSON object.
+//   - error: An error if a value could not be encoded.
+func (config Config) MarshalJSON() ([]byte, error) {
+	type plainConfig Config
+	data, err := json.Marshal(plainConfig(config))
+	if err != nil {
+		return nil, err
+	}
+
+	var legacy bytes.Buffer
+	configValue := reflect.ValueOf(config)
+	configType := configValue.Type()
+	for i := 0; i < configType.NumField(); i++ {
+		for _, alias := range fieldAliases(configType.Field(i)) {
+			value, err := json.Marshal(configValue.Field(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(&legacy, ",%q:%s", jsonAlias(alias), value)
+		}
+	}
+	if legacy.Len() == 0 || !bytes.HasSuffix(data, []byte("}")) {
+		return data, nil
+	}
+	return append(append(data[:len(data)-1], legacy.Bytes()...), '}'), nil
+}
+
+// UnmarshalJSON decodes a config from JSON, accepting the deprecated aliases of the JSON tags.
+// If both an alias and its canonical tag are given, the canonical tag wins; an alias with the same value
+// as its canonical tag, as written by MarshalJSON, is ignored without a warning.
+//
+// Parameters:
+//   - data: The JSON object.
+//
+// Returns:
+//   - error: An error if the JSON is invalid or a value has the wrong type.
+func (config *Config) UnmarshalJSON(data []byte) error {
+	type plainConfig Config
+	var values map[string]json.RawMessage
+	err := json.Unmarshal(data, &values)
+	if err != nil || values == nil {
+		return json.Unmarshal(data, (*plainConfig)(config))
+	}
+
+	renamed := false
+	configType := reflect.TypeOf(Config{})
+	for i := 0; i < configType.NumField(); i++ {
+		tag := configType.Field(i).Tag.Get("json")
+		for _, alias := range fieldAliases(configType.Field(i)) {
+			value, ok := values[jsonAlias(alias)]
+			if !ok {
+				continue
+			}
+			canonicalValue, exists := values[tag]
+			if !exists || !bytes.Equal(canonicalValue, value) {
+				log.Printf("Warning: JSON config: %v", deprecationMessage(jsonAlias(alias), tag))
+			}
+			if !exists {
+				values[tag] = value
+			}
+			delete(values, jsonAlias(alias))
+			renamed = true
+		}
+	}
+	if renamed {
+		data, err = json.Marshal(values)
+		if err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(data, (*plainConfig)(config))
+}
@@ -0,0 +1,176 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestCanonicalKey tests the lookup of deprecated YAML and JSON aliases
+func TestCanonicalKey(t *testing.T) {
+	tests := []struct {
+		key        string
+		expected   string
+		deprecated bool
+	}{
+		{"ANSYS_DISCO_CRYPT_PRIVAT_KEY", "ANSYS_DISCO_CRYPT_PRIVATE_KEY", true},
+		{"ANSYSDISOCSIGNPUBLICKEY", "ANSYS_DISCO_SIGN_PUBLIC_KEY", true},
+		{"ANSYS_DISCO_SIGN_PUBLIC_KEY", "ANSYS_DISCO_SIGN_PUBLIC_KEY", false},
+		{"LOG_LEVEL", "LOG_LEVEL", false},
+	}
+	for _, tt := range tests {
+		key, deprecated := CanonicalKey(tt.key)
+		if key != tt.expected || deprecated != tt.deprecated {
+			t.Errorf("CanonicalKey(%q): expected %q, %v, got %q, %v", tt.key, tt.expected, tt.deprecated, key, deprecated)
+		}
+	}
+}
+
+// TestAliases tests that deprecated aliases are accepted by every config source
+func TestAliases(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": "ANSYS_DISCO_CRYPT_PRIVAT_KEY: file-key\nANSYS_DISOC_SIGN_PUBLIC_KEY: legacy\nANSYS_DISCO_SIGN_PUBLIC_KEY: current\n",
+	})
+
+	// Config file
+	fileName := filepath.Join(dir, "config.yaml")
+	layered, err := readConfigFiles(fileName, nil, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config, err := layered.decode(fileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.ANSYS_DISCO_CRYPT_PRIVATE_KEY != "file-key" || config.ANSYS_DISCO_SIGN_PUBLIC_KEY != "current" {
+		t.Errorf("Expected aliases to be resolved with the canonical key winning, got %q and %q", config.ANSYS_DISCO_CRYPT_PRIVATE_KEY, config.ANSYS_DISCO_SIGN_PUBLIC_KEY)
+	}
+	if len(layered.warnings) != 2 || !strings.Contains(layered.warnings[0], "line 1, column 1: 'ANSYS_DISCO_CRYPT_PRIVAT_KEY' is deprecated") ||
+		!strings.Contains(layered.warnings[1], "is ignored because 'ANSYS_DISCO_SIGN_PUBLIC_KEY' is also set") {
+		t.Errorf("Expected deprecation warnings, got %q", layered.warnings)
+	}
+
+	// Environment variables and command line flags
+	t.Setenv("TEST_ANSYS_DISCO_CRYPT_PRIVAT_KEY", "env-key")
+	config = Config{}
+	err = ApplyEnvironmentVariables(&config, "TEST_")
+	if err != nil || config.ANSYS_DISCO_CRYPT_PRIVATE_KEY != "env-key" {
+		t.Errorf("Expected environment alias to be applied, got %q (%v)", config.ANSYS_DISCO_CRYPT_PRIVATE_KEY, err)
+	}
+	err = applyCommandLineArguments(&config, []string{"-ANSYS_DISOC_SIGN_PUBLIC_KEY", "cli"}, nil)
+	if err != nil || config.ANSYS_DISCO_SIGN_PUBLIC_KEY != "cli" {
+		t.Errorf("Expected command line alias to be applied, got %q (%v)", config.ANSYS_DISCO_SIGN_PUBLIC_KEY, err)
+	}
+
+	// JSON tags
+	config = Config{}
+	err = json.Unmarshal([]byte(`{"ANSYSDISCOCRYPTPRIVATKEY": "json-key", "LOGLEVEL": "debug"}`), &config)
+	if err != nil || config.ANSYS_DISCO_CRYPT_PRIVATE_KEY != "json-key" || config.LOG_LEVEL != "debug" {
+		t.Errorf("Expected JSON alias to be applied, got %+v (%v)", config, err)
+	}
+
+	// Key Vault names
+	vault := &fakeKeyVault{secrets: map[string]string{"ANSYS-DISOC-SIGN-PUBLIC-KEY": "vault-key", "ANSYSDISCOCRYPTPRIVATKEY": "vault-private"}}
+	config = Config{}
+	err = LoadFromKeyVault(context.Background(), &config, vault, KeyVaultOptions{})
+	expected := Config{ANSYS_DISCO_SIGN_PUBLIC_KEY: "vault-key", ANSYS_DISCO_CRYPT_PRIVATE_KEY: "vault-private"}
+	if err != nil || !reflect.DeepEqual(config, expected) {
+		t.Errorf("Expected key vault aliases to be applied, got %+v (%v)", config, err)
+	}
+}
+
+// TestDeprecatedPropertyNames tests that services can still use the old names for properties and Go fields
+func TestDeprecatedPropertyNames(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(configFile, []byte("ANSYS_DISCO_CRYPT_PRIVATE_KEY: private\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := Load(LoadOptions{
+		FilePath:              configFile,
+		IgnoreEnvironment:     true,
+		RequiredProperties:    []string{"ANSYS_DISCO_CRYPT_PRIVAT_KEY"},
+		OptionalDefaultValues: map[string]interface{}{"ANSYS_DISOC_SIGN_PUBLIC_KEY": "public"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.ANSYS_DISCO_SIGN_PUBLIC_KEY != "public" {
+		t.Errorf("Expected the default of the deprecated name to be applied, got %q", config.ANSYS_DISCO_SIGN_PUBLIC_KEY)
+	}
+	if config.ANSYS_DISCO_CRYPT_PRIVAT_KEY != "private" || config.ANSYS_DISOC_SIGN_PUBLIC_KEY != "public" {
+		t.Errorf("Expected the deprecated fields to be copies, got %q and %q", config.ANSYS_DISCO_CRYPT_PRIVAT_KEY, config.ANSYS_DISOC_SIGN_PUBLIC_KEY)
+	}
+	if config.Redacted().ANSYS_DISCO_CRYPT_PRIVAT_KEY != RedactedValue {
+		t.Errorf("Expected the deprecated copy of the secret to be redacted")
+	}
+
+	_, err = Load(LoadOptions{FilePath: configFile, IgnoreEnvironment: true, RequiredProperties: []string{"ANSYS_DISOC_SIGN_PUBLIC_KEY"}})
+	if err == nil || !strings.Contains(err.Error(), "'ANSYS_DISCO_SIGN_PUBLIC_KEY'") {
+		t.Errorf("Expected the missing property under its current name, got %v", err)
+	}
+}
+
+// TestMarshalJSONLegacyKeys tests that the JSON output still contains the deprecated keys and round-trips
+func TestMarshalJSONLegacyKeys(t *testing.T) {
+	config := Config{LOG_LEVEL: "debug", ANSYS_DISCO_CRYPT_PRIVATE_KEY: "private", ANSYS_DISCO_SIGN_PUBLIC_KEY: "public"}
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var values map[string]interface{}
+	err = json.Unmarshal(data, &values)
+	if err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	for key, expected := range map[string]string{
+		"ANSYSDISCOCRYPTPRIVATEKEY": "private",
+		"ANSYSDISCOCRYPTPRIVATKEY":  "private",
+		"ANSYSDISCOSIGNPUBLICKEY":   "public",
+		"ANSYSDISOCSIGNPUBLICKEY":   "public",
+		"LOGLEVEL":                  "debug",
+	} {
+		if values[key] != expected {
+			t.Errorf("Expected %v to be %q, got %v", key, expected, values[key])
+		}
+	}
+
+	var decoded Config
+	err = json.Unmarshal(data, &decoded)
+	if err != nil || !reflect.DeepEqual(decoded, config) {
+		t.Errorf("Expected %+v after round trip, got %+v (%v)", config, decoded, err)
+	}
+
+	redacted, err := config.RedactedJSON()
+	if err != nil || strings.Contains(redacted, "private") {
+		t.Errorf("Expected legacy key of secret field to be redacted, got %v (%v)", redacted, err)
+	}
+}
@@ -28,16 +28,14 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"reflect"
-	"strconv"
 	"strings"
 	"time"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
 	"gopkg.in/yaml.v2"
 )
 
@@ -47,90 +45,208 @@ import (
 
 // InitConfig initializes the configuration for the Aali service.
 //
+// InitConfig is a wrapper around Load that reads the config file from AALI_CONFIG_PATH (or config.yaml),
+// the command line arguments of the process and the environment, and stores the result in GlobalConfig.
+// It panics if the configuration cannot be loaded or a required property is missing; values violating
+// the validation rules are only logged as warnings. With -h or -help it prints the command line help and exits.
+// Command line flags are only written to the config file if -persist-config is given.
+//
+// Config values are resolved with the following precedence (lowest to highest):
+// optional default values < config file < environment variables (AALI_<KEY>) < CLI flags < Azure Key Vault
+// < secret providers (SECRETS_DIRECTORY, SECRETS_DOTENV_FILE, SECRETS_HTTP_VAULT_URL).
+//
 // Parameters:
 //   - requiredProperties: The list of required properties.
 //   - optionalDefaultValues: The map of optional properties and their default values.
 func InitConfig(requiredProperties []string, optionalDefaultValues map[string]interface{}) {
+	initConfig(LoadOptions{
+		RequiredProperties:    requiredProperties,
+		OptionalDefaultValues: optionalDefaultValues,
+	})
+}
+
+// initConfig loads the configuration with the config file location and command line arguments of the process
+// and activates it as GlobalConfig. It panics if the configuration cannot be loaded.
+//
+// Parameters:
+//   - options: The options to load the config with; FilePath and Args are set by this function.
+func initConfig(options LoadOptions) {
 	// Get config file location
-	// 1st option: read from environment variable
-	configFile := os.Getenv("AALI_CONFIG_PATH")
-	if configFile == "" {
-		// 2nd option: read from default location... root directory
-		configFile = "config.yaml"
+	options.FilePath = defaultConfigFilePath()
+	options.Args = os.Args[1:]
+
+	// Only write the CLI flags to the config file if requested
+	if persistRequested(options.Args) {
+		err := PersistCommandLineArguments(options.FilePath, options.Args)
+		if err != nil {
+			writeErrorAndPanic("error in creating and/or updating configuration file from command line:", err)
+		}
 	}
 
-	// Get config properties from CLI
-	err := CreateUpdateConfigFileFromCLI(configFile)
+	// Load the config from all sources
+	config, provenance, err := LoadWithProvenance(options)
+	if errors.Is(err, flag.ErrHelp) {
+		CommandLineUsage(os.Stdout)
+		os.Exit(0)
+	}
 	if err != nil {
-		pan := writeStringToFile("error in creating and/or updating configuration file from command line:")
-		if pan != nil {
-			panic(pan)
+		writeErrorAndPanic("error in loading configuration:", err)
+	}
+
+	// Assign to global config; reloads reuse the same options, but only replace the active config
+	GlobalConfig = config
+	setActiveConfig(config, provenance, options)
+}
+
+// Load builds a new Config from the config file, environment variables, command line arguments,
+// optional default values and, if enabled in the config, Azure Key Vault and secret providers.
+// In contrast to InitConfig it does not modify GlobalConfig and does not panic.
+//
+// Config values are resolved with the following precedence (lowest to highest):
+// optional default values < config file < environment variables < CLI flags < Azure Key Vault < secret providers.
+//
+// Parameters:
+//   - options: The options controlling where the config is loaded from.
+//
+// Returns:
+//   - config: The loaded configuration.
+//   - err: An error joining all issues found while loading the configuration.
+func Load(options LoadOptions) (config *Config, err error) {
+	config, _, err = LoadWithProvenance(options)
+	return config, err
+}
+
+// LoadWithProvenance loads the configuration like Load and additionally records
+// the source of the effective value of every field.
+//
+// Parameters:
+//   - options: The options controlling where the config is loaded from.
+//
+// Returns:
+//   - config: The loaded configuration.
+//   - provenance: The origin of every config field.
+//   - err: An error joining all issues found while loading the configuration.
+func LoadWithProvenance(options LoadOptions) (config *Config, provenance Provenance, err error) {
+	fileName := options.FilePath
+	if fileName == "" {
+		fileName = defaultConfigFilePath()
+	}
+	prefix := options.EnvironmentPrefix
+	if prefix == "" {
+		prefix = EnvironmentVariablePrefix
+	}
+	provenance = newProvenance()
+
+	// Merge the service profile defaults with the given defaults
+	var profile ServiceProfile
+	optionalDefaultValues := options.OptionalDefaultValues
+	if options.Service != "" {
+		profile, err = GetServiceProfile(options.Service)
+		if err != nil {
+			return nil, nil, err
 		}
-		pan = writeInterfaceToFile(err)
-		if pan != nil {
-			panic(pan)
+		optionalDefaultValues = profile.OptionalDefaultValues
+		for key, value := range options.OptionalDefaultValues {
+			optionalDefaultValues[key] = value
 		}
-		panic(err)
 	}
 
-	// Initialize config From File
-	err = InitGlobalConfigFromFile(configFile, requiredProperties, optionalDefaultValues)
+	// Read config file with its includes and overlays
+	overlays, stage := layerSelection(options, prefix)
+	layered, err := readConfigFiles(fileName, overlays, stage, options.EncryptionKey)
 	if err != nil {
-		pan := writeStringToFile("error in reading configuration values from configuration file:")
-		if pan != nil {
-			panic(pan)
-		}
-		pan = writeInterfaceToFile(err)
-		if pan != nil {
-			panic(pan)
+		return nil, nil, err
+	}
+	layered.logWarnings()
+	loaded, err := layered.decode(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	layered.recordOrigins(provenance)
+
+	// Set optional properties if missing
+	var errs []error
+	beforeDefaults := loaded
+	err = defineOptionalProperties(&loaded, optionalDefaultValues)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	recordDefaults(beforeDefaults, loaded, options, provenance)
+
+	// Overlay with environment variables
+	if !options.IgnoreEnvironment {
+		err = applyEnvironmentVariables(&loaded, prefix, provenance)
+		if err != nil {
+			errs = append(errs, err)
 		}
-		panic(err)
+	}
+
+	// Overlay with command line arguments
+	err = applyCommandLineArguments(&loaded, options.Args, provenance)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid command line arguments: %w", err))
+	}
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
 	}
 
 	// Optionally retrieve secrets from Azure Key Vault with Managed Identity (only works inside Azure Services)
-	if GlobalConfig.EXTRACT_CONFIG_FROM_AZURE_KEY_VAULT {
-		// Validate the required properties for Azure Key Vault are set
-		err = ValidateConfig(*GlobalConfig, []string{"AZURE_KEY_VAULT_NAME", "AZURE_MANAGED_IDENTITY_ID"})
-		if err != nil {
-			pan := writeStringToFile("error in validating the mandatory configuration values for extracting configuration from Azure Key Vault:")
-			if pan != nil {
-				panic(pan)
+	if loaded.EXTRACT_CONFIG_FROM_AZURE_KEY_VAULT && !options.IgnoreAzureKeyVault {
+		if options.KeyVaultClient == nil {
+			err = validateRequiredProperties(loaded, []string{"AZURE_KEY_VAULT_NAME", "AZURE_MANAGED_IDENTITY_ID"})
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid configuration for extracting configuration from Azure Key Vault: %w", err)
 			}
-			pan = writeInterfaceToFile(err)
-			if pan != nil {
-				panic(pan)
-			}
-			panic(err)
 		}
 
-		// Initialize the config from Azure Key Vault
-		err = InitGlobalConfigFromAzureKeyVault()
+		err = loadFromAzureKeyVault(&loaded, provenance, options.KeyVaultClient)
 		if err != nil {
-			pan := writeStringToFile("error in retrieving configuration values from Azure Key Vault:")
-			if pan != nil {
-				panic(pan)
-			}
-			pan = writeInterfaceToFile(err)
-			if pan != nil {
-				panic(pan)
-			}
-			panic(err)
+			return nil, nil, fmt.Errorf("error in retrieving configuration values from Azure Key Vault: %w", err)
 		}
 	}
 
-	// Validate mandatory config properties
-	err = ValidateConfig(*GlobalConfig, requiredProperties)
-	if err != nil {
-		pan := writeStringToFile("error in validating configuration variables:")
-		if pan != nil {
-			panic(pan)
-		}
-		pan = writeInterfaceToFile(err)
-		if pan != nil {
-			panic(pan)
+	// Retrieve secrets from the configured secret providers
+	providers := append(secretProvidersFromConfig(loaded), options.SecretProviders...)
+	if len(providers) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), secretProviderTimeout)
+		err = applySecretProviders(ctx, &loaded, provenance, providers...)
+		cancel()
+		if err != nil {
+			return nil, nil, err
 		}
-		panic(err)
 	}
+
+	// Validate mandatory config properties and config values; rule violations are only warnings unless strict
+	explicit := explicitlySetFields(provenance)
+	if options.Service != "" {
+		err = validateServiceConfig(loaded, profile, options.RequiredProperties, explicit)
+	} else {
+		err = validateConfig(loaded, options.RequiredProperties, explicit)
+	}
+	if !options.StrictValidation {
+		err = warnRuleViolations(err)
+	}
+	err = withViolations(err, layered.violations)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	syncDeprecatedFields(&loaded)
+	return &loaded, provenance, nil
+}
+
+// defaultConfigFilePath returns the location of the config file.
+//
+// Returns:
+//   - string: The value of AALI_CONFIG_PATH, or config.yaml if it is not set.
+func defaultConfigFilePath() string {
+	// 1st option: read from environment variable
+	configFile := os.Getenv("AALI_CONFIG_PATH")
+	if configFile == "" {
+		// 2nd option: read from default location... root directory
+		configFile = "config.yaml"
+	}
+	return configFile
 }
 
 //////////////////////////////////////////
@@ -138,6 +254,8 @@ func InitConfig(requiredProperties []string, optionalDefaultValues map[string]in
 //////////////////////////////////////////
 
 // InitGlobalConfigFromFile reads the configuration file and initializes the Config object.
+// The file is merged with the files it includes and with the overlays in AALI_CONFIG_OVERLAYS,
+// or the overlay of the stage in AALI_STAGE or STAGE (e.g. config.production.yaml).
 //
 // Parameters:
 //   - fileName: The name of the configuration file.
@@ -147,8 +265,17 @@ func InitConfig(requiredProperties []string, optionalDefaultValues map[string]in
 // Returns:
 //   - err: An error if there was an issue initializing the configuration.
 func InitGlobalConfigFromFile(fileName string, requiredProperties []string, optionalDefaultValues map[string]interface{}) (err error) {
-	var config Config
-	configResult, err := readYaml(fileName, config)
+	overlays, stage := layerSelection(LoadOptions{}, EnvironmentVariablePrefix)
+	layered, err := readConfigFiles(fileName, overlays, stage, nil)
+	if err != nil {
+		return err
+	}
+	layered.logWarnings()
+	configResult, err := layered.decode(fileName)
+	if err != nil {
+		return err
+	}
+	err = withViolations(nil, layered.violations)
 	if err != nil {
 		return err
 	}
@@ -161,49 +288,11 @@ func InitGlobalConfigFromFile(fileName string, requiredProperties []string, opti
 	if err != nil {
 		return err
 	}
+	syncDeprecatedFields(GlobalConfig)
 
 	return nil
 }
 
-// readYaml reads the yaml specified in `fileName` parameter and saves it to `config_struct`
-//
-// Parameters:
-//   - fileName: The name of the configuration file.
-//   - configStruct: Struct with the parameters of the YAML to read.
-//
-// Returns:
-//   - extractedConfigStruct: The extracted configuration struct.
-//   - err: An error if there was an issue reading the YAML file.
-func readYaml(fileName string, configStruct Config) (extractedConfigStruct Config, err error) {
-	// Read the YAML file into a byte slice
-	data, err := os.ReadFile(fileName)
-	if err != nil {
-		message := "config.yaml file is missing from directory or not accessible"
-		return Config{}, errors.New(message)
-	}
-
-	// Unmarshal the YAML data into the config object
-	err = yaml.Unmarshal(data, &configStruct)
-	if err != nil {
-		// Create a new Config struct with field names and types
-		configStruct := reflect.TypeOf(configStruct)
-		var fieldList []string
-		for i := 0; i < configStruct.NumField(); i++ {
-			field := configStruct.Field(i)
-			fieldList = append(fieldList, fmt.Sprintf("%q: %s", field.Name, field.Type.String()))
-		}
-
-		// Define error message
-		message := fileName + " contains incorrect content. The allowed values are as follows: {"
-		message += strings.Join(fieldList, ",")
-		message = message + "}"
-
-		// Write message and error to error file
-		return Config{}, errors.New(message)
-	}
-	return configStruct, nil
-}
-
 // defineOptionalProperties sets optional properties for the configuration.
 //
 // Parameters:
@@ -224,6 +313,8 @@ func defineOptionalProperties(config *Config, optionalDefaultValues map[string]i
 
 	// Iterate over the optional default values
 	for key, defaultValue := range optionalDefaultValues {
+		key = canonicalProperty(key, "optional default values")
+
 		// Use reflection to check if the field exists and is set to its zero value
 		fieldValue := reflect.ValueOf(config).Elem().FieldByName(key)
 		if fieldValue.IsValid() && isZeroValue(fieldValue) {
@@ -253,10 +344,16 @@ func isZeroValue(v reflect.Value) bool {
 }
 
 /////////////////////////////////////////
-// Create or update Config file from CLI
+// Command line arguments
 /////////////////////////////////////////
 
-// CreateUpdateConfigFileFromCLI reads and updates the configuration file based on command-line arguments.
+// persistConfigFlag is the command line flag requesting that the config flags are written to the config file.
+const persistConfigFlag = "persist-config"
+
+// CreateUpdateConfigFileFromCLI writes the config values given as command-line arguments of the process to the config file.
+//
+// Deprecated: Load and InitConfig apply command line arguments in memory. Use PersistCommandLineArguments
+// or the -persist-config flag to write them to the config file explicitly.
 //
 // Parameters:
 //   - fileName: The name of the configuration file.
@@ -267,265 +364,372 @@ func CreateUpdateConfigFileFromCLI(fileName string) (err error) {
 	// Checking for any command-line arguments
 	if len(os.Args) == 1 {
 		log.Println("No command line options given; full config will be retrieved from existing config.yaml file and/or Azure Key Vault.")
-		return
+		return nil
 	}
+	return PersistCommandLineArguments(fileName, os.Args[1:])
+}
 
-	// Create a new config to store command-line options
-	cliConfig := Config{}
-
-	// Use reflection to create flags for each field in Config
-	createFlags(reflect.ValueOf(&cliConfig).Elem(), "")
-
-	// Parse the flags
-	flag.Parse()
-
-	// Checking if config.yaml file already exists
-	_, err = os.Stat(fileName)
-
-	if os.IsNotExist(err) {
-		// If it doesn't exist, create a new one
-		log.Println("config.yaml file does not exist. Creating a new one with provided CLI values...")
-
-		file, _ := yaml.Marshal(cliConfig)
-		_ = os.WriteFile(fileName, file, 0644)
-	} else {
-		// If it does exist, open and append to it
-		log.Println("config.yaml file exists. Appending command line options with provided CLI values...")
+// PersistCommandLineArguments writes the config values given as command-line arguments to the config file.
+// Only the keys of the given flags are added or replaced; other keys in the file are kept.
+// Map flags are merged into the map in the file. The file is created if it does not exist.
+//
+// Parameters:
+//   - fileName: The name of the configuration file.
+//   - args: The command-line arguments without the program name.
+//
+// Returns:
+//   - err: An error if the arguments are invalid or the file cannot be read or written.
+func PersistCommandLineArguments(fileName string, args []string) (err error) {
+	arguments, err := parseCommandLineArguments(args)
+	if err != nil {
+		return fmt.Errorf("invalid command line arguments: %w", err)
+	}
+	if len(arguments.fields) == 0 {
+		return nil
+	}
 
-		file, _ := os.ReadFile(fileName)
-		config := Config{}
-		err := yaml.Unmarshal(file, &config)
+	// Read the existing file, keeping the order of its keys
+	var document yaml.MapSlice
+	fileConfig := Config{}
+	data, err := os.ReadFile(fileName)
+	switch {
+	case err == nil:
+		err = yaml.Unmarshal(data, &document)
 		if err != nil {
-			message := fmt.Sprintf("Error in yaml.Unmarshal: %v", err)
-			return fmt.Errorf(message)
+			return fmt.Errorf("error in parsing %v: %w", fileName, err)
 		}
+		err = yaml.Unmarshal(data, &fileConfig)
+		if err != nil {
+			return fmt.Errorf("error in parsing %v: %w", fileName, err)
+		}
+		log.Printf("%v exists. Updating it with the provided CLI values...", fileName)
+	case os.IsNotExist(err):
+		log.Printf("%v does not exist. Creating a new one with the provided CLI values...", fileName)
+	default:
+		return fmt.Errorf("error in reading %v: %w", fileName, err)
+	}
 
-		// Use reflection to update fields that were set in the command line
-		valCli := reflect.ValueOf(&cliConfig).Elem()
-		valConfig := reflect.ValueOf(&config).Elem()
-		t := reflect.TypeOf(cliConfig)
-
-		for i := 0; i < t.NumField(); i++ {
-			cliField := valCli.Field(i)
-			configField := valConfig.Field(i)
-			if !reflect.DeepEqual(cliField.Interface(), reflect.Zero(cliField.Type()).Interface()) {
-				configField.Set(cliField)
+	// Apply the flags like the in-memory overlay and replace the top level keys they touch
+	arguments.apply(&fileConfig, nil)
+	configValue := reflect.ValueOf(fileConfig)
+	configType := configValue.Type()
+	for _, index := range arguments.fields {
+		key := yamlKey(configType.Field(index[0]))
+		value := configValue.Field(index[0]).Interface()
+		replaced := false
+		kept := document[:0]
+		for _, item := range document {
+			documentKey, _ := item.Key.(string)
+			canonical, _ := CanonicalKey(documentKey)
+			if canonical != key {
+				kept = append(kept, item)
+				continue
+			}
+			// The first of the canonical key and its deprecated aliases is replaced by the canonical key, the others are removed
+			if !replaced {
+				kept = append(kept, yaml.MapItem{Key: key, Value: value})
+				replaced = true
 			}
 		}
-
-		// Write back to the file
-		file, _ = yaml.Marshal(config)
-		_ = os.WriteFile(fileName, file, 0644)
+		document = kept
+		if !replaced {
+			document = append(document, yaml.MapItem{Key: key, Value: value})
+		}
 	}
 
+	// Write back to the file
+	data, err = yaml.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("error in marshalling config: %w", err)
+	}
+	err = os.WriteFile(fileName, data, 0644)
+	if err != nil {
+		return fmt.Errorf("error in writing %v: %w", fileName, err)
+	}
 	return nil
 }
 
-// CreateFlags initializes command-line flags for configuration.
+// applyCommandLineArguments sets the config fields for which a flag was given in args.
+// String, integer and boolean flags replace the value, slice flags replace the slice with the
+// comma separated and repeated values, and map flags merge their key=value pairs into the existing map.
 //
 // Parameters:
-//   - val: The value to create flags for.
-//   - prefix: The prefix to use for the flags.
-func createFlags(val reflect.Value, prefix string) {
-	t := val.Type()
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		name := strings.ToUpper(prefix + field.Name)
-		switch field.Type.Kind() {
-		case reflect.String:
-			flag.StringVar(val.Field(i).Addr().Interface().(*string), name, "", "config option")
-		case reflect.Int:
-			flag.IntVar(val.Field(i).Addr().Interface().(*int), name, 0, "config option")
-		case reflect.Bool:
-			flag.BoolVar(val.Field(i).Addr().Interface().(*bool), name, false, "config option")
-		case reflect.Slice:
-			if field.Type.Elem().Kind() == reflect.String {
-				flag.Var((*flagStringSlice)(val.Field(i).Addr().Interface().(*[]string)), name, "config option")
-			}
-		case reflect.Struct:
-			createFlags(val.Field(i), name+"_")
-		}
-	}
-}
-
-/////////////////////////////////////////////////
-// Extract Config variables from Azure Key Vault
-/////////////////////////////////////////////////
-
-// InitGlobalConfigFromAzureKeyVault extracts the configuration from Azure Key Vault.
-// It iterates over all secrets in the key vault and if the secret name matches a field in the Config struct,
-// it sets the field to the value of the secret.
+//   - config: The configuration object to update.
+//   - args: The command-line arguments without the program name.
+//   - provenance: Records the fields set by a flag; may be nil.
 //
 // Returns:
-//   - err: An error if there was an issue extracting the configuration.
-func InitGlobalConfigFromAzureKeyVault() (err error) {
-	// log
-	log.Println("Extracting configuration from Azure Key Vault...")
-
-	// get environment variables
-	azureManagedIdentity := os.Getenv(GlobalConfig.AZURE_MANAGED_IDENTITY_ID)
-	azureKeyVaultName := os.Getenv(GlobalConfig.AZURE_KEY_VAULT_NAME)
-
-	// check if all required environment variables are set
-	if azureManagedIdentity == "" {
-		return fmt.Errorf("environment variable for %v is not set", azureManagedIdentity)
+//   - err: An error if the arguments could not be parsed; wraps flag.ErrHelp if help was requested.
+func applyCommandLineArguments(config *Config, args []string, provenance Provenance) (err error) {
+	if len(args) == 0 {
+		return nil
 	}
-	if azureKeyVaultName == "" {
-		return fmt.Errorf("environment variable for %v is not set", azureKeyVaultName)
-	}
-
-	// create key vault URL
-	keyVaultUrl := fmt.Sprintf("https://%s.vault.azure.net/", azureKeyVaultName)
 
-	// create Managed Identity credential
-	cred, err := azidentity.NewManagedIdentityCredential(&azidentity.ManagedIdentityCredentialOptions{
-		ID: azidentity.ClientID(azureManagedIdentity),
-	})
+	arguments, err := parseCommandLineArguments(args)
 	if err != nil {
-		return fmt.Errorf("failed to get Managed Identity credential: %w", err)
+		return err
 	}
+	arguments.apply(config, provenance)
+	return nil
+}
 
-	// Test the managed id by getting a token
-	scope := "https://vault.azure.net/.default" // Scope for Azure Key Vault
-	_, err = cred.GetToken(context.TODO(), policy.TokenRequestOptions{
-		Scopes: []string{scope},
-	})
+// parseCommandLineArguments parses config flags into a separate config.
+// Flags that are not config flags, e.g. flags registered by the service on flag.CommandLine or the -test.* flags
+// of go test, are ignored.
+//
+// Parameters:
+//   - args: The command-line arguments without the program name.
+//
+// Returns:
+//   - arguments: The parsed values and the fields that were set.
+//   - err: An error if the arguments could not be parsed; flag.ErrHelp if help was requested.
+func parseCommandLineArguments(args []string) (arguments commandLineArguments, err error) {
+	flagSet, fields := newConfigFlagSet(&arguments.config, &arguments.persist)
+	flagSet.SetOutput(io.Discard)
+	err = flagSet.Parse(configFlagArguments(flagSet, args))
 	if err != nil {
-		return fmt.Errorf("failed to get token from managed ID: %w", err)
+		return commandLineArguments{}, err
 	}
 
-	// Reflect on the struct
-	GlobalConfigValue := reflect.ValueOf(GlobalConfig).Elem()
-	GlobalConfigType := GlobalConfigValue.Type()
+	// Only the fields that were explicitly set are applied
+	flagSet.Visit(func(f *flag.Flag) {
+		index, ok := fields[f.Name]
+		if ok {
+			arguments.names = append(arguments.names, f.Name)
+			arguments.fields = append(arguments.fields, index)
+		}
+		canonical, deprecated := CanonicalKey(f.Name)
+		if deprecated {
+			log.Printf("Warning: command line: %v", deprecationMessage("-"+f.Name, "-"+canonical))
+		}
+	})
+	return arguments, nil
+}
 
-	// create azsecrets client
-	clientSecrets, err := azsecrets.NewClient(keyVaultUrl, cred, nil)
-	if err != nil {
-		return err
+// apply copies the fields set by command line flags into a config.
+//
+// Parameters:
+//   - config: The configuration object to update.
+//   - provenance: Records the fields set by a flag; may be nil.
+func (arguments commandLineArguments) apply(config *Config, provenance Provenance) {
+	valCli := reflect.ValueOf(arguments.config)
+	valConfig := reflect.ValueOf(config).Elem()
+	for i, index := range arguments.fields {
+		cliField := valCli.FieldByIndex(index)
+		field := valConfig.FieldByIndex(index)
+		if field.Kind() == reflect.Map && !field.IsNil() {
+			// Copy the existing map so that maps shared with other configs are not modified
+			merged := reflect.MakeMapWithSize(field.Type(), field.Len()+cliField.Len())
+			iter := field.MapRange()
+			for iter.Next() {
+				merged.SetMapIndex(iter.Key(), iter.Value())
+			}
+			iter = cliField.MapRange()
+			for iter.Next() {
+				merged.SetMapIndex(iter.Key(), iter.Value())
+			}
+			field.Set(merged)
+		} else {
+			field.Set(cliField)
+		}
+		provenance.record(valConfig.Type().FieldByIndex(index[:1]).Name, SourceCLI, "-"+arguments.names[i])
 	}
+}
 
-	// list all secrets
-	pagerSecerts := clientSecrets.NewListSecretPropertiesPager(nil)
-	// iterate over all secrets
-	for pagerSecerts.More() {
-		page, err := pagerSecerts.NextPage(context.TODO())
-		if err != nil {
-			return err
+// configFlagArguments returns the arguments of the flags defined in the flag set and the help flags.
+// Other flags are skipped with their value; their value is the next argument if it is not a flag,
+// unless the flag is a boolean flag registered on flag.CommandLine. Positional arguments are skipped as well.
+//
+// Parameters:
+//   - flagSet: The flag set with the config flags.
+//   - args: The command-line arguments without the program name.
+//
+// Returns:
+//   - []string: The arguments to parse with the flag set.
+func configFlagArguments(flagSet *flag.FlagSet, args []string) []string {
+	filtered := []string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			continue
 		}
-		for _, secret := range page.Value {
-			// iterate over all fields in the struct
-			for i := 0; i < GlobalConfigValue.NumField(); i++ {
-				// Get the field
-				field := GlobalConfigValue.Field(i)
-
-				// Get the YAML tag
-				fieldType := GlobalConfigType.Field(i)
-				yamlTag := fieldType.Tag.Get("json")
-
-				// Check if the field name matches the target field name
-				if yamlTag == secret.ID.Name() {
-					// Get the key value
-					resp, err := clientSecrets.GetSecret(context.TODO(), secret.ID.Name(), "", nil)
-					if err != nil {
-						return err
-					}
-					// Check if the field is settable
-					if field.CanSet() {
-						// Set the field to the new value
-						switch field.Kind() {
-						case reflect.String:
-							value := *resp.Value
-							unquoted, err := strconv.Unquote(`"` + strings.ReplaceAll(value, `"`, `\"`) + `"`)
-							if err == nil {
-								value = unquoted
-							}
-							field.SetString(value)
-						case reflect.Bool:
-							// Convert string to bool
-							value, err := strconv.ParseBool(*resp.Value)
-							if err != nil {
-								return err
-							}
-							field.SetBool(value)
-						case reflect.Int:
-							// Convert string to int
-							value, err := strconv.Atoi(*resp.Value)
-							if err != nil {
-								return err
-							}
-							field.SetInt(int64(value))
-						case reflect.Slice:
-							// Convert string to string slice
-							var value []string
-							err := json.Unmarshal([]byte(*resp.Value), &value)
-							if err != nil {
-								return err
-							}
-							field.Set(reflect.ValueOf(value))
-						case reflect.Map:
-							// Convert string to map[string]string
-							var value map[string]string
-							err := json.Unmarshal([]byte(*resp.Value), &value)
-							if err != nil {
-								return err
-							}
-							field.Set(reflect.ValueOf(value))
-						default:
-							return fmt.Errorf("unsupported field type: %v", field.Kind())
-						}
-					}
-				}
+		name, _, hasValue := strings.Cut(strings.TrimPrefix(arg[1:], "-"), "=")
+		switch {
+		case name == "h", name == "help":
+			filtered = append(filtered, arg)
+		case flagSet.Lookup(name) != nil:
+			filtered = append(filtered, arg)
+			if !isBoolFlag(flagSet.Lookup(name)) && !hasValue && i+1 < len(args) {
+				i++
+				filtered = append(filtered, args[i])
 			}
+		case !hasValue && !isBoolFlag(flag.CommandLine.Lookup(name)) && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-"):
+			i++
 		}
 	}
+	return filtered
+}
 
-	return nil
+// isBoolFlag reports whether a flag does not need a value.
+//
+// Parameters:
+//   - f: The flag; may be nil.
+//
+// Returns:
+//   - bool: True if the flag is a boolean flag.
+func isBoolFlag(f *flag.Flag) bool {
+	if f == nil {
+		return false
+	}
+	boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && boolFlag.IsBoolFlag()
 }
 
-///////////////////////
-// Helper Functions
-///////////////////////
+// persistRequested reports whether the -persist-config flag is given in args.
+//
+// Parameters:
+//   - args: The command-line arguments without the program name.
+//
+// Returns:
+//   - bool: True if the config flags should be written to the config file.
+func persistRequested(args []string) bool {
+	arguments, err := parseCommandLineArguments(args)
+	return err == nil && arguments.persist
+}
 
-// ValidateConfig checks for mandatory entries in the configuration and validates chosen models.
+// newConfigFlagSet creates a flag set with one flag per config field and the -persist-config flag.
 //
 // Parameters:
-//   - config: The configuration object to validate.
-//   - requiredProperties: The list of required properties.
+//   - cliConfig: The config receiving the flag values.
+//   - persist: Receives the value of the -persist-config flag.
 //
 // Returns:
-//   - err: An error if there was an issue validating the configuration.
-func ValidateConfig(config Config, requiredProperties []string) (err error) {
-	// Check if all mandatory properties are present
-	configValue := reflect.ValueOf(config)
+//   - *flag.FlagSet: The flag set.
+//   - map[string][]int: The index path of the config field of each flag.
+func newConfigFlagSet(cliConfig *Config, persist *bool) (*flag.FlagSet, map[string][]int) {
+	flagSet := flag.NewFlagSet("config", flag.ContinueOnError)
+	fields := map[string][]int{}
+	createFlags(flagSet, reflect.ValueOf(cliConfig).Elem(), "", nil, fields)
+	flagSet.BoolVar(persist, persistConfigFlag, false, "Write the given config flags to the config file.")
+	return flagSet, fields
+}
 
-	for _, property := range requiredProperties {
-		field := configValue.FieldByName(property)
+// createFlags initializes command-line flags for configuration.
+// The usage of each flag is the description of its field.
+//
+// Parameters:
+//   - flagSet: The flag set to register the flags on.
+//   - val: The value to create flags for.
+//   - prefix: The prefix to use for the flags.
+//   - index: The index path of val in the config.
+//   - fields: Receives the index path of the field of each flag.
+func createFlags(flagSet *flag.FlagSet, val reflect.Value, prefix string, index []int, fields map[string][]int) {
+	t := val.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if isDeprecatedField(field) {
+			continue
+		}
+		name := strings.ToUpper(prefix + field.Name)
+		fieldIndex := append(append([]int{}, index...), i)
+		usage := FieldDescription(field.Name)
+		if usage == "" {
+			usage = "config option"
+		}
+		switch field.Type.Kind() {
+		case reflect.String:
+			flagSet.StringVar(val.Field(i).Addr().Interface().(*string), name, "", usage)
+		case reflect.Int:
+			flagSet.IntVar(val.Field(i).Addr().Interface().(*int), name, 0, usage)
+		case reflect.Bool:
+			flagSet.BoolVar(val.Field(i).Addr().Interface().(*bool), name, false, usage)
+		case reflect.Slice:
+			if field.Type.Elem().Kind() != reflect.String {
+				continue
+			}
+			flagSet.Var(&flagStringSlice{values: val.Field(i).Addr().Interface().(*[]string)}, name, usage+" Comma separated `list`; the flag can be repeated.")
+		case reflect.Map:
+			if field.Type != reflect.TypeOf(map[string]string{}) {
+				continue
+			}
+			flagSet.Var((*flagStringMap)(val.Field(i).Addr().Interface().(*map[string]string)), name, usage+" Comma separated `key=value` pairs; the flag can be repeated.")
+		case reflect.Struct:
+			createFlags(flagSet, val.Field(i), name+"_", fieldIndex, fields)
+			continue
+		default:
+			continue
+		}
+		fields[name] = fieldIndex
 
-		if !field.IsValid() || field.IsZero() {
-			message := fmt.Sprintf("config.yaml is missing mandatory property '%v': ", property)
-			return fmt.Errorf(message)
+		// Deprecated aliases set the same value
+		for _, alias := range fieldAliases(field) {
+			aliasName := strings.ToUpper(prefix + alias)
+			flagSet.Var(flagSet.Lookup(name).Value, aliasName, fmt.Sprintf("Deprecated: use -%v.", name))
+			fields[aliasName] = fieldIndex
 		}
 	}
+}
 
-	return nil
+// CommandLineUsage writes the help for the config command line flags, listing every flag with its description.
+//
+// Parameters:
+//   - w: The writer to write the help to.
+func CommandLineUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %v [flags]\n\n", filepath.Base(os.Args[0]))
+	fmt.Fprintf(w, "Config values that are not given as flags are read from the config file (AALI_CONFIG_PATH or config.yaml)\n")
+	fmt.Fprintf(w, "and %v<KEY> environment variables. Flags are not written to the config file unless -%v is given.\n\n", EnvironmentVariablePrefix, persistConfigFlag)
+	fmt.Fprintf(w, "Flags:\n")
+
+	var persist bool
+	flagSet, _ := newConfigFlagSet(&Config{}, &persist)
+	flagSet.SetOutput(w)
+	flagSet.PrintDefaults()
 }
 
+///////////////////////
+// Helper Functions
+///////////////////////
+
 // GetGlobalConfigAsJSON returns the global configuration as a JSON string.
+// Secret fields are redacted, so the result is safe to log or expose.
 //
 // Returns:
-//   - string: The global configuration as a JSON string.
+//   - string: The redacted global configuration as a JSON string.
 func GetGlobalConfigAsJSON() string {
-	jsonData, err := json.Marshal(GlobalConfig)
+	if GlobalConfig == nil {
+		return "null"
+	}
+	jsonData, err := GlobalConfig.RedactedJSON()
 	if err != nil {
 		return ""
 	}
-	return string(jsonData)
+	return jsonData
 }
 
 ///////////////////////
 // Error file creator
 ///////////////////////
 
+// writeErrorAndPanic writes a message and an error to the error file and panics with the error.
+//
+// Parameters:
+//   - message: The message describing the failed step.
+//   - err: The error to report.
+func writeErrorAndPanic(message string, err error) {
+	pan := writeStringToFile(message)
+	if pan != nil {
+		panic(pan)
+	}
+	pan = writeInterfaceToFile(err.Error())
+	if pan != nil {
+		panic(pan)
+	}
+	panic(err)
+}
+
 // writeInterfaceToFile writes interface data to an error log file.
 //
 // Parameters:
@@ -23,7 +23,13 @@
 package config
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -126,3 +132,564 @@ func TestDefineOptionalProperties(t *testing.T) {
 		})
 	}
 }
+
+// TestApplyEnvironmentVariables tests the ApplyEnvironmentVariables function
+func TestApplyEnvironmentVariables(t *testing.T) {
+	t.Setenv("AALI_AGENT_PORT", "9091")
+	t.Setenv("AALI_LOCAL_LOGS", "true")
+	t.Setenv("AALI_NUMBER_OF_WORKFLOW_WORKERS", "4")
+	t.Setenv("AALI_PRIVATE_WORKFLOWS_FOLDERS", "folder1, folder2")
+	t.Setenv("AALI_WORKFLOW_CONFIG_VARIABLES", "key1=value1,key2=value2")
+
+	config := Config{
+		AGENT_PORT:   "9090",
+		SERVICE_NAME: "AaliService",
+	}
+	err := ApplyEnvironmentVariables(&config, EnvironmentVariablePrefix)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedConfig := Config{
+		AGENT_PORT:                 "9091",
+		SERVICE_NAME:               "AaliService",
+		LOCAL_LOGS:                 true,
+		NUMBER_OF_WORKFLOW_WORKERS: 4,
+		PRIVATE_WORKFLOWS_FOLDERS:  []string{"folder1", "folder2"},
+		WORKFLOW_CONFIG_VARIABLES:  map[string]string{"key1": "value1", "key2": "value2"},
+	}
+	if !reflect.DeepEqual(config, expectedConfig) {
+		t.Errorf("Expected config %+v, got %+v", expectedConfig, config)
+	}
+
+	// JSON values and invalid values
+	t.Setenv("AALI_WORKFLOW_CONFIG_VARIABLES", `{"key": "a,b=c"}`)
+	t.Setenv("AALI_NUMBER_OF_WORKFLOW_WORKERS", "four")
+	err = ApplyEnvironmentVariables(&config, EnvironmentVariablePrefix)
+	if err == nil {
+		t.Fatalf("expected error for invalid integer")
+	}
+	if !reflect.DeepEqual(config.WORKFLOW_CONFIG_VARIABLES, map[string]string{"key": "a,b=c"}) {
+		t.Errorf("Expected JSON map to be parsed, got %v", config.WORKFLOW_CONFIG_VARIABLES)
+	}
+}
+
+// TestApplyCommandLineArguments tests that CLI flags only override the fields that were set
+func TestApplyCommandLineArguments(t *testing.T) {
+	variables := map[string]string{"keep": "file", "replace": "file"}
+	config := Config{AGENT_PORT: "9091", LOCAL_LOGS: true, WORKFLOW_CONFIG_VARIABLES: variables, PRIVATE_WORKFLOWS_FOLDERS: []string{"file"}}
+	err := applyCommandLineArguments(&config, []string{
+		"-SERVICE_NAME", "cli", "-LOCAL_LOGS=false",
+		"-WORKFLOW_CONFIG_VARIABLES", "replace=cli,added=cli", "-WORKFLOW_CONFIG_VARIABLES", "url=http://host/?a=b",
+		"-PRIVATE_WORKFLOWS_FOLDERS", "a,b", "-PRIVATE_WORKFLOWS_FOLDERS", "c",
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedConfig := Config{
+		AGENT_PORT:                "9091",
+		SERVICE_NAME:              "cli",
+		LOCAL_LOGS:                false,
+		WORKFLOW_CONFIG_VARIABLES: map[string]string{"keep": "file", "replace": "cli", "added": "cli", "url": "http://host/?a=b"},
+		PRIVATE_WORKFLOWS_FOLDERS: []string{"a", "b", "c"},
+	}
+	if !reflect.DeepEqual(config, expectedConfig) {
+		t.Errorf("Expected config %+v, got %+v", expectedConfig, config)
+	}
+	if len(variables) != 2 {
+		t.Errorf("Expected the original map to be unchanged, got %v", variables)
+	}
+
+	err = applyCommandLineArguments(&config, []string{"-WORKFLOW_CONFIG_VARIABLES", "novalue"}, nil)
+	if err == nil {
+		t.Errorf("expected error for map flag without '='")
+	}
+	err = applyCommandLineArguments(&config, []string{"-help"}, nil)
+	if !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("expected flag.ErrHelp, got %v", err)
+	}
+}
+
+// TestInitConfigUnknownFlags tests that flags of the service and of go test do not make InitConfig fail
+func TestInitConfigUnknownFlags(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(configFile, []byte("SERVICE_NAME: file\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AALI_CONFIG_PATH", configFile)
+	args := os.Args
+	os.Args = []string{"service", "-test.v", "-verbose", "-port", "8080", "-LOG_LEVEL", "debug", "positional", "--test.timeout=1m"}
+	t.Cleanup(func() {
+		os.Args = args
+		activeConfig.Store(nil)
+		activeLoadOptions.Store(nil)
+		GlobalConfig = nil
+	})
+
+	InitConfig(nil, nil)
+	if GlobalConfig.SERVICE_NAME != "file" || GlobalConfig.LOG_LEVEL != "debug" {
+		t.Errorf("Expected SERVICE_NAME 'file' and LOG_LEVEL 'debug', got %q and %q", GlobalConfig.SERVICE_NAME, GlobalConfig.LOG_LEVEL)
+	}
+}
+
+// TestPersistCommandLineArguments tests that CLI flags are only written to the config file on request
+func TestPersistCommandLineArguments(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(fileName, []byte("SERVICE_NAME: file\nWORKFLOW_CONFIG_VARIABLES:\n  keep: file\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args := []string{"-persist-config", "-AGENT_PORT", "9092", "-WORKFLOW_CONFIG_VARIABLES", "added=cli"}
+	if !persistRequested(args) || persistRequested(args[1:]) {
+		t.Errorf("unexpected result of persistRequested")
+	}
+
+	// Loading does not modify the file
+	config, err := Load(LoadOptions{FilePath: fileName, Args: args, IgnoreEnvironment: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.AGENT_PORT != "9092" {
+		t.Errorf("expected CLI overlay, got %+v", config)
+	}
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "AGENT_PORT") {
+		t.Errorf("config file was modified by Load:\n%s", data)
+	}
+
+	err = PersistCommandLineArguments(fileName, args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	layered, err := readConfigFiles(fileName, nil, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	persisted, err := layered.decode(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Config{
+		SERVICE_NAME:              "file",
+		AGENT_PORT:                "9092",
+		WORKFLOW_CONFIG_VARIABLES: map[string]string{"keep": "file", "added": "cli"},
+	}
+	if !reflect.DeepEqual(persisted, expected) {
+		t.Errorf("Expected persisted config %+v, got %+v", expected, persisted)
+	}
+
+	// Errors are returned instead of being swallowed
+	err = PersistCommandLineArguments(filepath.Join(fileName, "invalid.yaml"), args)
+	if err == nil {
+		t.Errorf("expected error for invalid file path")
+	}
+}
+
+// TestPersistCommandLineArgumentsAlias tests that persisting a flag removes the deprecated aliases of its key
+func TestPersistCommandLineArgumentsAlias(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(fileName, []byte("ANSYS_DISOC_SIGN_PUBLIC_KEY: alias\nSERVICE_NAME: file\nANSYS_DISCO_SIGN_PUBLIC_KEY: canonical\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = PersistCommandLineArguments(fileName, []string{"-ANSYS_DISCO_SIGN_PUBLIC_KEY", "cli"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "ANSYS_DISCO_SIGN_PUBLIC_KEY: cli\nSERVICE_NAME: file\n"
+	if string(data) != expected {
+		t.Errorf("Expected config file %q, got %q", expected, data)
+	}
+}
+
+// TestFlagStringSlice tests that the first use of a slice flag replaces the current value and repeated uses append
+func TestFlagStringSlice(t *testing.T) {
+	values := []string{"default"}
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagSet.Var(&flagStringSlice{values: &values}, "LIST", "")
+	err := flagSet.Parse([]string{"-LIST", "a,b", "-LIST", "c"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(values, []string{"a", "b", "c"}) {
+		t.Errorf("Expected [a b c], got %v", values)
+	}
+}
+
+// TestCommandLineUsage tests that the help lists each flag with its description
+func TestCommandLineUsage(t *testing.T) {
+	var buffer strings.Builder
+	CommandLineUsage(&buffer)
+	usage := buffer.String()
+	for _, expected := range []string{
+		"-LOG_LEVEL string",
+		FieldDescription("LOG_LEVEL"),
+		"-WORKFLOW_CONFIG_VARIABLES key=value",
+		"-PRIVATE_WORKFLOWS_FOLDERS list",
+		"-persist-config",
+	} {
+		if !strings.Contains(usage, expected) {
+			t.Errorf("usage does not contain %q:\n%v", expected, usage)
+		}
+	}
+}
+
+// TestLoad tests that Load builds independent configs and aggregates errors
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	firstFile := filepath.Join(dir, "first.yaml")
+	secondFile := filepath.Join(dir, "second.yaml")
+	err := os.WriteFile(firstFile, []byte("SERVICE_NAME: first\nAGENT_PORT: \"9090\"\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(secondFile, []byte("SERVICE_NAME: second\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := Load(LoadOptions{
+		FilePath:              firstFile,
+		Args:                  []string{"-LOG_LEVEL", "debug"},
+		RequiredProperties:    []string{"SERVICE_NAME", "AGENT_PORT"},
+		OptionalDefaultValues: map[string]interface{}{"LOG_LEVEL": "info", "NUMBER_OF_WORKFLOW_WORKERS": 5},
+		IgnoreEnvironment:     true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.SERVICE_NAME != "first" || first.LOG_LEVEL != "debug" || first.NUMBER_OF_WORKFLOW_WORKERS != 5 {
+		t.Errorf("unexpected config: %+v", first)
+	}
+
+	second, err := Load(LoadOptions{FilePath: secondFile, IgnoreEnvironment: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second.SERVICE_NAME != "second" || first.SERVICE_NAME != "first" {
+		t.Errorf("configs are not independent: %+v, %+v", first, second)
+	}
+
+	// All missing properties are reported
+	_, err = Load(LoadOptions{
+		FilePath:           secondFile,
+		RequiredProperties: []string{"AGENT_PORT", "WEBSERVER_PORT"},
+		IgnoreEnvironment:  true,
+	})
+	if err == nil || !strings.Contains(err.Error(), "AGENT_PORT") || !strings.Contains(err.Error(), "WEBSERVER_PORT") {
+		t.Errorf("expected error for both missing properties, got %v", err)
+	}
+
+	// Missing file
+	_, err = Load(LoadOptions{FilePath: filepath.Join(dir, "missing.yaml")})
+	if err == nil {
+		t.Errorf("expected error for missing file")
+	}
+}
+
+func TestLoadWithProvenance(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(fileName, []byte("SERVICE_NAME: file\nAGENT_PORT: \"9090\"\nLOG_LEVEL: warn\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secretsDir := t.TempDir()
+	err = os.WriteFile(filepath.Join(secretsDir, "llm-api-key"), []byte("very-secret\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TESTPROV_AGENT_PORT", "9191")
+
+	config, provenance, err := LoadWithProvenance(LoadOptions{
+		FilePath:              fileName,
+		Args:                  []string{"-LOG_LEVEL", "debug"},
+		OptionalDefaultValues: map[string]interface{}{"NUMBER_OF_WORKFLOW_WORKERS": 5, "SERVICE_NAME": "default"},
+		EnvironmentPrefix:     "TESTPROV_",
+		SecretProviders:       []SecretProvider{NewDirectorySecretProvider(secretsDir)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]Origin{
+		"SERVICE_NAME":               {Source: SourceFile, Location: fileName},
+		"AGENT_PORT":                 {Source: SourceEnvironment, Location: "TESTPROV_AGENT_PORT"},
+		"LOG_LEVEL":                  {Source: SourceCLI, Location: "-LOG_LEVEL"},
+		"NUMBER_OF_WORKFLOW_WORKERS": {Source: SourceDefault},
+		"LLM_API_KEY":                {Source: SourceSecretProvider, Location: "secret directory " + secretsDir},
+		"WEBSERVER_PORT":             {Source: SourceUnset},
+	}
+	for field, origin := range expected {
+		if provenance.Origin(field) != origin {
+			t.Errorf("%v: expected origin %v, got %v", field, origin, provenance.Origin(field))
+		}
+	}
+
+	report := provenance.Report(*config)
+	if strings.Contains(report, "very-secret") {
+		t.Errorf("report contains secret value:\n%v", report)
+	}
+	for _, line := range []string{"LLM_API_KEY", "\"[REDACTED]\"", "environment (TESTPROV_AGENT_PORT)", "cli (-LOG_LEVEL)"} {
+		if !strings.Contains(report, line) {
+			t.Errorf("report does not contain %q:\n%v", line, report)
+		}
+	}
+}
+
+// TestRedacted tests that secret fields are redacted in all renderings
+func TestRedacted(t *testing.T) {
+	config := Config{SERVICE_NAME: "aali", LLM_API_KEY: "llm-secret", GITHUB_TOKEN: "gh-secret"}
+
+	redacted := config.Redacted()
+	expectedConfig := Config{SERVICE_NAME: "aali", LLM_API_KEY: RedactedValue, GITHUB_TOKEN: RedactedValue}
+	if !reflect.DeepEqual(redacted, expectedConfig) {
+		t.Errorf("Expected config %+v, got %+v", expectedConfig, redacted)
+	}
+	if config.LLM_API_KEY != "llm-secret" {
+		t.Errorf("Redacted must not modify the original config")
+	}
+
+	jsonData, err := config.RedactedJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	yamlData, err := config.RedactedYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rendering := range []string{jsonData, yamlData, config.String(), fmt.Sprintf("%v", config)} {
+		if strings.Contains(rendering, "llm-secret") || strings.Contains(rendering, "gh-secret") {
+			t.Errorf("Rendering leaks secret: %v", rendering)
+		}
+		if !strings.Contains(rendering, "aali") {
+			t.Errorf("Rendering misses non-secret value: %v", rendering)
+		}
+	}
+
+	change := ConfigChange{Field: "LLM_API_KEY", OldValue: "old-secret", NewValue: "new-secret"}
+	if strings.Contains(change.String(), "secret") {
+		t.Errorf("Change leaks secret: %v", change)
+	}
+}
+
+// TestValidateConfig tests that ValidateConfig reports all rule violations at once
+func TestValidateConfig(t *testing.T) {
+	certFile := filepath.Join(t.TempDir(), "cert.pem")
+	err := os.WriteFile(certFile, []byte("cert"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name               string
+		config             Config
+		requiredProperties []string
+		expectedRules      map[string]string
+	}{
+		{
+			name: "Valid config",
+			config: Config{
+				LOG_LEVEL:                  "DEBUG",
+				AGENT_PORT:                 "9090",
+				EXTERNALFUNCTIONS_ENDPOINT: "localhost:50051",
+				LLM_HANDLER_ENDPOINT:       "ws://localhost:9003",
+				NUMBER_OF_WORKFLOW_WORKERS: 4,
+				USE_SSL:                    true,
+				SSL_CERT_PUBLIC_KEY_FILE:   certFile,
+				SSL_CERT_PRIVATE_KEY_FILE:  certFile,
+			},
+			requiredProperties: []string{"AGENT_PORT"},
+			expectedRules:      map[string]string{},
+		},
+		{
+			name: "All violations reported",
+			config: Config{
+				LOG_LEVEL:                  "verbose",
+				AGENT_PORT:                 "99999",
+				EXTERNALFUNCTIONS_ENDPOINT: "localhost",
+				LOGGING_URL:                "datadog",
+				NUMBER_OF_WORKFLOW_WORKERS: -1,
+				USE_SSL:                    true,
+				SSL_CERT_PUBLIC_KEY_FILE:   filepath.Join(t.TempDir(), "missing.pem"),
+				DATADOG_LOGS:               true,
+			},
+			requiredProperties: []string{"SERVICE_NAME"},
+			expectedRules: map[string]string{
+				"SERVICE_NAME":               "required",
+				"LOG_LEVEL":                  "oneof",
+				"AGENT_PORT":                 "port",
+				"EXTERNALFUNCTIONS_ENDPOINT": "endpoint",
+				"LOGGING_URL":                "url",
+				"NUMBER_OF_WORKFLOW_WORKERS": "min",
+				"SSL_CERT_PRIVATE_KEY_FILE":  "required_when",
+				"SSL_CERT_PUBLIC_KEY_FILE":   "file_exists",
+				"LOGGING_API_KEY":            "required_when",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateConfig(tt.config, tt.requiredProperties)
+
+			rules := map[string]string{}
+			if err != nil {
+				validationError, ok := err.(*ValidationError)
+				if !ok {
+					t.Fatalf("Expected *ValidationError, got %T", err)
+				}
+				for _, violation := range validationError.Violations {
+					rules[violation.Field] = violation.Rule
+				}
+			}
+			if !reflect.DeepEqual(rules, tt.expectedRules) {
+				t.Errorf("Expected violations %v, got %v (%v)", tt.expectedRules, rules, err)
+			}
+		})
+	}
+}
+
+// TestLoadStrictValidation tests that rule violations are only warnings unless StrictValidation is set
+func TestLoadStrictValidation(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(configFile, []byte("LOG_LEVEL: VERBOSE\nLOGGING_URL: datadog:443\nNUMBER_OF_WORKFLOW_WORKERS: 0\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := LoadOptions{FilePath: configFile, IgnoreEnvironment: true}
+
+	// Legacy behaviour: the config is loaded and the violations are logged
+	config, err := Load(options)
+	if err != nil {
+		t.Fatalf("Expected rule violations to be warnings, got %v", err)
+	}
+	if config.LOG_LEVEL != "VERBOSE" {
+		t.Errorf("Expected LOG_LEVEL 'VERBOSE', got %q", config.LOG_LEVEL)
+	}
+
+	// Missing required properties are still errors
+	options.RequiredProperties = []string{"SERVICE_NAME"}
+	_, err = Load(options)
+	validationError, ok := err.(*ValidationError)
+	if !ok || len(validationError.Violations) != 1 || validationError.Violations[0].Rule != "required" {
+		t.Errorf("Expected only the missing SERVICE_NAME, got %v", err)
+	}
+
+	// Strict validation also rejects the explicit 0 workers
+	options.RequiredProperties = nil
+	options.StrictValidation = true
+	_, err = Load(options)
+	validationError, ok = err.(*ValidationError)
+	if !ok {
+		t.Fatalf("Expected *ValidationError, got %v", err)
+	}
+	rules := map[string]string{}
+	for _, violation := range validationError.Violations {
+		rules[violation.Field] = violation.Rule
+	}
+	expectedRules := map[string]string{"LOG_LEVEL": "oneof", "LOGGING_URL": "url", "NUMBER_OF_WORKFLOW_WORKERS": "min"}
+	if !reflect.DeepEqual(rules, expectedRules) {
+		t.Errorf("Expected violations %v, got %v", expectedRules, rules)
+	}
+}
+
+// TestServiceProfiles tests service specific loading, validation and scoped access
+func TestServiceProfiles(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(fileName, []byte("AGENT_PORT: \"not-a-port\"\nWATCH_FOLDER_PATH: /watch\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Exec node reports only exec specific violations
+	_, err = Load(LoadOptions{FilePath: fileName, Service: ServiceExec, IgnoreEnvironment: true})
+	validationError, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("Expected *ValidationError, got %v", err)
+	}
+	expectedMessage := "invalid configuration for the exec service:\n" +
+		"exec service requires config property 'EXEC_ID'\n" +
+		"exec service requires config property 'AGENT_ENDPOINT'"
+	if validationError.Error() != expectedMessage {
+		t.Errorf("Expected error %q, got %q", expectedMessage, validationError.Error())
+	}
+
+	// Defaults of the profile are applied
+	err = os.WriteFile(fileName, []byte("EXEC_ID: exec-1\nAGENT_ENDPOINT: ws://agent:9090\nAGENT_PORT: \"9090\"\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := Load(LoadOptions{FilePath: fileName, Service: ServiceExec, IgnoreEnvironment: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.PYTHON_EXECUTABLE != "python3" || config.SERVICE_NAME != "aali-exec" || config.LOG_LEVEL != "info" {
+		t.Errorf("Expected exec defaults to be applied, got %+v", config)
+	}
+
+	// Scoped access
+	scoped, err := config.Scope(ServiceExec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	execId, err := scoped.GetString("EXEC_ID")
+	if err != nil || execId != "exec-1" {
+		t.Errorf("Expected EXEC_ID 'exec-1', got %q (%v)", execId, err)
+	}
+	_, err = scoped.Get("AGENT_PORT")
+	if err == nil {
+		t.Errorf("Expected AGENT_PORT to be out of the exec scope")
+	}
+	if scoped.Config().AGENT_PORT != "" || scoped.Config().EXEC_ID != "exec-1" {
+		t.Errorf("Expected scoped config to only contain exec fields")
+	}
+
+	_, err = GetServiceProfile("unknown")
+	if err == nil {
+		t.Errorf("Expected error for unknown service profile")
+	}
+}
+
+// TestRedactValue tests that secret values of every kind are redacted without modifying the original
+func TestRedactValue(t *testing.T) {
+	type credentials struct {
+		User     string
+		Password string
+		Port     int
+	}
+	headers := map[string]string{"Authorization": "Bearer token", "X-Empty": ""}
+	tokens := []string{"token-a", "token-b"}
+	tests := []struct {
+		value    interface{}
+		expected interface{}
+	}{
+		{"secret", RedactedValue},
+		{"", ""},
+		{tokens, []string{RedactedValue, RedactedValue}},
+		{[]string(nil), []string(nil)},
+		{headers, map[string]string{"Authorization": RedactedValue, "X-Empty": ""}},
+		{map[string][]string{"keys": {"a"}}, map[string][]string{"keys": {RedactedValue}}},
+		{credentials{"user", "password", 22}, credentials{RedactedValue, RedactedValue, 0}},
+		{42, 0},
+	}
+	for _, tt := range tests {
+		redacted := redactValue(reflect.ValueOf(tt.value)).Interface()
+		if !reflect.DeepEqual(redacted, tt.expected) {
+			t.Errorf("Expected %#v to be redacted to %#v, got %#v", tt.value, tt.expected, redacted)
+		}
+	}
+	if tokens[0] != "token-a" || headers["Authorization"] != "Bearer token" {
+		t.Errorf("redactValue must not modify the original value")
+	}
+}
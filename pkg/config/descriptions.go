@@ -0,0 +1,154 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+// fieldDescriptions contains a short description of every config field.
+// They are used for the JSON schema, the config templates and the command line help.
+var fieldDescriptions = map[string]string{
+	// Logging
+	"LOG_LEVEL":                      "Minimum level of the log entries that are written (debug, info, warn, error or fatal).",
+	"LOG_LEVEL_OVERRIDES":            "Log levels by component (value of the 'component' log context) or package path, overriding LOG_LEVEL; a package path includes its subpackages.",
+	"CONSOLE_LOG_LEVEL":              "Minimum level of the log entries written to stdout, in addition to LOG_LEVEL.",
+	"LOCAL_LOGS":                     "If true, log entries are written to LOCAL_LOGS_LOCATION.",
+	"LOCAL_LOGS_LOCATION":            "Path of the local log file.",
+	"LOCAL_LOGS_LEVEL":               "Minimum level of the log entries written to LOCAL_LOGS_LOCATION, in addition to LOG_LEVEL.",
+	"LOCAL_LOGS_MAX_SIZE_MB":         "Size in MB at which the local log file is rotated (default 100).",
+	"LOCAL_LOGS_ROTATION_HOURS":      "Interval in hours at which the local log file is rotated, e.g. 24 for daily rotation at midnight UTC; 0 disables time-based rotation.",
+	"LOCAL_LOGS_MAX_AGE_DAYS":        "Days after which rotated local log files are removed; 0 keeps them.",
+	"LOCAL_LOGS_MAX_BACKUPS":         "Number of rotated local log files that are kept; 0 keeps all.",
+	"LOCAL_LOGS_COMPRESS":            "If true, rotated local log files are compressed with gzip.",
+	"DATADOG_LOGS":                   "If true, log entries are sent to Datadog at LOGGING_URL.",
+	"STAGE":                          "Deployment stage (e.g. dev, staging, production), used as Datadog env tag and to select the config overlay (e.g. config.production.yaml).",
+	"VERSION":                        "Version of the service, used as Datadog version tag.",
+	"SERVICE_NAME":                   "Name of the service as shown in the logs.",
+	"ERROR_FILE_LOCATION":            "Path of the file receiving errors of the logging and config packages.",
+	"LOGGING_URL":                    "Datadog logs intake URL.",
+	"LOGGING_API_KEY":                "Datadog API key used for logs and metrics.",
+	"DATADOG_SOURCE":                 "Datadog source attribute of the log entries.",
+	"DATADOG_LOG_LEVEL":              "Minimum level of the log entries sent to Datadog, in addition to LOG_LEVEL.",
+	"DATADOG_METRICS":                "If true, metrics are sent to Datadog at METRICS_URL.",
+	"METRICS_URL":                    "Datadog metrics intake URL.",
+	"METRICS_INTERVAL_SECONDS":       "Seconds over which counters, gauges and histograms are aggregated before they are sent to Datadog (default 10).",
+	"DATADOG_QUEUE_SIZE":             "Maximum number of log entries or metrics waiting to be sent to Datadog; further ones are dropped (default 10000).",
+	"DATADOG_BATCH_SIZE":             "Maximum number of log entries or metrics sent to Datadog in one request (default 100).",
+	"DATADOG_FLUSH_INTERVAL_SECONDS": "Seconds after which queued log entries and metrics are sent to Datadog even if the batch is not full (default 5).",
+	"DATADOG_MAX_RETRIES":            "Number of retries of Datadog requests failing with 429, 5xx or a network error (default 5).",
+	"DATADOG_SPOOL_DIRECTORY":        "Directory where log and metric batches that cannot be delivered to Datadog are stored and replayed from, also after a restart; empty disables the spool.",
+	"DATADOG_SPOOL_MAX_MB":           "Maximum size in MB of the spooled logs and of the spooled metrics; the oldest batches are removed first (default 100).",
+	"OTLP_LOGS":                      "If true, log entries are sent to an OpenTelemetry collector at OTLP_LOGS_URL.",
+	"OTLP_LOG_LEVEL":                 "Minimum level of the log entries sent to the OpenTelemetry collector, in addition to LOG_LEVEL.",
+	"OTLP_LOGS_URL":                  "OTLP/HTTP logs endpoint of the OpenTelemetry collector (e.g. http://localhost:4318/v1/logs).",
+
+	// SSL
+	"USE_SSL":                   "If true, the service serves its endpoints over TLS.",
+	"SSL_CERT_PUBLIC_KEY_FILE":  "Path of the TLS certificate file.",
+	"SSL_CERT_PRIVATE_KEY_FILE": "Path of the TLS private key file.",
+
+	// Azure Key Vault
+	"EXTRACT_CONFIG_FROM_AZURE_KEY_VAULT": "If true, config values are retrieved from Azure Key Vault using a managed identity.",
+	"AZURE_KEY_VAULT_NAME":                "Name of the environment variable containing the Azure Key Vault name.",
+	"AZURE_MANAGED_IDENTITY_ID":           "Name of the environment variable containing the client ID of the managed identity.",
+	"AZURE_KEY_VAULT_SECRET_NAMES":        "Maps Key Vault secret names to config keys; unmapped secrets match the config key with '-' instead of '_'.",
+	"AZURE_KEY_VAULT_TIMEOUT_SECONDS":     "Time limit in seconds for reading secrets from Azure Key Vault (default 30).",
+	"AZURE_KEY_VAULT_CONCURRENCY":         "Number of Key Vault secrets fetched in parallel (default 8).",
+	"AZURE_KEY_VAULT_CACHE_TTL":           "Seconds for which Key Vault secrets are reused by config reloads in the same process; 0 disables the cache.",
+
+	// Secret providers
+	"SECRETS_DIRECTORY":        "Directory with one file per secret (Kubernetes or Docker secrets); the file name is the config key.",
+	"SECRETS_DOTENV_FILE":      ".env file containing secret config values.",
+	"SECRETS_HTTP_VAULT_URL":   "URL of an HTTP vault API returning secret config values as JSON object.",
+	"SECRETS_HTTP_VAULT_TOKEN": "Bearer token for SECRETS_HTTP_VAULT_URL.",
+
+	// Feature flags
+	"FEATURE_FLAGS": "Feature flags by name, each with enabled, users, workflows and percentage (see FeatureEnabled).",
+
+	// Aali Agent
+	"PRODUCTION_MODE":                      "If true, the agent returns generic error messages.",
+	"AGENT_PORT":                           "Port of the agent server.",
+	"WORKFLOW_API_KEY":                     "API key required by the agent workflow endpoints.",
+	"WORKFLOW_STORE_PATH":                  "Directory of the workflow store.",
+	"BINARY_STORE_PATH":                    "Directory of the binary store.",
+	"NUMBER_OF_WORKFLOW_WORKERS":           "Number of workers executing workflows in parallel.",
+	"EXTERNALFUNCTIONS_ENDPOINT":           "Endpoint of the Aali Flowkit gRPC server.",
+	"FLOWKIT_PYTHON_ENDPOINT":              "Endpoint of the Aali Flowkit Python API.",
+	"FLOWKIT_PYTHON_API_KEY":               "API key of the Aali Flowkit Python API.",
+	"ENABLE_AUTH":                          "If true, the agent requires authentication and authorization for workflows.",
+	"AZURE_AD_AUTHENTICATION_URL":          "Azure AD URL used to authenticate users.",
+	"ANSYS_AUTHORIZATION_URL":              "Ansys URL used to authorize users.",
+	"ANSYS_AUTHORIZATION_CRYPT_KEY":        "Key used to decrypt Ansys authorization tokens.",
+	"ANSYS_DISCO_CRYPT_PRIVATE_KEY":        "Private key used to decrypt Ansys Discovery messages.",
+	"ANSYS_DISCO_SIGN_PUBLIC_KEY":          "Public key used to verify Ansys Discovery message signatures.",
+	"DISABLE_PUBLIC_WORKFLOWS":             "If true, the public workflows are not loaded.",
+	"LOAD_PRIVATE_WORKFLOWS":               "If true, private workflows are loaded from PRIVATE_WORKFLOWS_FOLDERS.",
+	"GITHUB_USER":                          "GitHub user used to clone private workflows.",
+	"GITHUB_TOKEN":                         "GitHub token used to clone private workflows.",
+	"PRIVATE_WORKFLOWS_FOLDERS":            "Folders containing private workflows.",
+	"EXEC_ENDPOINT":                        "Endpoint of the Aali Exec service.",
+	"EXEC_AGENT_API_KEY":                   "API key used by Aali Exec to connect to the agent.",
+	"MONGO_DB_FOR_MULTI_AGENT":             "If true, multiple agents share their state through MongoDB.",
+	"MONGO_DB_ENDPOINT":                    "Endpoint of the MongoDB server.",
+	"MILLISECONDS_MONGODB_UPDATE_INTERVAL": "Interval in milliseconds between MongoDB state updates.",
+	"EXEC_FILE_STORE_PATH":                 "Directory storing files exchanged with Aali Exec.",
+	"KVDB_ENDPOINT":                        "Endpoint of the Aali key-value database.",
+
+	// Aali Flowkit
+	"EXTERNALFUNCTIONS_GRPC_PORT": "Port of the Aali Flowkit gRPC server.",
+	"FLOWKIT_API_KEY":             "API key of the Aali Flowkit gRPC server.",
+	"LLM_HANDLER_ENDPOINT":        "Endpoint of the Aali LLM handler.",
+	"KNOWLEDGE_DB_ENDPOINT":       "Endpoint of the knowledge database.",
+
+	// Aali LLM
+	"WEBSERVER_PORT":         "Port of the Aali LLM web server.",
+	"MODELS_CONFIG_LOCATION": "Path of the LLM models configuration file.",
+	"LLM_API_KEY":            "API key required by the Aali LLM web server.",
+	"QDRANT_HOST":            "Host of the Qdrant vector database.",
+	"QDRANT_PORT":            "Port of the Qdrant vector database.",
+	"GRAPHDB_ADDRESS":        "Address of the Aali graph database.",
+
+	// Aali Exec
+	"EXEC_ID":                        "Unique identifier of the Aali Exec instance.",
+	"WEBSERVER_PORT_EXEC":            "Port of the Aali Exec web server.",
+	"EXEC_API_KEY":                   "API key required by the Aali Exec web server.",
+	"PYTHON_EXECUTABLE":              "Python executable used to run scripts.",
+	"BASH_EXECUTABLE":                "Bash executable used to run scripts.",
+	"WATCH_FOLDER_PATH":              "Folder watched for transferred files.",
+	"MILLISECONDS_SINCE_LAST_CHANGE": "Time in milliseconds a watched file must be unchanged before it is processed.",
+	"AGENT_ENDPOINT":                 "Endpoint of the Aali agent.",
+	"WORKFLOW_CONFIG_VARIABLES":      "Variables made available to workflows.",
+
+	// Aali Database
+	"KVDB_ADDRESS":   "Address the key-value database listens on.",
+	"KVDB_PATH":      "Directory of the key-value database files.",
+	"KVDB_IN_MEMORY": "If true, the key-value database is kept in memory only.",
+}
+
+// FieldDescription returns the description of a config field.
+//
+// Parameters:
+//   - fieldName: The name of the config field.
+//
+// Returns:
+//   - string: The description, or an empty string if the field has none.
+func FieldDescription(fieldName string) string {
+	return fieldDescriptions[fieldName]
+}
@@ -0,0 +1,451 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+////////////////////////////////
+// Encrypted config values
+////////////////////////////////
+
+const (
+	// ConfigKeyEnvironmentVariable is the environment variable containing the base64 encoded key for encrypted config values.
+	ConfigKeyEnvironmentVariable = "AALI_CONFIG_KEY"
+	// ConfigKeyFileEnvironmentVariable is the environment variable containing the path of a file with the base64 encoded key.
+	ConfigKeyFileEnvironmentVariable = "AALI_CONFIG_KEY_FILE"
+
+	// encryptedValuePrefix and encryptedValueSuffix enclose the base64 encoded nonce and ciphertext of an encrypted value.
+	encryptedValuePrefix = "ENC[AES256_GCM,"
+	encryptedValueSuffix = "]"
+	// encryptionKeySize is the size of an AES-256 key in bytes.
+	encryptionKeySize = 32
+)
+
+// GenerateEncryptionKey creates a random key for encrypted config values.
+//
+// Returns:
+//   - string: The base64 encoded key, to be stored in AALI_CONFIG_KEY or the file in AALI_CONFIG_KEY_FILE.
+//   - error: An error if no random bytes are available.
+func GenerateEncryptionKey() (string, error) {
+	key := make([]byte, encryptionKeySize)
+	_, err := rand.Read(key)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ParseEncryptionKey decodes a base64 encoded key for encrypted config values.
+//
+// Parameters:
+//   - encoded: The base64 encoded key.
+//
+// Returns:
+//   - []byte: The key.
+//   - error: An error if the key is not valid base64 or does not have 32 bytes.
+func ParseEncryptionKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("config encryption key is not valid base64: %w", err)
+	}
+	if len(key) != encryptionKeySize {
+		return nil, fmt.Errorf("config encryption key must have %d bytes, got %d", encryptionKeySize, len(key))
+	}
+	return key, nil
+}
+
+// LoadEncryptionKey reads the key for encrypted config values from AALI_CONFIG_KEY or,
+// if it is not set, from the file in AALI_CONFIG_KEY_FILE.
+//
+// Returns:
+//   - []byte: The key.
+//   - error: An error if neither variable is set or the key is invalid.
+func LoadEncryptionKey() ([]byte, error) {
+	encoded := os.Getenv(ConfigKeyEnvironmentVariable)
+	if encoded == "" {
+		keyFile := os.Getenv(ConfigKeyFileEnvironmentVariable)
+		if keyFile == "" {
+			return nil, fmt.Errorf("neither %v nor %v is set", ConfigKeyEnvironmentVariable, ConfigKeyFileEnvironmentVariable)
+		}
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error in reading config encryption key file: %w", err)
+		}
+		encoded = string(data)
+	}
+	return ParseEncryptionKey(encoded)
+}
+
+// IsEncryptedValue reports whether a config value has the form ENC[AES256_GCM,...].
+//
+// Parameters:
+//   - value: The config value.
+//
+// Returns:
+//   - bool: True if the value is encrypted.
+func IsEncryptedValue(value string) bool {
+	return strings.HasPrefix(value, encryptedValuePrefix) && strings.HasSuffix(value, encryptedValueSuffix)
+}
+
+// EncryptValue encrypts a config value with AES-256-GCM.
+//
+// Parameters:
+//   - key: The 32 byte key.
+//   - plaintext: The value to encrypt.
+//
+// Returns:
+//   - string: The encrypted value in the form ENC[AES256_GCM,<base64 nonce and ciphertext>].
+//   - error: An error if the key is invalid.
+func EncryptValue(key []byte, plaintext string) (string, error) {
+	aead, err := newConfigCipher(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedValuePrefix + base64.StdEncoding.EncodeToString(sealed) + encryptedValueSuffix, nil
+}
+
+// DecryptValue decrypts a config value encrypted by EncryptValue.
+//
+// Parameters:
+//   - key: The 32 byte key.
+//   - value: The encrypted value.
+//
+// Returns:
+//   - string: The plaintext.
+//   - error: An error if the value is malformed or was encrypted with a different key.
+func DecryptValue(key []byte, value string) (string, error) {
+	if !IsEncryptedValue(value) {
+		return "", errors.New("value is not of the form ENC[AES256_GCM,...]")
+	}
+	aead, err := newConfigCipher(key)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(value, encryptedValuePrefix), encryptedValueSuffix))
+	if err != nil {
+		return "", fmt.Errorf("encrypted value is not valid base64: %w", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("encrypted value is too short")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("encrypted value cannot be decrypted with the given key")
+	}
+	return string(plaintext), nil
+}
+
+// newConfigCipher creates the AES-256-GCM cipher for config values.
+//
+// Parameters:
+//   - key: The 32 byte key.
+//
+// Returns:
+//   - cipher.AEAD: The cipher.
+//   - error: An error if the key does not have 32 bytes.
+func newConfigCipher(key []byte) (cipher.AEAD, error) {
+	if len(key) != encryptionKeySize {
+		return nil, fmt.Errorf("config encryption key must have %d bytes, got %d", encryptionKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+////////////////////////////////
+// Decryption at load time
+////////////////////////////////
+
+// decrypt replaces the encrypted values of the merged config files by their plaintext.
+// The key is only loaded with LoadEncryptionKey if no key is given and an encrypted value is found.
+// Values that cannot be decrypted are recorded as violations and removed.
+//
+// Parameters:
+//   - key: The key, or nil to load it when needed.
+func (layered *configFiles) decrypt(key []byte) {
+	configType := reflect.TypeOf(Config{})
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < configType.NumField(); i++ {
+		if isDeprecatedField(configType.Field(i)) {
+			continue
+		}
+		fields[yamlKey(configType.Field(i))] = configType.Field(i)
+	}
+
+	var keyErr error
+	getKey := func() ([]byte, error) {
+		if key == nil && keyErr == nil {
+			key, keyErr = LoadEncryptionKey()
+		}
+		return key, keyErr
+	}
+
+	keys := make([]string, 0, len(layered.values))
+	for valueKey := range layered.values {
+		keys = append(keys, valueKey)
+	}
+	sort.Strings(keys)
+
+	for _, valueKey := range keys {
+		value, err := decryptValues(layered.values[valueKey], getKey)
+		original, isString := layered.values[valueKey].(string)
+		field, isField := fields[valueKey]
+		if err == nil && isString && isField && IsEncryptedValue(original) {
+			value, err = convertInterpolated(value, field.Type)
+		}
+		if err != nil {
+			name := valueKey
+			if isField {
+				name = field.Name
+			}
+			layered.violations = append(layered.violations, Violation{
+				Field:   name,
+				Rule:    "decrypt",
+				Message: fmt.Sprintf("config property '%v' cannot be decrypted: %v", name, err),
+			})
+			delete(layered.values, valueKey)
+			continue
+		}
+		layered.values[valueKey] = value
+	}
+}
+
+// decryptValues decrypts an encrypted string value or the encrypted strings in a list or map.
+//
+// Parameters:
+//   - value: The value.
+//   - getKey: Returns the key.
+//
+// Returns:
+//   - interface{}: The decrypted value.
+//   - error: An error if the key is not available or a value cannot be decrypted.
+func decryptValues(value interface{}, getKey func() ([]byte, error)) (interface{}, error) {
+	switch typed := value.(type) {
+	case string:
+		if !IsEncryptedValue(typed) {
+			return typed, nil
+		}
+		key, err := getKey()
+		if err != nil {
+			return nil, err
+		}
+		return DecryptValue(key, typed)
+	case []interface{}:
+		decrypted := make([]interface{}, len(typed))
+		for i, item := range typed {
+			var err error
+			decrypted[i], err = decryptValues(item, getKey)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return decrypted, nil
+	case map[string]interface{}:
+		decrypted := make(map[string]interface{}, len(typed))
+		for mapKey, item := range typed {
+			var err error
+			decrypted[mapKey], err = decryptValues(item, getKey)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return decrypted, nil
+	default:
+		return value, nil
+	}
+}
+
+////////////////////////////////
+// Encrypt and rotate files
+////////////////////////////////
+
+// EncryptFileValues encrypts values of a YAML config file in place. Comments and key order are kept.
+// Values that are empty or already encrypted are left unchanged; in lists and maps every string is encrypted.
+//
+// Parameters:
+//   - fileName: The name of the configuration file.
+//   - key: The 32 byte key.
+//   - fields: The YAML keys to encrypt; if empty, all fields tagged as secret are encrypted.
+//
+// Returns:
+//   - err: An error if the file cannot be read, parsed or written.
+func EncryptFileValues(fileName string, key []byte, fields ...string) (err error) {
+	if len(fields) == 0 {
+		configType := reflect.TypeOf(Config{})
+		for i := 0; i < configType.NumField(); i++ {
+			if isDeprecatedField(configType.Field(i)) {
+				continue
+			}
+			if configType.Field(i).Tag.Get("secret") == "true" {
+				fields = append(fields, yamlKey(configType.Field(i)))
+			}
+		}
+	}
+	selected := map[string]bool{}
+	for _, field := range fields {
+		selected[field] = true
+	}
+
+	return rewriteFileValues(fileName, func(topLevelKey string, node *yaml3.Node) error {
+		if !selected[topLevelKey] || node.Value == "" || IsEncryptedValue(node.Value) {
+			return nil
+		}
+		encrypted, err := EncryptValue(key, node.Value)
+		if err != nil {
+			return err
+		}
+		node.Value = encrypted
+		node.Tag = "!!str"
+		return nil
+	})
+}
+
+// RotateFileKey re-encrypts all encrypted values of a YAML config file with a new key, in place.
+//
+// Parameters:
+//   - fileName: The name of the configuration file.
+//   - oldKey: The key the values are currently encrypted with.
+//   - newKey: The new key.
+//
+// Returns:
+//   - err: An error if the file cannot be read, parsed or written, or a value cannot be decrypted with oldKey.
+//     The file is not modified if an error occurs.
+func RotateFileKey(fileName string, oldKey []byte, newKey []byte) (err error) {
+	return rewriteFileValues(fileName, func(topLevelKey string, node *yaml3.Node) error {
+		if !IsEncryptedValue(node.Value) {
+			return nil
+		}
+		plaintext, err := DecryptValue(oldKey, node.Value)
+		if err != nil {
+			return fmt.Errorf("%v: %w", topLevelKey, err)
+		}
+		node.Value, err = EncryptValue(newKey, plaintext)
+		return err
+	})
+}
+
+// rewriteFileValues applies a function to every scalar value of a YAML file and writes the file back.
+//
+// Parameters:
+//   - fileName: The name of the YAML file.
+//   - rewrite: Called with the top level key and the scalar node; may modify the node.
+//
+// Returns:
+//   - err: An error if the file is not a YAML file, cannot be read, parsed or written, or rewrite fails.
+func rewriteFileValues(fileName string, rewrite func(topLevelKey string, node *yaml3.Node) error) (err error) {
+	if configFormat(fileName) != formatYAML {
+		return fmt.Errorf("%v: encrypting values is only supported in YAML config files", fileName)
+	}
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	var document yaml3.Node
+	err = yaml3.Unmarshal(data, &document)
+	if err != nil {
+		return fmt.Errorf("%v contains invalid YAML: %w", fileName, err)
+	}
+	if document.Kind != yaml3.DocumentNode || len(document.Content) == 0 || document.Content[0].Kind != yaml3.MappingNode {
+		return fmt.Errorf("%v does not contain a mapping", fileName)
+	}
+
+	root := document.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		err = walkScalars(root.Content[i+1], func(node *yaml3.Node) error {
+			return rewrite(root.Content[i].Value, node)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var buffer bytes.Buffer
+	encoder := yaml3.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	err = encoder.Encode(&document)
+	if err != nil {
+		return err
+	}
+	err = encoder.Close()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, buffer.Bytes(), info.Mode().Perm())
+}
+
+// walkScalars calls a function for every scalar value in a YAML node; mapping keys are skipped.
+//
+// Parameters:
+//   - node: The YAML node.
+//   - visit: The function to call.
+//
+// Returns:
+//   - error: The first error returned by visit.
+func walkScalars(node *yaml3.Node, visit func(node *yaml3.Node) error) error {
+	switch node.Kind {
+	case yaml3.ScalarNode:
+		return visit(node)
+	case yaml3.SequenceNode:
+		for _, item := range node.Content {
+			err := walkScalars(item, visit)
+			if err != nil {
+				return err
+			}
+		}
+	case yaml3.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			err := walkScalars(node.Content[i], visit)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestEncryptValue tests that encrypted values can only be decrypted with the same key
+func TestEncryptValue(t *testing.T) {
+	encoded, err := GenerateEncryptionKey()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	key, err := ParseEncryptionKey(encoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	encrypted, err := EncryptValue(key, "secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !IsEncryptedValue(encrypted) || strings.Contains(encrypted, "secret") {
+		t.Errorf("Expected ENC[AES256_GCM,...] value, got %q", encrypted)
+	}
+	decrypted, err := DecryptValue(key, encrypted)
+	if err != nil || decrypted != "secret" {
+		t.Errorf("Expected %q, got %q (%v)", "secret", decrypted, err)
+	}
+
+	otherKey := make([]byte, encryptionKeySize)
+	_, err = DecryptValue(otherKey, encrypted)
+	if err == nil {
+		t.Errorf("Expected error for a different key")
+	}
+	_, err = ParseEncryptionKey("c2hvcnQ=")
+	if err == nil {
+		t.Errorf("Expected error for a short key")
+	}
+}
+
+// TestEncryptedConfigFile tests encrypting a config file, loading it and rotating its key
+func TestEncryptedConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": "# Credentials\nFLOWKIT_API_KEY: flowkit-key # comment\n" +
+			"ANSYS_AUTHORIZATION_CRYPT_KEY: crypt-key\nSERVICE_NAME: service\nQDRANT_PORT: \"6333\"\n",
+	})
+	fileName := filepath.Join(dir, "config.yaml")
+	key := make([]byte, encryptionKeySize)
+	newKey := []byte(strings.Repeat("k", encryptionKeySize))
+
+	// Secret fields are encrypted by default, other fields on request
+	err := EncryptFileValues(fileName, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = EncryptFileValues(fileName, key, "QDRANT_PORT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if strings.Contains(content, "flowkit-key") || strings.Contains(content, "crypt-key") || strings.Contains(content, "6333") {
+		t.Errorf("Expected values to be encrypted, got:\n%v", content)
+	}
+	if !strings.Contains(content, "# Credentials") || !strings.Contains(content, "# comment") || !strings.Contains(content, "SERVICE_NAME: service") {
+		t.Errorf("Expected comments and other values to be kept, got:\n%v", content)
+	}
+
+	// Values are decrypted and converted at load time
+	config, err := Load(LoadOptions{FilePath: fileName, IgnoreEnvironment: true, EncryptionKey: key})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.FLOWKIT_API_KEY != "flowkit-key" || config.ANSYS_AUTHORIZATION_CRYPT_KEY != "crypt-key" || config.QDRANT_PORT != 6333 {
+		t.Errorf("Expected decrypted values, got %q, %q and %v", config.FLOWKIT_API_KEY, config.ANSYS_AUTHORIZATION_CRYPT_KEY, config.QDRANT_PORT)
+	}
+
+	// After rotation only the new key works; it is read from the key file
+	err = RotateFileKey(fileName, key, newKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = RotateFileKey(fileName, key, newKey)
+	if err == nil {
+		t.Errorf("Expected error for rotating with the old key")
+	}
+	_, err = Load(LoadOptions{FilePath: fileName, IgnoreEnvironment: true, EncryptionKey: key})
+	validationError, ok := err.(*ValidationError)
+	if !ok || len(validationError.Violations) != 3 || validationError.Violations[0].Rule != "decrypt" {
+		t.Errorf("Expected decrypt violations for the old key, got %v", err)
+	}
+	keyFile := filepath.Join(dir, "config.key")
+	writeFiles(t, dir, map[string]string{"config.key": "a2tra2tra2tra2tra2tra2tra2tra2tra2tra2tra2s=\n"})
+	t.Setenv(ConfigKeyEnvironmentVariable, "")
+	t.Setenv(ConfigKeyFileEnvironmentVariable, keyFile)
+	config, err = Load(LoadOptions{FilePath: fileName, IgnoreEnvironment: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.FLOWKIT_API_KEY != "flowkit-key" {
+		t.Errorf("Expected value decrypted with the key file, got %q", config.FLOWKIT_API_KEY)
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+//////////////////////////////////////////////////
+// Read Config variables from Environment variables
+//////////////////////////////////////////////////
+
+// EnvironmentVariablePrefix is the prefix of the environment variables that override config values,
+// e.g. AALI_AGENT_PORT overrides AGENT_PORT.
+const EnvironmentVariablePrefix = "AALI_"
+
+// InitGlobalConfigFromEnvironment overlays the global configuration with values
+// from environment variables prefixed with EnvironmentVariablePrefix.
+//
+// Returns:
+//   - err: An error if one or more environment variables could not be parsed.
+func InitGlobalConfigFromEnvironment() (err error) {
+	if GlobalConfig == nil {
+		GlobalConfig = &Config{}
+	}
+	return ApplyEnvironmentVariables(GlobalConfig, EnvironmentVariablePrefix)
+}
+
+// ApplyEnvironmentVariables sets every config field for which an environment variable
+// named prefix + YAML key, or prefix + a deprecated alias of the key, exists.
+//
+// Strings, booleans and integers are parsed directly. Slices accept either a JSON array
+// or a comma separated list ("a,b,c"). Maps accept either a JSON object or a comma
+// separated list of key=value pairs ("key1=value1,key2=value2"); maps of other value types,
+// like FEATURE_FLAGS, accept a JSON object.
+//
+// Parameters:
+//   - config: The configuration object to update.
+//   - prefix: The prefix of the environment variables.
+//
+// Returns:
+//   - err: An error listing every environment variable that could not be parsed.
+func ApplyEnvironmentVariables(config *Config, prefix string) (err error) {
+	return applyEnvironmentVariables(config, prefix, nil)
+}
+
+// applyEnvironmentVariables overlays the config with environment variables like ApplyEnvironmentVariables.
+//
+// Parameters:
+//   - config: The configuration object to update.
+//   - prefix: The prefix of the environment variables.
+//   - provenance: Records the fields set by an environment variable; may be nil.
+//
+// Returns:
+//   - err: An error listing every environment variable that could not be parsed.
+func applyEnvironmentVariables(config *Config, prefix string, provenance Provenance) (err error) {
+	configValue := reflect.ValueOf(config).Elem()
+	configType := configValue.Type()
+
+	var errs []error
+	for i := 0; i < configType.NumField(); i++ {
+		if isDeprecatedField(configType.Field(i)) {
+			continue
+		}
+		name := prefix + yamlKey(configType.Field(i))
+		value, ok := os.LookupEnv(name)
+		for _, alias := range fieldAliases(configType.Field(i)) {
+			if ok {
+				break
+			}
+			value, ok = os.LookupEnv(prefix + alias)
+			if ok {
+				log.Printf("Warning: environment: %v", deprecationMessage(prefix+alias, name))
+				name = prefix + alias
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		err := setFieldFromString(configValue.Field(i), value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid value for environment variable '%v': %w", name, err))
+			continue
+		}
+		provenance.record(configType.Field(i).Name, SourceEnvironment, name)
+	}
+
+	return errors.Join(errs...)
+}
+
+// setFieldFromString parses a string and assigns it to a config field.
+//
+// Parameters:
+//   - field: The field to set.
+//   - value: The string representation of the value.
+//
+// Returns:
+//   - err: An error if the value could not be converted to the field type.
+func setFieldFromString(field reflect.Value, value string) (err error) {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(parsed))
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported field type: %v", field.Type())
+		}
+		parsed, err := parseStringSlice(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(parsed))
+	case reflect.Map:
+		if field.Type() != reflect.TypeOf(map[string]string{}) {
+			// Other maps, e.g. FEATURE_FLAGS, are given as JSON object
+			parsed := reflect.New(field.Type())
+			err := json.Unmarshal([]byte(value), parsed.Interface())
+			if err != nil {
+				return err
+			}
+			field.Set(parsed.Elem())
+			return nil
+		}
+		parsed, err := parseStringMap(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(parsed))
+	default:
+		return fmt.Errorf("unsupported field type: %v", field.Kind())
+	}
+
+	return nil
+}
+
+// parseStringSlice parses a JSON array or a comma separated list into a string slice.
+//
+// Parameters:
+//   - value: The string to parse.
+//
+// Returns:
+//   - []string: The parsed slice.
+//   - error: An error if the JSON array is malformed.
+func parseStringSlice(value string) ([]string, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return []string{}, nil
+	}
+
+	if strings.HasPrefix(trimmed, "[") {
+		var parsed []string
+		err := json.Unmarshal([]byte(trimmed), &parsed)
+		if err != nil {
+			return nil, err
+		}
+		return parsed, nil
+	}
+
+	parsed := strings.Split(trimmed, ",")
+	for i := range parsed {
+		parsed[i] = strings.TrimSpace(parsed[i])
+	}
+	return parsed, nil
+}
+
+// parseStringMap parses a JSON object or a comma separated list of key=value pairs into a string map.
+//
+// Parameters:
+//   - value: The string to parse.
+//
+// Returns:
+//   - map[string]string: The parsed map.
+//   - error: An error if the JSON object is malformed or a pair has no '='.
+func parseStringMap(value string) (map[string]string, error) {
+	trimmed := strings.TrimSpace(value)
+	parsed := map[string]string{}
+	if trimmed == "" {
+		return parsed, nil
+	}
+
+	if strings.HasPrefix(trimmed, "{") {
+		err := json.Unmarshal([]byte(trimmed), &parsed)
+		if err != nil {
+			return nil, err
+		}
+		return parsed, nil
+	}
+
+	for _, pair := range strings.Split(trimmed, ",") {
+		key, val, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("expected key=value pair, got %q", pair)
+		}
+		parsed[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	return parsed, nil
+}
+
+// yamlKey returns the YAML key of a config field, falling back to the field name.
+//
+// Parameters:
+//   - field: The struct field.
+//
+// Returns:
+//   - string: The YAML key.
+func yamlKey(field reflect.StructField) string {
+	tag := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if tag == "" {
+		return field.Name
+	}
+	return tag
+}
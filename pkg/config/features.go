@@ -0,0 +1,160 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+)
+
+////////////////////////////////
+// Feature flags
+////////////////////////////////
+
+// FeatureEnabled reports whether a feature flag of the active configuration is enabled for a user and workflow.
+// Since the active configuration is swapped on reload, flag changes take effect without restart.
+//
+// Parameters:
+//   - name: The name of the feature flag in FEATURE_FLAGS.
+//   - target: The user and workflow to evaluate the flag for; may be empty.
+//
+// Returns:
+//   - bool: True if the flag is enabled for the target; false if the flag or the configuration does not exist.
+func FeatureEnabled(name string, target FeatureTarget) bool {
+	config := Current()
+	if config == nil {
+		return false
+	}
+	return config.FeatureEnabled(name, target)
+}
+
+// FeatureEnabled reports whether a feature flag of the config is enabled for a user and workflow.
+//
+// A flag is evaluated as follows:
+//   - if enabled is false, the feature is disabled for everyone,
+//   - if the user mail (case-insensitive) or the workflow ID is listed, the feature is enabled,
+//   - if percentage is set, the feature is enabled for that percentage of users, or of workflows if no user mail
+//     is given; the same user or workflow always gets the same result for the same flag,
+//   - otherwise, the feature is enabled for everyone unless users or workflows are listed.
+//
+// Parameters:
+//   - name: The name of the feature flag in FEATURE_FLAGS.
+//   - target: The user and workflow to evaluate the flag for; may be empty.
+//
+// Returns:
+//   - bool: True if the flag is enabled for the target; false if the flag does not exist.
+func (config Config) FeatureEnabled(name string, target FeatureTarget) bool {
+	flag, ok := config.FEATURE_FLAGS[name]
+	if !ok || !flag.Enabled {
+		return false
+	}
+
+	if target.UserMail != "" {
+		for _, user := range flag.Users {
+			if strings.EqualFold(user, target.UserMail) {
+				return true
+			}
+		}
+	}
+	if target.WorkflowID != "" {
+		for _, workflow := range flag.Workflows {
+			if workflow == target.WorkflowID {
+				return true
+			}
+		}
+	}
+
+	if flag.Percentage > 0 {
+		subject := strings.ToLower(target.UserMail)
+		if subject == "" {
+			subject = target.WorkflowID
+		}
+		if subject == "" {
+			return false
+		}
+		return rolloutBucket(name, subject) < flag.Percentage
+	}
+	return len(flag.Users) == 0 && len(flag.Workflows) == 0
+}
+
+// FeatureFlagNames returns the names of the feature flags of the active configuration.
+//
+// Returns:
+//   - []string: The sorted flag names.
+func FeatureFlagNames() []string {
+	config := Current()
+	if config == nil {
+		return []string{}
+	}
+	names := make([]string, 0, len(config.FEATURE_FLAGS))
+	for name := range config.FEATURE_FLAGS {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rolloutBucket assigns a user or workflow to one of 100 buckets, stable across processes and restarts.
+// The flag name is part of the hash, so different flags roll out to different subjects.
+//
+// Parameters:
+//   - name: The name of the feature flag.
+//   - subject: The user mail or workflow ID.
+//
+// Returns:
+//   - int: The bucket between 0 and 99.
+func rolloutBucket(name string, subject string) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(name + ":" + subject))
+	return int(hash.Sum32() % 100)
+}
+
+// validFeatureFlags requires the percentage of every feature flag to be between 0 and 100.
+//
+// Parameters:
+//   - config: The configuration object to validate.
+//
+// Returns:
+//   - []Violation: A violation for each flag with an invalid percentage.
+func validFeatureFlags(config Config) []Violation {
+	names := make([]string, 0, len(config.FEATURE_FLAGS))
+	for name := range config.FEATURE_FLAGS {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	violations := []Violation{}
+	for _, name := range names {
+		percentage := config.FEATURE_FLAGS[name].Percentage
+		if percentage < 0 || percentage > 100 {
+			violations = append(violations, Violation{
+				Field:   "FEATURE_FLAGS",
+				Rule:    "percentage",
+				Message: fmt.Sprintf("percentage of feature flag '%v' must be between 0 and 100, got %d", name, percentage),
+			})
+		}
+	}
+	return violations
+}
@@ -0,0 +1,115 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestFeatureEnabled tests targeting and percentage rollouts of feature flags
+func TestFeatureEnabled(t *testing.T) {
+	config := Config{FEATURE_FLAGS: map[string]FeatureFlag{
+		"everyone": {Enabled: true},
+		"disabled": {Enabled: false, Users: []string{"alice@ansys.com"}, Percentage: 100},
+		"targeted": {Enabled: true, Users: []string{"Alice@ansys.com"}, Workflows: []string{"wf-1"}},
+		"rollout":  {Enabled: true, Users: []string{"alice@ansys.com"}, Percentage: 30},
+	}}
+
+	tests := []struct {
+		flag     string
+		target   FeatureTarget
+		expected bool
+	}{
+		{"everyone", FeatureTarget{}, true},
+		{"unknown", FeatureTarget{UserMail: "alice@ansys.com"}, false},
+		{"disabled", FeatureTarget{UserMail: "alice@ansys.com"}, false},
+		{"targeted", FeatureTarget{UserMail: "alice@ANSYS.com"}, true},
+		{"targeted", FeatureTarget{UserMail: "bob@ansys.com", WorkflowID: "wf-1"}, true},
+		{"targeted", FeatureTarget{UserMail: "bob@ansys.com", WorkflowID: "wf-2"}, false},
+		{"rollout", FeatureTarget{UserMail: "alice@ansys.com"}, true},
+		{"rollout", FeatureTarget{}, false},
+	}
+	for _, tt := range tests {
+		if enabled := config.FeatureEnabled(tt.flag, tt.target); enabled != tt.expected {
+			t.Errorf("FeatureEnabled(%q, %+v): expected %v, got %v", tt.flag, tt.target, tt.expected, enabled)
+		}
+	}
+
+	// Rollouts are stable per subject and cover roughly the configured percentage
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		target := FeatureTarget{UserMail: fmt.Sprintf("user%d@ansys.com", i)}
+		result := config.FeatureEnabled("rollout", target)
+		if result != config.FeatureEnabled("rollout", target) {
+			t.Fatalf("Expected stable result for %v", target.UserMail)
+		}
+		if result {
+			enabled++
+		}
+	}
+	if enabled < 250 || enabled > 350 {
+		t.Errorf("Expected about 30%% of users to be enabled, got %d of 1000", enabled)
+	}
+	if rolloutBucket("rollout", "wf-1") != 63 {
+		t.Errorf("Expected bucket of 'wf-1' to be stable across releases, got %d", rolloutBucket("rollout", "wf-1"))
+	}
+}
+
+// TestFeatureFlagSources tests that feature flags are read from config files and environment variables and validated
+func TestFeatureFlagSources(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": "FEATURE_FLAGS:\n  new-ui:\n    enabled: true\n    users: [alice@ansys.com]\n    percentage: 10\n",
+	})
+	fileName := filepath.Join(dir, "config.yaml")
+	layered, err := readConfigFiles(fileName, nil, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config, err := layered.decode(fileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]FeatureFlag{"new-ui": {Enabled: true, Users: []string{"alice@ansys.com"}, Percentage: 10}}
+	if !reflect.DeepEqual(config.FEATURE_FLAGS, expected) {
+		t.Errorf("Expected flags %+v, got %+v", expected, config.FEATURE_FLAGS)
+	}
+
+	t.Setenv("TEST_FEATURE_FLAGS", `{"new-ui": {"enabled": true, "workflows": ["wf-1"], "percentage": 150}}`)
+	err = ApplyEnvironmentVariables(&config, "TEST_")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = map[string]FeatureFlag{"new-ui": {Enabled: true, Workflows: []string{"wf-1"}, Percentage: 150}}
+	if !reflect.DeepEqual(config.FEATURE_FLAGS, expected) {
+		t.Errorf("Expected flags %+v, got %+v", expected, config.FEATURE_FLAGS)
+	}
+
+	violations := validFeatureFlags(config)
+	if len(violations) != 1 || violations[0].Field != "FEATURE_FLAGS" || violations[0].Rule != "percentage" {
+		t.Errorf("Expected percentage violation, got %+v", violations)
+	}
+}
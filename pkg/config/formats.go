@@ -0,0 +1,568 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// Config file formats, selected by the file extension.
+const (
+	formatYAML   = "YAML"
+	formatJSON   = "JSON"
+	formatTOML   = "TOML"
+	formatDotEnv = ".env"
+)
+
+// yamlErrorPattern extracts the line and message from the errors of yaml.v2.
+var yamlErrorPattern = regexp.MustCompile(`line (\d+): (.*)`)
+
+// tomlErrorPrefix matches the position prefix of the errors of the TOML parser.
+var tomlErrorPrefix = regexp.MustCompile(`^toml: line \d+( \(last key "[^"]*"\))?: `)
+
+// tomlKeyPattern matches a top level key or table header in a TOML file.
+var tomlKeyPattern = regexp.MustCompile(`^(\s*)(\[\[?\s*)?("[^"]*"|[A-Za-z0-9_+-]+)`)
+
+////////////////////////////////
+// Parse errors
+////////////////////////////////
+
+// Error returns the error message with the file, line and column.
+//
+// Returns:
+//   - string: The error message, e.g. "config.yaml: line 3, column 5: unknown config key 'LOG_LEVLE'".
+func (parseError *ParseError) Error() string {
+	var position []string
+	if parseError.File != "" {
+		position = append(position, parseError.File)
+	}
+	if parseError.Line > 0 {
+		line := fmt.Sprintf("line %d", parseError.Line)
+		if parseError.Column > 0 {
+			line += fmt.Sprintf(", column %d", parseError.Column)
+		}
+		position = append(position, line)
+	}
+	return strings.Join(append(position, parseError.Message), ": ")
+}
+
+// offsetError creates a ParseError at a byte offset of a file.
+//
+// Parameters:
+//   - fileName: The name of the file.
+//   - data: The content of the file.
+//   - offset: The byte offset of the error.
+//   - message: The error message.
+//
+// Returns:
+//   - *ParseError: The error with the line and column of the offset.
+func offsetError(fileName string, data []byte, offset int64, message string) *ParseError {
+	position := offsetPosition(data, offset)
+	return &ParseError{File: fileName, Line: position.line, Column: position.column, Message: message}
+}
+
+// offsetPosition converts a byte offset into a line and column.
+//
+// Parameters:
+//   - data: The content of the file.
+//   - offset: The byte offset.
+//
+// Returns:
+//   - keyPosition: The line and column, both starting at 1.
+func offsetPosition(data []byte, offset int64) keyPosition {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	before := data[:offset]
+	return keyPosition{
+		line:   bytes.Count(before, []byte("\n")) + 1,
+		column: len(before) - bytes.LastIndexByte(before, '\n'),
+	}
+}
+
+////////////////////////////////
+// Config file formats
+////////////////////////////////
+
+// configFormat returns the format of a config file selected by its extension:
+// .json for JSON, .toml for TOML, .env for .env files and YAML for all others.
+//
+// Parameters:
+//   - fileName: The name of the file.
+//
+// Returns:
+//   - string: The format.
+func configFormat(fileName string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".json":
+		return formatJSON
+	case ".toml":
+		return formatTOML
+	case ".env":
+		return formatDotEnv
+	default:
+		return formatYAML
+	}
+}
+
+// parseConfigFile parses a config file in the format selected by its extension.
+//
+// Parameters:
+//   - fileName: The name of the file.
+//   - data: The content of the file.
+//
+// Returns:
+//   - map[string]interface{}: The top level values by key.
+//   - map[string]keyPosition: The position of each top level key, if known.
+//   - error: A *ParseError with the position of the syntax error.
+func parseConfigFile(fileName string, data []byte) (map[string]interface{}, map[string]keyPosition, error) {
+	switch configFormat(fileName) {
+	case formatJSON:
+		return parseJSONConfig(fileName, data)
+	case formatTOML:
+		return parseTOMLConfig(fileName, data)
+	case formatDotEnv:
+		return parseDotEnvConfig(fileName, data)
+	default:
+		return parseYAMLConfig(fileName, data)
+	}
+}
+
+// parseYAMLConfig parses a YAML config file.
+//
+// Parameters:
+//   - fileName: The name of the file.
+//   - data: The content of the file.
+//
+// Returns:
+//   - map[string]interface{}: The top level values by key.
+//   - map[string]keyPosition: The position of each top level key.
+//   - error: A *ParseError with the line of the syntax error.
+func parseYAMLConfig(fileName string, data []byte) (map[string]interface{}, map[string]keyPosition, error) {
+	var values map[string]interface{}
+	err := yaml.Unmarshal(data, &values)
+	if err != nil {
+		match := yamlErrorPattern.FindStringSubmatch(err.Error())
+		if match == nil {
+			return nil, nil, &ParseError{File: fileName, Message: "invalid YAML: " + strings.TrimPrefix(err.Error(), "yaml: ")}
+		}
+		line, _ := strconv.Atoi(match[1])
+		return nil, nil, &ParseError{File: fileName, Line: line, Message: "invalid YAML: " + match[2]}
+	}
+
+	// yaml.v3 exposes the position of the keys
+	positions := map[string]keyPosition{}
+	var document yaml3.Node
+	err = yaml3.Unmarshal(data, &document)
+	if err == nil && document.Kind == yaml3.DocumentNode && len(document.Content) > 0 && document.Content[0].Kind == yaml3.MappingNode {
+		root := document.Content[0]
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			positions[root.Content[i].Value] = keyPosition{line: root.Content[i].Line, column: root.Content[i].Column}
+		}
+	}
+	return values, positions, nil
+}
+
+// parseJSONConfig parses a JSON config file containing an object.
+//
+// Parameters:
+//   - fileName: The name of the file.
+//   - data: The content of the file.
+//
+// Returns:
+//   - map[string]interface{}: The top level values by key; integral numbers are converted to int64.
+//   - map[string]keyPosition: The position of each top level key.
+//   - error: A *ParseError with the line and column of the syntax error.
+func parseJSONConfig(fileName string, data []byte) (map[string]interface{}, map[string]keyPosition, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return map[string]interface{}{}, map[string]keyPosition{}, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var values map[string]interface{}
+	err := decoder.Decode(&values)
+	if err == nil {
+		_, err = decoder.Token()
+		if err == io.EOF {
+			err = nil
+		} else if err == nil {
+			return nil, nil, offsetError(fileName, data, decoder.InputOffset(), "invalid JSON: unexpected content after the top level object")
+		}
+	}
+	if err != nil {
+		var syntaxError *json.SyntaxError
+		var typeError *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxError):
+			return nil, nil, offsetError(fileName, data, syntaxError.Offset-1, "invalid JSON: "+syntaxError.Error())
+		case errors.As(err, &typeError):
+			return nil, nil, offsetError(fileName, data, typeError.Offset-1, "invalid JSON: the content must be an object")
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return nil, nil, offsetError(fileName, data, int64(len(data)), "invalid JSON: unexpected end of file")
+		default:
+			return nil, nil, &ParseError{File: fileName, Message: "invalid JSON: " + err.Error()}
+		}
+	}
+
+	// Walk the tokens of the top level object to find the position of the keys
+	positions := map[string]keyPosition{}
+	decoder = json.NewDecoder(bytes.NewReader(data))
+	_, err = decoder.Token()
+	for err == nil && decoder.More() {
+		start := decoder.InputOffset()
+		for start < int64(len(data)) && strings.ContainsRune(" \t\r\n,", rune(data[start])) {
+			start++
+		}
+		var token json.Token
+		token, err = decoder.Token()
+		key, ok := token.(string)
+		if err != nil || !ok {
+			break
+		}
+		positions[key] = offsetPosition(data, start)
+		var value json.RawMessage
+		err = decoder.Decode(&value)
+	}
+
+	for key, value := range values {
+		values[key] = normalizeJSONValue(value)
+	}
+	return values, positions, nil
+}
+
+// normalizeJSONValue converts the json.Number values of a parsed JSON value to int64 or float64.
+//
+// Parameters:
+//   - value: The parsed JSON value.
+//
+// Returns:
+//   - interface{}: The value with converted numbers.
+func normalizeJSONValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case json.Number:
+		integer, err := typed.Int64()
+		if err == nil {
+			return integer
+		}
+		float, _ := typed.Float64()
+		return float
+	case map[string]interface{}:
+		for key, item := range typed {
+			typed[key] = normalizeJSONValue(item)
+		}
+		return typed
+	case []interface{}:
+		for i, item := range typed {
+			typed[i] = normalizeJSONValue(item)
+		}
+		return typed
+	default:
+		return value
+	}
+}
+
+// parseTOMLConfig parses a TOML config file.
+//
+// Parameters:
+//   - fileName: The name of the file.
+//   - data: The content of the file.
+//
+// Returns:
+//   - map[string]interface{}: The top level values by key.
+//   - map[string]keyPosition: The position of each top level key and table.
+//   - error: A *ParseError with the line and column of the syntax error.
+func parseTOMLConfig(fileName string, data []byte) (map[string]interface{}, map[string]keyPosition, error) {
+	values := map[string]interface{}{}
+	_, err := toml.Decode(string(data), &values)
+	if err != nil {
+		var parseError toml.ParseError
+		if errors.As(err, &parseError) {
+			message := parseError.Message
+			if message == "" {
+				message = tomlErrorPrefix.ReplaceAllString(err.Error(), "")
+			}
+			return nil, nil, offsetError(fileName, data, int64(parseError.Position.Start), "invalid TOML: "+message)
+		}
+		return nil, nil, &ParseError{File: fileName, Message: "invalid TOML: " + err.Error()}
+	}
+
+	// Keys before the first table header and table headers are top level keys
+	positions := map[string]keyPosition{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	inTable := false
+	for scanner.Scan() {
+		lineNumber++
+		match := tomlKeyPattern.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		isHeader := match[2] != ""
+		if isHeader {
+			inTable = true
+		} else if inTable {
+			continue
+		}
+		key := strings.Trim(match[3], `"`)
+		_, exists := positions[key]
+		if !exists {
+			positions[key] = keyPosition{line: lineNumber, column: len(match[1]) + len(match[2]) + 1}
+		}
+	}
+	return values, positions, nil
+}
+
+// parseDotEnvConfig parses a .env config file. Values of config fields that are integers, booleans,
+// lists (JSON array or comma separated) or maps (JSON object or key=value pairs) are converted to the field type;
+// values that cannot be converted are kept as string and reported when the config is decoded.
+//
+// Parameters:
+//   - fileName: The name of the file.
+//   - data: The content of the file.
+//
+// Returns:
+//   - map[string]interface{}: The values by key.
+//   - map[string]keyPosition: The position of each key.
+//   - error: A *ParseError with the line of the malformed line.
+func parseDotEnvConfig(fileName string, data []byte) (map[string]interface{}, map[string]keyPosition, error) {
+	parsed, lines, err := parseDotEnvLines(data)
+	if err != nil {
+		var parseError *ParseError
+		if errors.As(err, &parseError) {
+			parseError.File = fileName
+		}
+		return nil, nil, err
+	}
+
+	fields := configFieldsByKey()
+	values := make(map[string]interface{}, len(parsed))
+	positions := make(map[string]keyPosition, len(parsed))
+	for key, value := range parsed {
+		values[key] = value
+		positions[key] = keyPosition{line: lines[key], column: 1}
+		field, ok := fields[strings.TrimSuffix(key, appendSuffix)]
+		if !ok {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.Int, reflect.Bool:
+			converted, err := convertInterpolated(value, field.Type)
+			if err == nil {
+				values[key] = converted
+			}
+		case reflect.Slice:
+			list, err := parseStringSlice(value)
+			if err == nil {
+				items := make([]interface{}, len(list))
+				for i, item := range list {
+					items[i] = item
+				}
+				values[key] = items
+			}
+		case reflect.Map:
+			entries, err := parseStringMap(value)
+			if err == nil {
+				items := make(map[string]interface{}, len(entries))
+				for entryKey, item := range entries {
+					items[entryKey] = item
+				}
+				values[key] = items
+			}
+		}
+	}
+	return values, positions, nil
+}
+
+////////////////////////////////
+// Unknown keys and type errors
+////////////////////////////////
+
+// configFieldsByKey returns the config fields by their YAML key.
+//
+// Returns:
+//   - map[string]reflect.StructField: The fields.
+func configFieldsByKey() map[string]reflect.StructField {
+	configType := reflect.TypeOf(Config{})
+	fields := make(map[string]reflect.StructField, configType.NumField())
+	for i := 0; i < configType.NumField(); i++ {
+		if isDeprecatedField(configType.Field(i)) {
+			continue
+		}
+		fields[yamlKey(configType.Field(i))] = configType.Field(i)
+	}
+	return fields
+}
+
+// checkUnknownKeys records every top level key of a config file that is not a config field. They are reported
+// as warnings after interpolation, unless they are referenced as variables (see interpolate).
+//
+// Parameters:
+//   - fileName: The name of the file.
+//   - values: The top level values of the file.
+//   - positions: The position of each key in the file.
+func (layered *configFiles) checkUnknownKeys(fileName string, values map[string]interface{}, positions map[string]keyPosition) {
+	fields := configFieldsByKey()
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		field := strings.TrimSuffix(key, appendSuffix)
+		if key == includeKey {
+			continue
+		}
+		if _, ok := fields[field]; ok {
+			continue
+		}
+		message := fmt.Sprintf("unknown config key '%v' is ignored", key)
+		suggestion := closestConfigKey(field, fields)
+		if suggestion != "" {
+			message += fmt.Sprintf(", did you mean '%v'?", suggestion)
+		}
+		position := positions[key]
+		warning := &ParseError{File: fileName, Line: position.line, Column: position.column, Message: message}
+		layered.unknownKeys = append(layered.unknownKeys, unknownKey{key: key, warning: warning.Error()})
+	}
+}
+
+// closestConfigKey returns the config key most similar to a misspelled key.
+//
+// Parameters:
+//   - key: The misspelled key.
+//   - fields: The config fields by YAML key.
+//
+// Returns:
+//   - string: The config key with an edit distance of at most 2 (ignoring case), or an empty string if there is none.
+func closestConfigKey(key string, fields map[string]reflect.StructField) string {
+	best := ""
+	bestDistance := 3
+	for candidate := range fields {
+		distance := editDistance(strings.ToUpper(key), candidate)
+		if distance < bestDistance || (distance == bestDistance && candidate < best) {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+//
+// Parameters:
+//   - a: The first string.
+//   - b: The second string.
+//
+// Returns:
+//   - int: The number of single character insertions, deletions and substitutions turning a into b.
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// typeError returns the error for a merged config value that does not match the type of its field.
+//
+// Parameters:
+//   - key: The YAML key of the field.
+//   - fieldType: The type of the field.
+//
+// Returns:
+//   - *ParseError: The error at the position of the key in the file that set it.
+func (layered configFiles) typeError(key string, fieldType reflect.Type) *ParseError {
+	position := layered.positions[key]
+	return &ParseError{
+		File:    layered.origins[key],
+		Line:    position.line,
+		Column:  position.column,
+		Message: fmt.Sprintf("value of '%v' must be of type %v, got %v", key, fieldType, yamlTypeName(layered.values[key])),
+	}
+}
+
+// yamlTypeName returns a short description of the type of a parsed config value.
+//
+// Parameters:
+//   - value: The parsed value.
+//
+// Returns:
+//   - string: The type description, e.g. "string" or "list".
+func yamlTypeName(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int64, uint64:
+		return "integer"
+	case float64:
+		return "number"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "map"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// logWarnings logs the warnings found while reading the config files.
+func (layered configFiles) logWarnings() {
+	for _, warning := range layered.warnings {
+		log.Printf("Warning: %v", warning)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestConfigFormats tests that YAML, JSON, TOML and .env config files are read into the same config
+func TestConfigFormats(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": "SERVICE_NAME: agent\nQDRANT_PORT: 6333\nUSE_SSL: true\n" +
+			"PRIVATE_WORKFLOWS_FOLDERS: [a, b]\nWORKFLOW_CONFIG_VARIABLES:\n  region: eu\n",
+		"config.json": `{"SERVICE_NAME": "agent", "QDRANT_PORT": 6333, "USE_SSL": true,` +
+			` "PRIVATE_WORKFLOWS_FOLDERS": ["a", "b"], "WORKFLOW_CONFIG_VARIABLES": {"region": "eu"}}`,
+		"config.toml": "SERVICE_NAME = \"agent\"\nQDRANT_PORT = 6333\nUSE_SSL = true\n" +
+			"PRIVATE_WORKFLOWS_FOLDERS = [\"a\", \"b\"]\n\n[WORKFLOW_CONFIG_VARIABLES]\nregion = \"eu\"\n",
+		"config.env": "SERVICE_NAME=agent\nQDRANT_PORT=6333\nUSE_SSL=true\n" +
+			"PRIVATE_WORKFLOWS_FOLDERS=a,b\nWORKFLOW_CONFIG_VARIABLES=region=eu\n",
+	})
+
+	expectedConfig := Config{
+		SERVICE_NAME:              "agent",
+		QDRANT_PORT:               6333,
+		USE_SSL:                   true,
+		PRIVATE_WORKFLOWS_FOLDERS: []string{"a", "b"},
+		WORKFLOW_CONFIG_VARIABLES: map[string]string{"region": "eu"},
+	}
+	for _, name := range []string{"config.yaml", "config.json", "config.toml", "config.env"} {
+		fileName := filepath.Join(dir, name)
+		layered, err := readConfigFiles(fileName, nil, "", nil)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", name, err)
+		}
+		config, err := layered.decode(fileName)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", name, err)
+		}
+		if !reflect.DeepEqual(config, expectedConfig) {
+			t.Errorf("%v: expected config %+v, got %+v", name, expectedConfig, config)
+		}
+	}
+}
+
+// TestConfigFileErrors tests the positions of parse errors, type errors and unknown key warnings
+func TestConfigFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"syntax.yaml":  "SERVICE_NAME: agent\nLOG_LEVEL: [info\n",
+		"syntax.json":  "{\n  \"SERVICE_NAME\": \"agent\",\n  \"LOG_LEVEL\" \"info\"\n}",
+		"syntax.toml":  "SERVICE_NAME = \"agent\"\nLOG_LEVEL = \n",
+		"syntax.env":   "SERVICE_NAME=agent\nLOG_LEVEL\n",
+		"type.yaml":    "SERVICE_NAME: agent\n  # comment\nQDRANT_PORT: not-a-number\n",
+		"type.json":    "{\"SERVICE_NAME\": \"agent\",\n \"USE_SSL\": \"maybe\"}",
+		"unknown.yaml": "SERVICE_NAME: agent\nLOG_LEVLE: debug\nCOMPLETELY_UNKNOWN: 1\n",
+		"unknown.toml": "SERVICE_NAME = \"agent\"\n\n[WORKFLOW_CONFIG_VARIABLE]\nregion = \"eu\"\n",
+	})
+
+	tests := []struct {
+		name   string
+		line   int
+		column int
+	}{
+		{"syntax.yaml", 2, 0},
+		{"syntax.json", 3, 15},
+		{"syntax.toml", 2, 13},
+		{"syntax.env", 2, 0},
+	}
+	for _, tt := range tests {
+		_, err := readConfigFiles(filepath.Join(dir, tt.name), nil, "", nil)
+		var parseError *ParseError
+		if !errors.As(err, &parseError) {
+			t.Errorf("%v: expected parse error, got %v", tt.name, err)
+			continue
+		}
+		if parseError.File != filepath.Join(dir, tt.name) || parseError.Line != tt.line || parseError.Column != tt.column || strings.Contains(parseError.Message, "line") {
+			t.Errorf("%v: expected error at line %v, column %v, got %v", tt.name, tt.line, tt.column, parseError)
+		}
+	}
+
+	// Type errors point to the key
+	for name, expected := range map[string]ParseError{
+		"type.yaml": {Line: 3, Column: 1, Message: "value of 'QDRANT_PORT' must be of type int, got string"},
+		"type.json": {Line: 2, Column: 2, Message: "value of 'USE_SSL' must be of type bool, got string"},
+	} {
+		fileName := filepath.Join(dir, name)
+		layered, err := readConfigFiles(fileName, nil, "", nil)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", name, err)
+		}
+		_, err = layered.decode(fileName)
+		var parseError *ParseError
+		expected.File = fileName
+		if !errors.As(err, &parseError) || *parseError != expected {
+			t.Errorf("%v: expected %v, got %v", name, &expected, err)
+		}
+	}
+
+	// Unknown keys are reported with a suggestion
+	layered, err := readConfigFiles(filepath.Join(dir, "unknown.yaml"), nil, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedWarnings := []string{
+		filepath.Join(dir, "unknown.yaml") + ": line 3, column 1: unknown config key 'COMPLETELY_UNKNOWN' is ignored",
+		filepath.Join(dir, "unknown.yaml") + ": line 2, column 1: unknown config key 'LOG_LEVLE' is ignored, did you mean 'LOG_LEVEL'?",
+	}
+	if !reflect.DeepEqual(layered.warnings, expectedWarnings) {
+		t.Errorf("Expected warnings %q, got %q", expectedWarnings, layered.warnings)
+	}
+	layered, err = readConfigFiles(filepath.Join(dir, "unknown.toml"), nil, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedWarnings = []string{
+		filepath.Join(dir, "unknown.toml") + ": line 3, column 2: unknown config key 'WORKFLOW_CONFIG_VARIABLE' is ignored, did you mean 'WORKFLOW_CONFIG_VARIABLES'?",
+	}
+	if !reflect.DeepEqual(layered.warnings, expectedWarnings) {
+		t.Errorf("Expected warnings %q, got %q", expectedWarnings, layered.warnings)
+	}
+}
@@ -0,0 +1,336 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+////////////////////////////////
+// Variable interpolation
+////////////////////////////////
+
+// interpolate replaces the references in all string values of the merged config files.
+//
+// The following references are supported:
+//   - ${NAME}: the config value with key NAME if NAME is a config field or a key in the config files,
+//     otherwise the environment variable NAME,
+//   - ${env:NAME}: the environment variable NAME,
+//   - ${config:NAME}: the config value with key NAME; keys without config field (e.g. FLOWKIT_HOST) can be
+//     defined in the config files to be referenced,
+//   - ${file:/path}: the content of a file without trailing newline,
+//   - ${REFERENCE:-default}: default if the referenced value is not set or empty.
+//
+// References to config fields use the merged file values before environment variables and command line
+// arguments are applied, and may contain references themselves. "$${" is replaced by a literal "${".
+// Strings with references in integer and boolean fields are converted after interpolation.
+// Unresolved references and reference cycles are recorded as violations and the value is removed.
+// Unknown keys that are not referenced are recorded as warnings.
+func (layered *configFiles) interpolate() {
+	configType := reflect.TypeOf(Config{})
+	interpolation := &interpolation{
+		values:   layered.values,
+		fields:   map[string]reflect.Type{},
+		resolved: map[string]string{},
+		used:     map[string]bool{},
+	}
+	names := map[string]string{}
+	for i := 0; i < configType.NumField(); i++ {
+		if isDeprecatedField(configType.Field(i)) {
+			continue
+		}
+		interpolation.fields[yamlKey(configType.Field(i))] = configType.Field(i).Type
+		names[yamlKey(configType.Field(i))] = configType.Field(i).Name
+	}
+
+	keys := make([]string, 0, len(layered.values))
+	for key := range layered.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	expanded := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		name, ok := names[key]
+		if !ok {
+			name = key
+		}
+		interpolation.stack = []string{key}
+		value, err := interpolation.expandValue(layered.values[key])
+		original, isString := layered.values[key].(string)
+		if err == nil && isString && strings.Contains(original, "${") {
+			value, err = convertInterpolated(value, interpolation.fields[key])
+		}
+		if err != nil {
+			layered.violations = append(layered.violations, Violation{
+				Field:   name,
+				Rule:    "reference",
+				Message: fmt.Sprintf("config property '%v' %v", name, err),
+			})
+			continue
+		}
+		expanded[key] = value
+	}
+	layered.values = expanded
+
+	for _, unknown := range layered.unknownKeys {
+		if !interpolation.used[unknown.key] {
+			layered.warnings = append(layered.warnings, unknown.warning)
+		}
+	}
+}
+
+// expandValue replaces the references in a string value or in the strings of a list or map.
+//
+// Parameters:
+//   - value: The value.
+//
+// Returns:
+//   - interface{}: The value with all references replaced.
+//   - error: An error describing the first reference that could not be resolved.
+func (interpolation *interpolation) expandValue(value interface{}) (interface{}, error) {
+	switch typed := value.(type) {
+	case string:
+		return interpolation.expand(typed)
+	case []interface{}:
+		expanded := make([]interface{}, len(typed))
+		for i, item := range typed {
+			var err error
+			expanded[i], err = interpolation.expandValue(item)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return expanded, nil
+	case map[string]interface{}:
+		expanded := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			var err error
+			expanded[key], err = interpolation.expandValue(item)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return expanded, nil
+	default:
+		return value, nil
+	}
+}
+
+// expand replaces the references in a string.
+//
+// Parameters:
+//   - value: The string.
+//
+// Returns:
+//   - string: The string with all references replaced.
+//   - error: An error describing the first reference that could not be resolved.
+func (interpolation *interpolation) expand(value string) (string, error) {
+	var builder strings.Builder
+	remaining := value
+	for {
+		start := strings.Index(remaining, "${")
+		if start < 0 {
+			builder.WriteString(remaining)
+			return builder.String(), nil
+		}
+
+		// "$${" is an escaped "${"
+		if start > 0 && remaining[start-1] == '$' {
+			builder.WriteString(remaining[:start-1])
+			builder.WriteString("${")
+			remaining = remaining[start+2:]
+			continue
+		}
+
+		end := strings.Index(remaining[start:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("contains an unterminated reference in %q", value)
+		}
+		builder.WriteString(remaining[:start])
+		resolved, err := interpolation.resolve(remaining[start+2 : start+end])
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(resolved)
+		remaining = remaining[start+end+1:]
+	}
+}
+
+// resolve returns the value of a single reference.
+//
+// Parameters:
+//   - reference: The reference without "${" and "}".
+//
+// Returns:
+//   - string: The referenced value.
+//   - error: An error if the reference is not set and has no default, or is part of a cycle.
+func (interpolation *interpolation) resolve(reference string) (string, error) {
+	expression, fallback, hasFallback := strings.Cut(reference, ":-")
+	kind, name, hasKind := strings.Cut(expression, ":")
+	if !hasKind {
+		name = expression
+		kind = "env"
+		_, isField := interpolation.fields[strings.TrimSpace(name)]
+		_, isValue := interpolation.values[strings.TrimSpace(name)]
+		if isField || isValue {
+			kind = "config"
+		}
+	}
+	name = strings.TrimSpace(name)
+
+	var value, description string
+	var found bool
+	switch kind {
+	case "env":
+		value, found = os.LookupEnv(name)
+		description = fmt.Sprintf("environment variable '%v'", name)
+	case "file":
+		data, err := os.ReadFile(name)
+		found = err == nil
+		value = strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+		description = fmt.Sprintf("file '%v'", name)
+	case "config":
+		var err error
+		value, found, err = interpolation.field(name)
+		if err != nil {
+			return "", err
+		}
+		description = fmt.Sprintf("config property '%v'", name)
+	default:
+		return "", fmt.Errorf("contains the unknown reference type '%v' in '${%v}'", kind, reference)
+	}
+
+	if hasFallback && (!found || value == "") {
+		return fallback, nil
+	}
+	if !found {
+		return "", fmt.Errorf("references undefined %v", description)
+	}
+	return value, nil
+}
+
+// field returns the interpolated value of a config field referenced by another field.
+//
+// Parameters:
+//   - key: The YAML key of the referenced field.
+//
+// Returns:
+//   - string: The interpolated value.
+//   - bool: True if the field is set in the config files.
+//   - error: An error if the field is a list or map, is part of a reference cycle or contains unresolved references.
+func (interpolation *interpolation) field(key string) (string, bool, error) {
+	interpolation.used[key] = true
+	resolved, ok := interpolation.resolved[key]
+	if ok {
+		return resolved, true, nil
+	}
+	for i, resolving := range interpolation.stack {
+		if resolving == key {
+			cycle := append(append([]string{}, interpolation.stack[i:]...), key)
+			return "", false, fmt.Errorf("contains a reference cycle: %v", strings.Join(cycle, " -> "))
+		}
+	}
+
+	var value string
+	switch raw := interpolation.values[key].(type) {
+	case nil:
+		return "", false, nil
+	case string:
+		interpolation.stack = append(interpolation.stack, key)
+		expanded, err := interpolation.expand(raw)
+		interpolation.stack = interpolation.stack[:len(interpolation.stack)-1]
+		if err != nil {
+			return "", false, err
+		}
+		value = expanded
+	case []interface{}, map[string]interface{}:
+		return "", false, fmt.Errorf("references the list or map config property '%v'", key)
+	default:
+		value = fmt.Sprint(raw)
+	}
+	interpolation.resolved[key] = value
+	return value, true, nil
+}
+
+// convertInterpolated converts an interpolated string to the type of an integer or boolean config field.
+//
+// Parameters:
+//   - value: The interpolated value.
+//   - fieldType: The type of the config field; nil for keys without field.
+//
+// Returns:
+//   - interface{}: The converted value, or value if no conversion is needed.
+//   - error: An error if the string cannot be converted.
+func convertInterpolated(value interface{}, fieldType reflect.Type) (interface{}, error) {
+	text, ok := value.(string)
+	if !ok || fieldType == nil {
+		return value, nil
+	}
+	switch fieldType.Kind() {
+	case reflect.Int:
+		converted, err := strconv.Atoi(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("must be an integer, got %q", text)
+		}
+		return converted, nil
+	case reflect.Bool:
+		converted, err := strconv.ParseBool(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("must be a boolean, got %q", text)
+		}
+		return converted, nil
+	default:
+		return value, nil
+	}
+}
+
+// withViolations adds violations to the result of a validation.
+//
+// Parameters:
+//   - err: The result of the validation; nil or a *ValidationError.
+//   - violations: The violations to add.
+//
+// Returns:
+//   - error: A *ValidationError containing the violations, or err if there are none.
+func withViolations(err error, violations []Violation) error {
+	if len(violations) == 0 {
+		return err
+	}
+	if err == nil {
+		return &ValidationError{Violations: violations}
+	}
+	validationError, ok := err.(*ValidationError)
+	if !ok {
+		return err
+	}
+	return &ValidationError{
+		Service:    validationError.Service,
+		Violations: append(append([]Violation{}, violations...), validationError.Violations...),
+	}
+}
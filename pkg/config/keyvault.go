@@ -0,0 +1,457 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+)
+
+const (
+	// defaultKeyVaultTimeout limits the time spent reading secrets from a key vault.
+	defaultKeyVaultTimeout = 30 * time.Second
+	// defaultKeyVaultConcurrency is the number of secrets fetched in parallel.
+	defaultKeyVaultConcurrency = 8
+)
+
+// keyVaultCache holds the secrets fetched per cache key, guarded by keyVaultCacheMutex.
+var (
+	keyVaultCache      = map[string]keyVaultCacheEntry{}
+	keyVaultCacheMutex sync.Mutex
+)
+
+/////////////////////////////////////////////////
+// Extract Config variables from Azure Key Vault
+/////////////////////////////////////////////////
+
+// InitGlobalConfigFromAzureKeyVault extracts the configuration from Azure Key Vault.
+// Secrets are matched to config fields as described in LoadFromKeyVault, using the
+// AZURE_KEY_VAULT_* settings of the global config.
+//
+// Returns:
+//   - err: An error if there was an issue extracting the configuration.
+func InitGlobalConfigFromAzureKeyVault() (err error) {
+	return loadFromAzureKeyVault(GlobalConfig, nil, nil)
+}
+
+// loadFromAzureKeyVault extracts the configuration from Azure Key Vault into the given config.
+//
+// Parameters:
+//   - config: The configuration object to update.
+//   - provenance: Records the fields set from a secret; may be nil.
+//   - client: The client to read the secrets with; if nil, an Azure client is created from the config when needed.
+//
+// Returns:
+//   - err: An error if there was an issue extracting the configuration.
+func loadFromAzureKeyVault(config *Config, provenance Provenance, client KeyVaultClient) (err error) {
+	// log
+	log.Println("Extracting configuration from Azure Key Vault...")
+
+	options := keyVaultOptionsFromConfig(*config)
+	newClient := func() (KeyVaultClient, error) {
+		if client != nil {
+			return client, nil
+		}
+		return NewAzureKeyVaultClient(os.Getenv(config.AZURE_KEY_VAULT_NAME), os.Getenv(config.AZURE_MANAGED_IDENTITY_ID))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), options.Timeout)
+	defer cancel()
+	return loadFromKeyVault(ctx, config, newClient, options, provenance)
+}
+
+// keyVaultOptionsFromConfig creates the key vault options from the AZURE_KEY_VAULT_* settings of a config.
+//
+// Parameters:
+//   - config: The configuration object.
+//
+// Returns:
+//   - KeyVaultOptions: The options, with defaults for unset values.
+func keyVaultOptionsFromConfig(config Config) KeyVaultOptions {
+	options := KeyVaultOptions{
+		SecretNames: config.AZURE_KEY_VAULT_SECRET_NAMES,
+		Timeout:     time.Duration(config.AZURE_KEY_VAULT_TIMEOUT_SECONDS) * time.Second,
+		Concurrency: config.AZURE_KEY_VAULT_CONCURRENCY,
+		CacheKey:    os.Getenv(config.AZURE_KEY_VAULT_NAME),
+		CacheTTL:    time.Duration(config.AZURE_KEY_VAULT_CACHE_TTL) * time.Second,
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = defaultKeyVaultTimeout
+	}
+	return options
+}
+
+////////////////////////////////
+// Key Vault loading
+////////////////////////////////
+
+// LoadFromKeyVault reads the secrets of a key vault and sets the matching config fields.
+// Secrets are matched by the explicit mapping in options.SecretNames first; other secrets match the
+// config key with '-' instead of '_' (e.g. llm-api-key sets LLM_API_KEY) or the JSON tag of a field
+// (e.g. LLMAPIKEY), both case-insensitively; deprecated aliases of both match with a warning.
+// Matching secrets are fetched concurrently.
+//
+// Parameters:
+//   - ctx: The context for the secret retrieval; options.Timeout is applied on top of it.
+//   - config: The configuration object to update.
+//   - client: The key vault client.
+//   - options: The name mapping, timeout, concurrency and cache settings.
+//
+// Returns:
+//   - err: An error joining all retrieval and conversion errors.
+func LoadFromKeyVault(ctx context.Context, config *Config, client KeyVaultClient, options KeyVaultOptions) (err error) {
+	if options.Timeout <= 0 {
+		options.Timeout = defaultKeyVaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, options.Timeout)
+	defer cancel()
+	return loadFromKeyVault(ctx, config, func() (KeyVaultClient, error) { return client, nil }, options, nil)
+}
+
+// ClearKeyVaultCache removes all cached key vault secrets, so the next load fetches them again.
+func ClearKeyVaultCache() {
+	keyVaultCacheMutex.Lock()
+	defer keyVaultCacheMutex.Unlock()
+	keyVaultCache = map[string]keyVaultCacheEntry{}
+}
+
+// loadFromKeyVault reads the secrets of a key vault, or takes them from the cache, and sets the matching config fields.
+//
+// Parameters:
+//   - ctx: The context for the secret retrieval.
+//   - config: The configuration object to update.
+//   - newClient: Returns the key vault client; only called if the secrets are not cached.
+//   - options: The name mapping, concurrency and cache settings.
+//   - provenance: Records the fields set from a secret; may be nil.
+//
+// Returns:
+//   - err: An error joining all retrieval and conversion errors.
+func loadFromKeyVault(ctx context.Context, config *Config, newClient func() (KeyVaultClient, error), options KeyVaultOptions, provenance Provenance) (err error) {
+	configValue := reflect.ValueOf(config).Elem()
+	for secretName, key := range options.SecretNames {
+		_, _, ok := fieldBySecretKey(configValue, key)
+		if !ok {
+			return fmt.Errorf("key vault secret '%v' is mapped to unknown config key '%v'", secretName, key)
+		}
+	}
+
+	secrets, ok := cachedKeyVaultSecrets(options)
+	if !ok {
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+		secrets, err = fetchKeyVaultSecrets(ctx, client, configValue, options)
+		if err != nil {
+			return err
+		}
+		storeKeyVaultSecrets(options, secrets)
+	}
+
+	// Apply in a fixed order, so explicitly mapped secrets win over secrets matched by name
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		_, iMapped := options.SecretNames[names[i]]
+		_, jMapped := options.SecretNames[names[j]]
+		if iMapped != jMapped {
+			return jMapped
+		}
+		return names[i] < names[j]
+	})
+
+	var errs []error
+	for _, name := range names {
+		field, fieldName, ok := keyVaultField(configValue, name, options)
+		if !ok {
+			continue
+		}
+		if _, mapped := options.SecretNames[name]; !mapped {
+			warnDeprecatedSecretKey("key vault", name, fieldName)
+		}
+		value := secrets[name]
+		if field.Kind() == reflect.String {
+			unquoted, err := strconv.Unquote(`"` + strings.ReplaceAll(value, `"`, `\"`) + `"`)
+			if err == nil {
+				value = unquoted
+			}
+		}
+		err := setFieldFromString(field, value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid value for key vault secret '%v': %w", name, err))
+			continue
+		}
+		provenance.record(fieldName, SourceAzureKeyVault, name)
+	}
+
+	return errors.Join(errs...)
+}
+
+// fetchKeyVaultSecrets lists the secrets of a key vault and fetches those matching a config field concurrently.
+//
+// Parameters:
+//   - ctx: The context for the secret retrieval.
+//   - client: The key vault client.
+//   - configValue: The reflected config struct.
+//   - options: The name mapping and concurrency.
+//
+// Returns:
+//   - map[string]string: The values of the matching secrets by secret name.
+//   - error: An error joining all retrieval errors.
+func fetchKeyVaultSecrets(ctx context.Context, client KeyVaultClient, configValue reflect.Value, options KeyVaultOptions) (map[string]string, error) {
+	names, err := client.ListSecretNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error in listing key vault secrets: %w", err)
+	}
+	matching := []string{}
+	for _, name := range names {
+		_, _, ok := keyVaultField(configValue, name, options)
+		if ok {
+			matching = append(matching, name)
+		}
+	}
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultKeyVaultConcurrency
+	}
+
+	var (
+		mutex   sync.Mutex
+		wg      sync.WaitGroup
+		errs    []error
+		secrets = make(map[string]string, len(matching))
+		work    = make(chan string)
+	)
+	for worker := 0; worker < concurrency && worker < len(matching); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range work {
+				value, err := client.GetSecret(ctx, name)
+				mutex.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("error in retrieving key vault secret '%v': %w", name, err))
+				} else {
+					secrets[name] = value
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	for _, name := range matching {
+		work <- name
+	}
+	close(work)
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return secrets, nil
+}
+
+// keyVaultField returns the config field a key vault secret is applied to.
+//
+// Parameters:
+//   - configValue: The reflected config struct.
+//   - name: The secret name.
+//   - options: The explicit name mapping.
+//
+// Returns:
+//   - reflect.Value: The matching field.
+//   - string: The name of the matching field.
+//   - bool: True if a field matches.
+func keyVaultField(configValue reflect.Value, name string, options KeyVaultOptions) (reflect.Value, string, bool) {
+	key, mapped := options.SecretNames[name]
+	if mapped {
+		return fieldBySecretKey(configValue, key)
+	}
+	field, fieldName, ok := fieldBySecretKey(configValue, name)
+	if ok {
+		return field, fieldName, true
+	}
+	configType := configValue.Type()
+	for i := 0; i < configType.NumField(); i++ {
+		if isDeprecatedField(configType.Field(i)) {
+			continue
+		}
+		if strings.EqualFold(configType.Field(i).Tag.Get("json"), name) {
+			return configValue.Field(i), configType.Field(i).Name, true
+		}
+		for _, alias := range fieldAliases(configType.Field(i)) {
+			if strings.EqualFold(jsonAlias(alias), name) {
+				return configValue.Field(i), configType.Field(i).Name, true
+			}
+		}
+	}
+	return reflect.Value{}, "", false
+}
+
+// cachedKeyVaultSecrets returns the cached secrets of a key vault if they are younger than the cache TTL.
+//
+// Parameters:
+//   - options: The cache key and TTL.
+//
+// Returns:
+//   - map[string]string: The cached secrets.
+//   - bool: True if the cache contains valid secrets.
+func cachedKeyVaultSecrets(options KeyVaultOptions) (map[string]string, bool) {
+	if options.CacheTTL <= 0 || options.CacheKey == "" {
+		return nil, false
+	}
+	keyVaultCacheMutex.Lock()
+	defer keyVaultCacheMutex.Unlock()
+	entry, ok := keyVaultCache[options.CacheKey]
+	if !ok || time.Since(entry.fetched) > options.CacheTTL {
+		return nil, false
+	}
+	return entry.secrets, true
+}
+
+// storeKeyVaultSecrets caches the secrets of a key vault if caching is enabled.
+//
+// Parameters:
+//   - options: The cache key and TTL.
+//   - secrets: The fetched secrets.
+func storeKeyVaultSecrets(options KeyVaultOptions, secrets map[string]string) {
+	if options.CacheTTL <= 0 || options.CacheKey == "" {
+		return
+	}
+	keyVaultCacheMutex.Lock()
+	defer keyVaultCacheMutex.Unlock()
+	keyVaultCache[options.CacheKey] = keyVaultCacheEntry{secrets: secrets, fetched: time.Now()}
+}
+
+////////////////////////////////
+// Azure Key Vault client
+////////////////////////////////
+
+// azureKeyVaultClient reads secrets from Azure Key Vault with the Azure SDK.
+type azureKeyVaultClient struct {
+	client *azsecrets.Client
+}
+
+// NewAzureKeyVaultClient creates a client for an Azure Key Vault authenticated with a managed identity
+// (only works inside Azure Services).
+//
+// Parameters:
+//   - vaultName: The name of the key vault.
+//   - managedIdentityID: The client ID of the managed identity.
+//
+// Returns:
+//   - KeyVaultClient: The client.
+//   - error: An error if a parameter is empty or the managed identity cannot get a token.
+func NewAzureKeyVaultClient(vaultName string, managedIdentityID string) (KeyVaultClient, error) {
+	if managedIdentityID == "" {
+		return nil, errors.New("managed identity ID for Azure Key Vault is not set")
+	}
+	if vaultName == "" {
+		return nil, errors.New("Azure Key Vault name is not set")
+	}
+
+	// create Managed Identity credential
+	cred, err := azidentity.NewManagedIdentityCredential(&azidentity.ManagedIdentityCredentialOptions{
+		ID: azidentity.ClientID(managedIdentityID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Managed Identity credential: %w", err)
+	}
+
+	// Test the managed id by getting a token
+	ctx, cancel := context.WithTimeout(context.Background(), defaultKeyVaultTimeout)
+	defer cancel()
+	_, err = cred.GetToken(ctx, policy.TokenRequestOptions{
+		Scopes: []string{"https://vault.azure.net/.default"}, // Scope for Azure Key Vault
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token from managed ID: %w", err)
+	}
+
+	client, err := azsecrets.NewClient(fmt.Sprintf("https://%s.vault.azure.net/", vaultName), cred, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &azureKeyVaultClient{client: client}, nil
+}
+
+// ListSecretNames returns the names of all enabled secrets in the key vault.
+//
+// Parameters:
+//   - ctx: The context for the requests.
+//
+// Returns:
+//   - []string: The secret names.
+//   - error: An error if a page of secrets cannot be retrieved.
+func (keyVault *azureKeyVaultClient) ListSecretNames(ctx context.Context) ([]string, error) {
+	names := []string{}
+	pager := keyVault.client.NewListSecretPropertiesPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, secret := range page.Value {
+			if secret.ID == nil || (secret.Attributes != nil && secret.Attributes.Enabled != nil && !*secret.Attributes.Enabled) {
+				continue
+			}
+			names = append(names, secret.ID.Name())
+		}
+	}
+	return names, nil
+}
+
+// GetSecret returns the current value of a secret in the key vault.
+//
+// Parameters:
+//   - ctx: The context for the request.
+//   - name: The secret name.
+//
+// Returns:
+//   - string: The secret value.
+//   - error: An error if the secret cannot be retrieved.
+func (keyVault *azureKeyVaultClient) GetSecret(ctx context.Context, name string) (string, error) {
+	resp, err := keyVault.client.GetSecret(ctx, name, "", nil)
+	if err != nil {
+		return "", err
+	}
+	if resp.Value == nil {
+		return "", nil
+	}
+	return *resp.Value, nil
+}
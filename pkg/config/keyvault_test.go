@@ -0,0 +1,174 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeKeyVault is an in-process KeyVaultClient that counts requests and tracks their concurrency.
+type fakeKeyVault struct {
+	secrets       map[string]string
+	delay         time.Duration
+	mutex         sync.Mutex
+	gets          int
+	running       int
+	maxConcurrent int
+}
+
+func (vault *fakeKeyVault) ListSecretNames(ctx context.Context) ([]string, error) {
+	names := []string{}
+	for name := range vault.secrets {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (vault *fakeKeyVault) GetSecret(ctx context.Context, name string) (string, error) {
+	vault.mutex.Lock()
+	vault.gets++
+	vault.running++
+	if vault.running > vault.maxConcurrent {
+		vault.maxConcurrent = vault.running
+	}
+	vault.mutex.Unlock()
+	defer func() {
+		vault.mutex.Lock()
+		vault.running--
+		vault.mutex.Unlock()
+	}()
+
+	select {
+	case <-time.After(vault.delay):
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	value, ok := vault.secrets[name]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+	return value, nil
+}
+
+// TestLoadFromKeyVault tests name mapping, concurrency and timeouts of the key vault loading
+func TestLoadFromKeyVault(t *testing.T) {
+	vault := &fakeKeyVault{
+		delay: 20 * time.Millisecond,
+		secrets: map[string]string{
+			"llm-api-key":     "llm-key",
+			"FLOWKITAPIKEY":   "flowkit-key",
+			"qdrant-port":     "6333",
+			"crypt":           "crypt-key",
+			"folders":         `["a", "b"]`,
+			"github-token":    "unmapped-token",
+			"gh":              "mapped-token",
+			"unrelated-value": "ignored",
+		},
+	}
+	options := KeyVaultOptions{
+		SecretNames: map[string]string{
+			"crypt":   "ANSYS_AUTHORIZATION_CRYPT_KEY",
+			"folders": "PRIVATE_WORKFLOWS_FOLDERS",
+			"gh":      "GITHUB_TOKEN",
+		},
+		Concurrency: 3,
+	}
+
+	config := Config{}
+	err := LoadFromKeyVault(context.Background(), &config, vault, options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedConfig := Config{
+		LLM_API_KEY:                   "llm-key",
+		FLOWKIT_API_KEY:               "flowkit-key",
+		QDRANT_PORT:                   6333,
+		ANSYS_AUTHORIZATION_CRYPT_KEY: "crypt-key",
+		PRIVATE_WORKFLOWS_FOLDERS:     []string{"a", "b"},
+		GITHUB_TOKEN:                  "mapped-token",
+	}
+	if !reflect.DeepEqual(config, expectedConfig) {
+		t.Errorf("Expected config %+v, got %+v", expectedConfig, config)
+	}
+	if vault.gets != 7 || vault.maxConcurrent < 2 || vault.maxConcurrent > 3 {
+		t.Errorf("Expected 7 concurrent requests with at most 3 in parallel, got %v with %v in parallel", vault.gets, vault.maxConcurrent)
+	}
+
+	// Timeout
+	options.Timeout = 5 * time.Millisecond
+	err = LoadFromKeyVault(context.Background(), &Config{}, vault, options)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+
+	// Mapping to an unknown config key
+	err = LoadFromKeyVault(context.Background(), &Config{}, vault, KeyVaultOptions{SecretNames: map[string]string{"gh": "UNKNOWN"}})
+	if err == nil {
+		t.Errorf("Expected error for mapping to an unknown config key")
+	}
+}
+
+// TestLoadFromKeyVaultCache tests that Load reuses cached key vault secrets within the TTL
+func TestLoadFromKeyVaultCache(t *testing.T) {
+	defer ClearKeyVaultCache()
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": "EXTRACT_CONFIG_FROM_AZURE_KEY_VAULT: true\nAZURE_KEY_VAULT_NAME: TEST_KEY_VAULT_NAME\n" +
+			"AZURE_MANAGED_IDENTITY_ID: TEST_MANAGED_IDENTITY_ID\nAZURE_KEY_VAULT_CACHE_TTL: 60\n" +
+			"AZURE_KEY_VAULT_SECRET_NAMES:\n  flowkit: FLOWKIT_API_KEY\n",
+	})
+	t.Setenv("TEST_KEY_VAULT_NAME", "test-vault")
+	vault := &fakeKeyVault{secrets: map[string]string{"flowkit": "flowkit-key"}}
+	options := LoadOptions{FilePath: filepath.Join(dir, "config.yaml"), IgnoreEnvironment: true, KeyVaultClient: vault}
+
+	for i := 0; i < 2; i++ {
+		config, provenance, err := LoadWithProvenance(options)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if config.FLOWKIT_API_KEY != "flowkit-key" {
+			t.Errorf("Expected value from key vault, got %q", config.FLOWKIT_API_KEY)
+		}
+		if origin := provenance.Origin("FLOWKIT_API_KEY"); origin.Source != SourceAzureKeyVault || origin.Location != "flowkit" {
+			t.Errorf("Expected key vault origin, got %+v", origin)
+		}
+	}
+	if vault.gets != 1 {
+		t.Errorf("Expected secrets to be fetched once, got %v requests", vault.gets)
+	}
+
+	ClearKeyVaultCache()
+	_, err := Load(options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if vault.gets != 2 {
+		t.Errorf("Expected secrets to be fetched again after clearing the cache, got %v requests", vault.gets)
+	}
+}
@@ -0,0 +1,379 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+////////////////////////////////
+// Layered config files
+////////////////////////////////
+
+// includeKey is the top level key of a config file listing the files it includes.
+const includeKey = "include"
+
+// appendSuffix marks a key whose list value is appended to the list of the lower layers instead of replacing it.
+const appendSuffix = "+"
+
+// readConfigFiles reads a base config file and its overlays and deep-merges them into one set of values.
+//
+// Layers are merged in the order: files included by the base file, base file, overlays. An overlay is merged
+// like a file included at the end, so it can include files itself. Included files are merged before the file
+// including them, so the including file wins. Include paths are relative to the including file.
+// Each file is parsed as JSON (.json), TOML (.toml), .env file (.env) or YAML (all other extensions);
+// deprecated key aliases are renamed to their canonical key and, like top level keys that are no config field
+// and are not referenced by another value, reported as warnings.
+//
+// Values are merged with the following semantics:
+//   - maps (e.g. WORKFLOW_CONFIG_VARIABLES) are merged key by key, recursively,
+//   - lists (e.g. PRIVATE_WORKFLOWS_FOLDERS) replace the list of the lower layers; a key with the suffix "+"
+//     (e.g. "PRIVATE_WORKFLOWS_FOLDERS+") appends to it instead,
+//   - null removes the value of the lower layers,
+//   - all other values replace the value of the lower layers.
+//
+// After merging, encrypted values are decrypted (see decrypt) and references like ${ENV_VAR} are resolved (see interpolate).
+//
+// Parameters:
+//   - fileName: The name of the base configuration file.
+//   - overlays: The overlay files; if empty, the overlay of the stage is used if it exists.
+//   - stage: The stage selecting the overlay <name>.<stage><ext> next to the base file; if empty,
+//     the STAGE value of the base file is used.
+//   - key: The key for encrypted values; if nil, it is loaded with LoadEncryptionKey when needed.
+//
+// Returns:
+//   - layered: The merged values, the file that set each key, all files that were read and unresolved references.
+//   - err: An error if a file is missing, cannot be parsed or the includes form a cycle.
+func readConfigFiles(fileName string, overlays []string, stage string, key []byte) (layered configFiles, err error) {
+	layered = configFiles{values: map[string]interface{}{}, origins: map[string]string{}, positions: map[string]keyPosition{}}
+	_, err = os.Stat(fileName)
+	if err != nil {
+		return configFiles{}, errors.New("config.yaml file is missing from directory or not accessible")
+	}
+	err = layered.mergeFile(fileName, nil)
+	if err != nil {
+		return configFiles{}, err
+	}
+
+	// Select the overlays of the stage if no explicit overlays are given
+	if len(overlays) == 0 {
+		if stage == "" {
+			stage, _ = layered.values["STAGE"].(string)
+		}
+		if stage != "" {
+			overlay := stageOverlayPath(fileName, stage)
+			_, err = os.Stat(overlay)
+			if err == nil {
+				overlays = []string{overlay}
+			}
+		}
+	}
+
+	for _, overlay := range overlays {
+		err = layered.mergeFile(overlay, nil)
+		if err != nil {
+			return configFiles{}, err
+		}
+	}
+
+	// Decrypt ENC[AES256_GCM,...] values and resolve references like ${ENV_VAR} in the merged values
+	layered.decrypt(key)
+	layered.interpolate()
+	return layered, nil
+}
+
+// stageOverlayPath returns the path of the overlay of a stage, e.g. config.production.yaml for config.yaml.
+//
+// Parameters:
+//   - fileName: The name of the base configuration file.
+//   - stage: The stage.
+//
+// Returns:
+//   - string: The path of the overlay.
+func stageOverlayPath(fileName string, stage string) string {
+	extension := filepath.Ext(fileName)
+	return strings.TrimSuffix(fileName, extension) + "." + stage + extension
+}
+
+// layerSelection returns the explicit overlays and the stage used to load the config files.
+//
+// Parameters:
+//   - options: The load options.
+//   - prefix: The prefix of the environment variables.
+//
+// Returns:
+//   - overlays: LoadOptions.OverlayFiles, or the comma separated files in AALI_CONFIG_OVERLAYS.
+//   - stage: LoadOptions.Stage, or the value of the <prefix>STAGE environment variable.
+func layerSelection(options LoadOptions, prefix string) (overlays []string, stage string) {
+	overlays = options.OverlayFiles
+	stage = options.Stage
+	if options.IgnoreEnvironment {
+		return overlays, stage
+	}
+	if len(overlays) == 0 {
+		value := strings.TrimSpace(os.Getenv("AALI_CONFIG_OVERLAYS"))
+		if value != "" {
+			overlays, _ = parseStringSlice(value)
+		}
+	}
+	if stage == "" {
+		stage = os.Getenv(prefix + "STAGE")
+	}
+	return overlays, stage
+}
+
+// mergeFile merges a config file and the files it includes into the layered values.
+//
+// Parameters:
+//   - fileName: The name of the file.
+//   - including: The chain of files including this file, used to detect cycles.
+//
+// Returns:
+//   - err: An error if the file cannot be read or parsed or the includes form a cycle.
+func (layered *configFiles) mergeFile(fileName string, including []string) (err error) {
+	for _, parent := range including {
+		if parent == fileName {
+			return fmt.Errorf("config files include each other: %v", strings.Join(append(including, fileName), " -> "))
+		}
+	}
+
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return fmt.Errorf("config file %v is missing or not accessible", fileName)
+	}
+	values, positions, err := parseConfigFile(fileName, data)
+	if err != nil {
+		return err
+	}
+	layered.files = append(layered.files, fileName)
+	layered.resolveAliases(fileName, values, positions)
+	layered.checkUnknownKeys(fileName, values, positions)
+
+	// Merge the included files first
+	includes, err := includedFiles(fileName, values[includeKey])
+	if err != nil {
+		return err
+	}
+	delete(values, includeKey)
+	for _, include := range includes {
+		err = layered.mergeFile(include, append(including, fileName))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Sorting puts "KEY" before "KEY+", so a replaced list is appended to in the same file
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := normalizeYamlValue(values[key])
+		field := strings.TrimSuffix(key, appendSuffix)
+		switch {
+		case value == nil:
+			delete(layered.values, field)
+			delete(layered.origins, field)
+			delete(layered.positions, field)
+			continue
+		case strings.HasSuffix(key, appendSuffix):
+			existing, _ := layered.values[field].([]interface{})
+			list, ok := value.([]interface{})
+			if !ok {
+				return fmt.Errorf("%v: value of '%v' must be a list", fileName, key)
+			}
+			layered.values[field] = append(append([]interface{}{}, existing...), list...)
+		default:
+			layered.values[field] = mergeValues(layered.values[field], value)
+		}
+		layered.origins[field] = fileName
+		layered.positions[field] = positions[key]
+	}
+	return nil
+}
+
+// includedFiles returns the paths of the files listed in an include directive.
+//
+// Parameters:
+//   - fileName: The name of the including file.
+//   - include: The value of the include key: a path or a list of paths.
+//
+// Returns:
+//   - []string: The paths, relative paths being resolved against the directory of fileName.
+//   - error: An error if the value is neither a string nor a list of strings.
+func includedFiles(fileName string, include interface{}) ([]string, error) {
+	var paths []string
+	switch value := include.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		paths = []string{value}
+	case []interface{}:
+		for _, item := range value {
+			path, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%v: '%v' must be a path or a list of paths", fileName, includeKey)
+			}
+			paths = append(paths, path)
+		}
+	default:
+		return nil, fmt.Errorf("%v: '%v' must be a path or a list of paths", fileName, includeKey)
+	}
+
+	for i, path := range paths {
+		if !filepath.IsAbs(path) {
+			paths[i] = filepath.Join(filepath.Dir(fileName), path)
+		}
+	}
+	return paths, nil
+}
+
+// mergeValues deep-merges an overlay value into a base value.
+// Maps are merged key by key, where a null value removes the key; all other values replace the base value.
+//
+// Parameters:
+//   - base: The value of the lower layers.
+//   - overlay: The value of the current layer.
+//
+// Returns:
+//   - interface{}: The merged value.
+func mergeValues(base interface{}, overlay interface{}) interface{} {
+	baseMap, baseIsMap := base.(map[string]interface{})
+	overlayMap, overlayIsMap := overlay.(map[string]interface{})
+	if !baseIsMap || !overlayIsMap {
+		return overlay
+	}
+
+	merged := make(map[string]interface{}, len(baseMap)+len(overlayMap))
+	for key, value := range baseMap {
+		merged[key] = value
+	}
+	for key, value := range overlayMap {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = mergeValues(merged[key], value)
+	}
+	return merged
+}
+
+// normalizeYamlValue converts the map[interface{}]interface{} values produced by yaml.v2 to map[string]interface{}.
+//
+// Parameters:
+//   - value: The parsed YAML value.
+//
+// Returns:
+//   - interface{}: The value with string keys in all maps.
+func normalizeYamlValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[interface{}]interface{}:
+		normalized := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			normalized[fmt.Sprint(key)] = normalizeYamlValue(item)
+		}
+		return normalized
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			normalized[key] = normalizeYamlValue(item)
+		}
+		return normalized
+	case []interface{}:
+		normalized := make([]interface{}, len(typed))
+		for i, item := range typed {
+			normalized[i] = normalizeYamlValue(item)
+		}
+		return normalized
+	default:
+		return value
+	}
+}
+
+// decode converts the merged values into a Config.
+//
+// Parameters:
+//   - fileName: The name of the base configuration file, used in error messages.
+//
+// Returns:
+//   - Config: The configuration.
+//   - error: A *ParseError with the file and position of each value that has the wrong type.
+func (layered configFiles) decode(fileName string) (Config, error) {
+	config := Config{}
+	data, err := yaml.Marshal(layered.values)
+	if err == nil {
+		err = yaml.Unmarshal(data, &config)
+	}
+	if err == nil {
+		return config, nil
+	}
+
+	// Decode the values one by one to find those with the wrong type
+	fields := configFieldsByKey()
+	keys := make([]string, 0, len(layered.values))
+	for key := range layered.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var errs []error
+	for _, key := range keys {
+		field, ok := fields[key]
+		if !ok {
+			continue
+		}
+		data, err := yaml.Marshal(map[string]interface{}{key: layered.values[key]})
+		if err == nil {
+			err = yaml.Unmarshal(data, &Config{})
+		}
+		if err != nil {
+			errs = append(errs, layered.typeError(key, field.Type))
+		}
+	}
+	if len(errs) == 0 {
+		errs = append(errs, fmt.Errorf("%v contains incorrect content: %w", fileName, err))
+	}
+	return Config{}, errors.Join(errs...)
+}
+
+// recordOrigins records the file that set each config field.
+//
+// Parameters:
+//   - provenance: The provenance to update.
+func (layered configFiles) recordOrigins(provenance Provenance) {
+	configType := reflect.TypeOf(Config{})
+	for i := 0; i < configType.NumField(); i++ {
+		fileName, ok := layered.origins[yamlKey(configType.Field(i))]
+		if ok {
+			provenance.record(configType.Field(i).Name, SourceFile, fileName)
+		}
+	}
+}
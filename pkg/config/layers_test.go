@@ -0,0 +1,194 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes files with the given content into dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestReadConfigFiles tests includes, stage overlays and the merge semantics of layered config files
+func TestReadConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"common.yaml": "LOG_LEVEL: info\nSERVICE_NAME: common\nWORKFLOW_CONFIG_VARIABLES:\n  region: eu\n  team: aali\n",
+		"config.yaml": "include: common.yaml\nSTAGE: production\nSERVICE_NAME: base\nAGENT_PORT: \"9003\"\n" +
+			"PRIVATE_WORKFLOWS_FOLDERS: [base]\nWORKFLOW_CONFIG_VARIABLES:\n  debug: \"true\"\n",
+		"config.production.yaml": "LOG_LEVEL: warn\nAGENT_PORT: null\nPRIVATE_WORKFLOWS_FOLDERS+: [production]\n" +
+			"WORKFLOW_CONFIG_VARIABLES:\n  region: us\n  debug: null\n",
+		"config.dev.yaml":   "LOG_LEVEL: debug\nPRIVATE_WORKFLOWS_FOLDERS: [dev]\n",
+		"custom.yaml":       "SERVICE_NAME: custom\n",
+		"cycle-a.yaml":      "include: cycle-b.yaml\n",
+		"cycle-b.yaml":      "include: [cycle-a.yaml]\n",
+		"invalid-list.yaml": "PRIVATE_WORKFLOWS_FOLDERS+: single\n",
+	})
+	base := filepath.Join(dir, "config.yaml")
+
+	// Stage from the base file
+	layered, err := readConfigFiles(base, nil, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config, err := layered.decode(base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Config{
+		LOG_LEVEL:                 "warn",
+		SERVICE_NAME:              "base",
+		STAGE:                     "production",
+		PRIVATE_WORKFLOWS_FOLDERS: []string{"base", "production"},
+		WORKFLOW_CONFIG_VARIABLES: map[string]string{"region": "us", "team": "aali"},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Expected config %+v, got %+v", expected, config)
+	}
+	expectedOrigins := map[string]string{
+		"LOG_LEVEL":                 filepath.Join(dir, "config.production.yaml"),
+		"SERVICE_NAME":              base,
+		"STAGE":                     base,
+		"PRIVATE_WORKFLOWS_FOLDERS": filepath.Join(dir, "config.production.yaml"),
+		"WORKFLOW_CONFIG_VARIABLES": filepath.Join(dir, "config.production.yaml"),
+	}
+	if !reflect.DeepEqual(layered.origins, expectedOrigins) {
+		t.Errorf("Expected origins %v, got %v", expectedOrigins, layered.origins)
+	}
+	if len(layered.files) != 3 {
+		t.Errorf("Expected 3 files to be read, got %v", layered.files)
+	}
+
+	// Explicit stage
+	layered, err = readConfigFiles(base, nil, "dev", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config, _ = layered.decode(base)
+	if config.LOG_LEVEL != "debug" || !reflect.DeepEqual(config.PRIVATE_WORKFLOWS_FOLDERS, []string{"dev"}) || config.AGENT_PORT != "9003" {
+		t.Errorf("unexpected dev config: %+v", config)
+	}
+
+	// Explicit overlays replace the stage overlay
+	layered, err = readConfigFiles(base, []string{filepath.Join(dir, "custom.yaml")}, "dev", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config, _ = layered.decode(base)
+	if config.SERVICE_NAME != "custom" || config.LOG_LEVEL != "info" {
+		t.Errorf("unexpected config with explicit overlay: %+v", config)
+	}
+
+	// Errors
+	_, err = readConfigFiles(filepath.Join(dir, "cycle-a.yaml"), nil, "", nil)
+	if err == nil || !strings.Contains(err.Error(), "include each other") {
+		t.Errorf("expected include cycle error, got %v", err)
+	}
+	_, err = readConfigFiles(base, []string{filepath.Join(dir, "missing.yaml")}, "", nil)
+	if err == nil {
+		t.Errorf("expected error for missing overlay")
+	}
+	_, err = readConfigFiles(filepath.Join(dir, "invalid-list.yaml"), nil, "", nil)
+	if err == nil {
+		t.Errorf("expected error for appending a non-list value")
+	}
+}
+
+// TestInterpolate tests environment, file and config field references in config values
+func TestInterpolate(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"token": "file-token\n",
+		"config.yaml": "FLOWKIT_HOST: ${TEST_FLOWKIT_HOST:-localhost}\n" +
+			"EXTERNALFUNCTIONS_GRPC_PORT: \"50051\"\n" +
+			"EXTERNALFUNCTIONS_ENDPOINT: ${FLOWKIT_HOST}:${EXTERNALFUNCTIONS_GRPC_PORT}\n" +
+			"FLOWKIT_API_KEY: ${file:" + filepath.Join(dir, "token") + "}\n" +
+			"QDRANT_PORT: ${env:TEST_QDRANT_PORT}\n" +
+			"USE_SSL: ${TEST_USE_SSL:-false}\n" +
+			"SERVICE_NAME: $${literal}\n" +
+			"UNUSED_VARIABLE: unused\n" +
+			"WORKFLOW_CONFIG_VARIABLES:\n  endpoint: http://${config:EXTERNALFUNCTIONS_ENDPOINT}\n",
+		"cycle.yaml":      "AGENT_PORT: ${EXEC_ID}\nEXEC_ID: ${AGENT_PORT}\n",
+		"unresolved.yaml": "AGENT_PORT: \"9003\"\nLLM_API_KEY: ${TEST_UNDEFINED_VARIABLE}\n",
+	})
+	t.Setenv("TEST_QDRANT_PORT", "6333")
+
+	fileName := filepath.Join(dir, "config.yaml")
+	layered, err := readConfigFiles(fileName, nil, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(layered.violations) != 0 {
+		t.Fatalf("unexpected violations: %v", layered.violations)
+	}
+	if len(layered.warnings) != 1 || !strings.Contains(layered.warnings[0], "unknown config key 'UNUSED_VARIABLE' is ignored") {
+		t.Errorf("Expected only the unreferenced variable to be reported, got %q", layered.warnings)
+	}
+	config, err := layered.decode(fileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.EXTERNALFUNCTIONS_ENDPOINT != "localhost:50051" {
+		t.Errorf("Expected field references to be resolved, got %q", config.EXTERNALFUNCTIONS_ENDPOINT)
+	}
+	if config.FLOWKIT_API_KEY != "file-token" {
+		t.Errorf("Expected file reference to be resolved, got %q", config.FLOWKIT_API_KEY)
+	}
+	if config.QDRANT_PORT != 6333 || config.USE_SSL {
+		t.Errorf("Expected typed environment references, got %v and %v", config.QDRANT_PORT, config.USE_SSL)
+	}
+	if config.SERVICE_NAME != "${literal}" {
+		t.Errorf("Expected escaped reference, got %q", config.SERVICE_NAME)
+	}
+	if config.WORKFLOW_CONFIG_VARIABLES["endpoint"] != "http://localhost:50051" {
+		t.Errorf("Expected map values to be interpolated, got %v", config.WORKFLOW_CONFIG_VARIABLES)
+	}
+
+	// Cycles are reported for every field of the cycle
+	layered, err = readConfigFiles(filepath.Join(dir, "cycle.yaml"), nil, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(layered.violations) != 2 || !strings.Contains(layered.violations[0].Message, "AGENT_PORT -> EXEC_ID -> AGENT_PORT") {
+		t.Errorf("Expected reference cycle violations, got %v", layered.violations)
+	}
+
+	// Unresolved references are validation errors
+	_, err = Load(LoadOptions{FilePath: filepath.Join(dir, "unresolved.yaml"), IgnoreEnvironment: true})
+	validationError, ok := err.(*ValidationError)
+	if !ok || len(validationError.Violations) != 1 || validationError.Violations[0].Field != "LLM_API_KEY" || validationError.Violations[0].Rule != "reference" {
+		t.Errorf("Expected reference violation for LLM_API_KEY, got %v", err)
+	}
+}
@@ -0,0 +1,370 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+////////////////////////////////
+// Service profiles
+////////////////////////////////
+
+// Names of the predefined service profiles.
+const (
+	ServiceAgent   = "agent"
+	ServiceFlowkit = "flowkit"
+	ServiceLLM     = "llm"
+	ServiceExec    = "exec"
+	ServiceKVDB    = "kvdb"
+	ServiceGraphDB = "graphdb"
+)
+
+// commonFields are the logging, SSL, secret and feature flag settings shared by all services.
+var commonFields = []string{
+	"LOG_LEVEL", "LOG_LEVEL_OVERRIDES", "CONSOLE_LOG_LEVEL", "LOCAL_LOGS", "LOCAL_LOGS_LOCATION", "LOCAL_LOGS_LEVEL",
+	"LOCAL_LOGS_MAX_SIZE_MB", "LOCAL_LOGS_ROTATION_HOURS", "LOCAL_LOGS_MAX_AGE_DAYS", "LOCAL_LOGS_MAX_BACKUPS", "LOCAL_LOGS_COMPRESS",
+	"DATADOG_LOGS", "STAGE", "VERSION", "SERVICE_NAME", "ERROR_FILE_LOCATION", "LOGGING_URL", "LOGGING_API_KEY",
+	"DATADOG_SOURCE", "DATADOG_LOG_LEVEL", "DATADOG_METRICS", "METRICS_URL", "METRICS_INTERVAL_SECONDS",
+	"DATADOG_QUEUE_SIZE", "DATADOG_BATCH_SIZE", "DATADOG_FLUSH_INTERVAL_SECONDS", "DATADOG_MAX_RETRIES",
+	"DATADOG_SPOOL_DIRECTORY", "DATADOG_SPOOL_MAX_MB",
+	"OTLP_LOGS", "OTLP_LOGS_URL", "OTLP_LOG_LEVEL",
+	"USE_SSL", "SSL_CERT_PUBLIC_KEY_FILE", "SSL_CERT_PRIVATE_KEY_FILE",
+	"EXTRACT_CONFIG_FROM_AZURE_KEY_VAULT", "AZURE_KEY_VAULT_NAME", "AZURE_MANAGED_IDENTITY_ID",
+	"AZURE_KEY_VAULT_SECRET_NAMES", "AZURE_KEY_VAULT_TIMEOUT_SECONDS", "AZURE_KEY_VAULT_CONCURRENCY", "AZURE_KEY_VAULT_CACHE_TTL",
+	"SECRETS_DIRECTORY", "SECRETS_DOTENV_FILE", "SECRETS_HTTP_VAULT_URL", "SECRETS_HTTP_VAULT_TOKEN",
+	"FEATURE_FLAGS",
+}
+
+// commonDefaultValues are the default values shared by all services.
+var commonDefaultValues = map[string]interface{}{
+	"LOG_LEVEL":           "info",
+	"ERROR_FILE_LOCATION": "error.log",
+	"LOCAL_LOGS_LOCATION": "logs.log",
+}
+
+// serviceProfiles contains the predefined service profiles by name.
+var serviceProfiles = map[string]ServiceProfile{
+	ServiceAgent: {
+		Name: ServiceAgent,
+		Fields: []string{
+			"PRODUCTION_MODE", "AGENT_PORT", "WORKFLOW_API_KEY", "WORKFLOW_STORE_PATH", "BINARY_STORE_PATH",
+			"NUMBER_OF_WORKFLOW_WORKERS", "EXTERNALFUNCTIONS_ENDPOINT", "FLOWKIT_PYTHON_ENDPOINT", "FLOWKIT_PYTHON_API_KEY",
+			"ENABLE_AUTH", "AZURE_AD_AUTHENTICATION_URL", "ANSYS_AUTHORIZATION_URL", "ANSYS_AUTHORIZATION_CRYPT_KEY",
+			"ANSYS_DISCO_CRYPT_PRIVATE_KEY", "ANSYS_DISCO_SIGN_PUBLIC_KEY", "DISABLE_PUBLIC_WORKFLOWS", "LOAD_PRIVATE_WORKFLOWS",
+			"GITHUB_USER", "GITHUB_TOKEN", "PRIVATE_WORKFLOWS_FOLDERS", "EXEC_ENDPOINT", "EXEC_AGENT_API_KEY",
+			"MONGO_DB_FOR_MULTI_AGENT", "MONGO_DB_ENDPOINT", "MILLISECONDS_MONGODB_UPDATE_INTERVAL", "EXEC_FILE_STORE_PATH",
+			"KVDB_ENDPOINT", "FLOWKIT_API_KEY", "WORKFLOW_CONFIG_VARIABLES",
+		},
+		RequiredProperties: []string{"AGENT_PORT", "EXTERNALFUNCTIONS_ENDPOINT"},
+		OptionalDefaultValues: map[string]interface{}{
+			"SERVICE_NAME":               "aali-agent",
+			"NUMBER_OF_WORKFLOW_WORKERS": 5,
+		},
+	},
+	ServiceFlowkit: {
+		Name: ServiceFlowkit,
+		Fields: []string{
+			"EXTERNALFUNCTIONS_GRPC_PORT", "FLOWKIT_API_KEY", "LLM_HANDLER_ENDPOINT", "KNOWLEDGE_DB_ENDPOINT",
+			"QDRANT_HOST", "QDRANT_PORT", "GRAPHDB_ADDRESS", "WORKFLOW_CONFIG_VARIABLES",
+		},
+		RequiredProperties: []string{"EXTERNALFUNCTIONS_GRPC_PORT"},
+		OptionalDefaultValues: map[string]interface{}{
+			"SERVICE_NAME": "aali-flowkit",
+		},
+	},
+	ServiceLLM: {
+		Name:               ServiceLLM,
+		Fields:             []string{"WEBSERVER_PORT", "MODELS_CONFIG_LOCATION", "LLM_API_KEY"},
+		RequiredProperties: []string{"WEBSERVER_PORT", "MODELS_CONFIG_LOCATION"},
+		OptionalDefaultValues: map[string]interface{}{
+			"SERVICE_NAME": "aali-llm",
+		},
+	},
+	ServiceExec: {
+		Name: ServiceExec,
+		Fields: []string{
+			"EXEC_ID", "WEBSERVER_PORT_EXEC", "EXEC_API_KEY", "PYTHON_EXECUTABLE", "BASH_EXECUTABLE",
+			"WATCH_FOLDER_PATH", "MILLISECONDS_SINCE_LAST_CHANGE", "AGENT_ENDPOINT", "WORKFLOW_CONFIG_VARIABLES",
+		},
+		RequiredProperties: []string{"EXEC_ID", "AGENT_ENDPOINT"},
+		OptionalDefaultValues: map[string]interface{}{
+			"SERVICE_NAME":      "aali-exec",
+			"PYTHON_EXECUTABLE": "python3",
+			"BASH_EXECUTABLE":   "bash",
+		},
+	},
+	ServiceKVDB: {
+		Name:               ServiceKVDB,
+		Fields:             []string{"KVDB_ADDRESS", "KVDB_PATH", "KVDB_IN_MEMORY"},
+		RequiredProperties: []string{"KVDB_ADDRESS"},
+		OptionalDefaultValues: map[string]interface{}{
+			"SERVICE_NAME": "aali-kvdb",
+		},
+	},
+	ServiceGraphDB: {
+		Name:               ServiceGraphDB,
+		Fields:             []string{"GRAPHDB_ADDRESS"},
+		RequiredProperties: []string{"GRAPHDB_ADDRESS"},
+		OptionalDefaultValues: map[string]interface{}{
+			"SERVICE_NAME": "aali-graphdb",
+		},
+	},
+}
+
+// GetServiceProfile returns the profile of a service, including the common logging, SSL and secret settings.
+//
+// Parameters:
+//   - service: The name of the service, e.g. ServiceExec.
+//
+// Returns:
+//   - ServiceProfile: A copy of the profile.
+//   - error: An error if there is no profile for the service.
+func GetServiceProfile(service string) (ServiceProfile, error) {
+	profile, ok := serviceProfiles[service]
+	if !ok {
+		return ServiceProfile{}, fmt.Errorf("unknown service profile '%v', expected one of %v", service, ServiceProfileNames())
+	}
+
+	result := ServiceProfile{
+		Name:                  profile.Name,
+		Fields:                append(append([]string{}, commonFields...), profile.Fields...),
+		RequiredProperties:    append([]string{}, profile.RequiredProperties...),
+		OptionalDefaultValues: map[string]interface{}{},
+	}
+	for key, value := range commonDefaultValues {
+		result.OptionalDefaultValues[key] = value
+	}
+	for key, value := range profile.OptionalDefaultValues {
+		result.OptionalDefaultValues[key] = value
+	}
+	return result, nil
+}
+
+// ServiceProfileNames returns the names of all service profiles.
+//
+// Returns:
+//   - []string: The sorted profile names.
+func ServiceProfileNames() []string {
+	names := make([]string, 0, len(serviceProfiles))
+	for name := range serviceProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// InitConfigForService initializes GlobalConfig like InitConfig, using the required properties and
+// default values of a service profile. It panics if the profile does not exist or the config is invalid;
+// in contrast to InitConfig, violations of the validation rules are errors as well.
+//
+// Parameters:
+//   - service: The name of the service, e.g. ServiceExec.
+func InitConfigForService(service string) {
+	_, err := GetServiceProfile(service)
+	if err != nil {
+		writeErrorAndPanic("error in initializing configuration:", err)
+	}
+
+	initConfig(LoadOptions{Service: service, StrictValidation: true})
+}
+
+// validateServiceConfig validates a config for a service.
+// Only violations of fields in the service profile are reported, and messages name the service.
+//
+// Parameters:
+//   - config: The configuration object to validate.
+//   - profile: The service profile.
+//   - requiredProperties: Additional required properties.
+//
+// Returns:
+//   - err: A *ValidationError listing all violations, or nil if the config is valid for the service.
+func validateServiceConfig(config Config, profile ServiceProfile, requiredProperties []string, explicit map[string]bool) (err error) {
+	required := append(append([]string{}, profile.RequiredProperties...), requiredProperties...)
+	err = validateConfig(config, required, explicit)
+	if err == nil {
+		return nil
+	}
+	validationError, ok := err.(*ValidationError)
+	if !ok {
+		return err
+	}
+
+	inScope := map[string]bool{}
+	for _, field := range append(profile.Fields, required...) {
+		inScope[field] = true
+	}
+
+	serviceError := &ValidationError{Service: profile.Name}
+	for _, violation := range validationError.Violations {
+		if !inScope[violation.Field] {
+			continue
+		}
+		if violation.Rule == "required" {
+			violation.Message = fmt.Sprintf("%v service requires config property '%v'", profile.Name, violation.Field)
+		}
+		serviceError.Violations = append(serviceError.Violations, violation)
+	}
+
+	if len(serviceError.Violations) == 0 {
+		return nil
+	}
+	return serviceError
+}
+
+////////////////////////////////
+// Scoped config
+////////////////////////////////
+
+// Scope returns a view of the config that only exposes the fields of a service profile.
+//
+// Parameters:
+//   - service: The name of the service, e.g. ServiceExec.
+//
+// Returns:
+//   - *ScopedConfig: The scoped view on a copy of the config.
+//   - error: An error if there is no profile for the service.
+func (config Config) Scope(service string) (*ScopedConfig, error) {
+	profile, err := GetServiceProfile(service)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]bool{}
+	for _, field := range profile.Fields {
+		fields[field] = true
+	}
+	return &ScopedConfig{service: service, fields: fields, config: config}, nil
+}
+
+// Service returns the name of the service the config is scoped to.
+//
+// Returns:
+//   - string: The service name.
+func (scoped *ScopedConfig) Service() string {
+	return scoped.service
+}
+
+// Fields returns the names of the fields exposed by the scoped config.
+//
+// Returns:
+//   - []string: The sorted field names.
+func (scoped *ScopedConfig) Fields() []string {
+	fields := make([]string, 0, len(scoped.fields))
+	for field := range scoped.fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Config returns a copy of the config in which all fields outside the service scope are zero.
+//
+// Returns:
+//   - Config: The scoped copy of the config.
+func (scoped *ScopedConfig) Config() Config {
+	result := Config{}
+	source := reflect.ValueOf(scoped.config)
+	target := reflect.ValueOf(&result).Elem()
+	for field := range scoped.fields {
+		target.FieldByName(field).Set(source.FieldByName(field))
+	}
+	return result
+}
+
+// Get returns the value of a field in the service scope.
+//
+// Parameters:
+//   - field: The name of the config field.
+//
+// Returns:
+//   - interface{}: The value of the field.
+//   - error: An error if the field is not part of the service scope.
+func (scoped *ScopedConfig) Get(field string) (interface{}, error) {
+	if !scoped.fields[field] {
+		return nil, fmt.Errorf("config property '%v' is not part of the %v service configuration", field, scoped.service)
+	}
+	return reflect.ValueOf(scoped.config).FieldByName(field).Interface(), nil
+}
+
+// GetString returns the value of a string field in the service scope.
+//
+// Parameters:
+//   - field: The name of the config field.
+//
+// Returns:
+//   - string: The value of the field.
+//   - error: An error if the field is not part of the service scope or not a string.
+func (scoped *ScopedConfig) GetString(field string) (string, error) {
+	value, err := scoped.Get(field)
+	if err != nil {
+		return "", err
+	}
+	typed, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("config property '%v' is of type %T, not string", field, value)
+	}
+	return typed, nil
+}
+
+// GetInt returns the value of an integer field in the service scope.
+//
+// Parameters:
+//   - field: The name of the config field.
+//
+// Returns:
+//   - int: The value of the field.
+//   - error: An error if the field is not part of the service scope or not an integer.
+func (scoped *ScopedConfig) GetInt(field string) (int, error) {
+	value, err := scoped.Get(field)
+	if err != nil {
+		return 0, err
+	}
+	typed, ok := value.(int)
+	if !ok {
+		return 0, fmt.Errorf("config property '%v' is of type %T, not int", field, value)
+	}
+	return typed, nil
+}
+
+// GetBool returns the value of a boolean field in the service scope.
+//
+// Parameters:
+//   - field: The name of the config field.
+//
+// Returns:
+//   - bool: The value of the field.
+//   - error: An error if the field is not part of the service scope or not a boolean.
+func (scoped *ScopedConfig) GetBool(field string) (bool, error) {
+	value, err := scoped.Get(field)
+	if err != nil {
+		return false, err
+	}
+	typed, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("config property '%v' is of type %T, not bool", field, value)
+	}
+	return typed, nil
+}
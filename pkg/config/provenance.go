@@ -0,0 +1,189 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"text/tabwriter"
+)
+
+// Sources of config values, from lowest to highest precedence.
+const (
+	SourceUnset          Source = "unset"
+	SourceDefault        Source = "default"
+	SourceFile           Source = "file"
+	SourceEnvironment    Source = "environment"
+	SourceCLI            Source = "cli"
+	SourceAzureKeyVault  Source = "azure-key-vault"
+	SourceSecretProvider Source = "secret-provider"
+)
+
+////////////////////////////////
+// Provenance API
+////////////////////////////////
+
+// CurrentProvenance returns the origin of every field of the active configuration.
+//
+// Returns:
+//   - Provenance: The provenance of the active config; nil if the config was not loaded by InitConfig or a reload.
+func CurrentProvenance() Provenance {
+	provenance := activeProvenance.Load()
+	if provenance == nil {
+		return nil
+	}
+	return *provenance
+}
+
+// GetProvenanceReport returns a report of the active configuration with the origin of every value.
+// Secret fields are redacted, so the result is safe to log or expose.
+//
+// Returns:
+//   - string: The report, or an empty string if the config was not loaded by InitConfig or a reload.
+func GetProvenanceReport() string {
+	config := Current()
+	provenance := CurrentProvenance()
+	if config == nil || provenance == nil {
+		return ""
+	}
+	return provenance.Report(*config)
+}
+
+// Origin returns the origin of a config field.
+//
+// Parameters:
+//   - fieldName: The name of the config field.
+//
+// Returns:
+//   - Origin: The origin; its source is SourceUnset if no source set the field.
+func (provenance Provenance) Origin(fieldName string) Origin {
+	origin, ok := provenance[fieldName]
+	if !ok {
+		return Origin{Source: SourceUnset}
+	}
+	return origin
+}
+
+// Report formats every field of config with its effective value and origin as an aligned table.
+// Secret fields are redacted.
+//
+// Parameters:
+//   - config: The configuration the provenance was recorded for.
+//
+// Returns:
+//   - string: The report, one field per line in struct order.
+func (provenance Provenance) Report(config Config) string {
+	redacted := reflect.ValueOf(config.Redacted())
+	configType := redacted.Type()
+
+	var buffer bytes.Buffer
+	writer := tabwriter.NewWriter(&buffer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "FIELD\tVALUE\tSOURCE")
+	for i := 0; i < configType.NumField(); i++ {
+		if isDeprecatedField(configType.Field(i)) {
+			continue
+		}
+		name := configType.Field(i).Name
+		value, err := json.Marshal(redacted.Field(i).Interface())
+		if err != nil {
+			value = []byte(fmt.Sprint(redacted.Field(i).Interface()))
+		}
+		fmt.Fprintf(writer, "%v\t%s\t%v\n", name, value, provenance.Origin(name))
+	}
+	writer.Flush()
+	return buffer.String()
+}
+
+// String returns the source of the origin followed by its location in parentheses.
+//
+// Returns:
+//   - string: The origin, e.g. "environment (AALI_LOG_LEVEL)".
+func (origin Origin) String() string {
+	if origin.Location == "" {
+		return string(origin.Source)
+	}
+	return fmt.Sprintf("%v (%v)", origin.Source, origin.Location)
+}
+
+////////////////////////////////
+// Recording
+////////////////////////////////
+
+// newProvenance creates a provenance with every config field unset.
+//
+// Returns:
+//   - Provenance: The provenance.
+func newProvenance() Provenance {
+	configType := reflect.TypeOf(Config{})
+	provenance := make(Provenance, configType.NumField())
+	for i := 0; i < configType.NumField(); i++ {
+		if isDeprecatedField(configType.Field(i)) {
+			continue
+		}
+		provenance[configType.Field(i).Name] = Origin{Source: SourceUnset}
+	}
+	return provenance
+}
+
+// record sets the origin of a config field. It does nothing on a nil provenance,
+// so loading functions can be used with and without provenance tracking.
+//
+// Parameters:
+//   - fieldName: The name of the config field.
+//   - source: The source of the value.
+//   - location: The file, environment variable, flag, secret or profile that set the value.
+func (provenance Provenance) record(fieldName string, source Source, location string) {
+	if provenance == nil {
+		return
+	}
+	provenance[fieldName] = Origin{Source: source, Location: location}
+}
+
+// recordDefaults records the fields that were set by the optional default values.
+// Defaults given in the load options are recorded without location, defaults of the
+// service profile with the name of the profile.
+//
+// Parameters:
+//   - before: The config before the default values were applied.
+//   - after: The config after the default values were applied.
+//   - options: The load options.
+//   - provenance: The provenance to update.
+func recordDefaults(before Config, after Config, options LoadOptions, provenance Provenance) {
+	beforeValue := reflect.ValueOf(before)
+	afterValue := reflect.ValueOf(after)
+	configType := beforeValue.Type()
+	for i := 0; i < configType.NumField(); i++ {
+		if reflect.DeepEqual(beforeValue.Field(i).Interface(), afterValue.Field(i).Interface()) {
+			continue
+		}
+		name := configType.Field(i).Name
+		_, explicit := options.OptionalDefaultValues[name]
+		if explicit || options.Service == "" {
+			provenance.record(name, SourceDefault, "")
+		} else {
+			provenance.record(name, SourceDefault, options.Service+" service profile")
+		}
+	}
+}
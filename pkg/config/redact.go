@@ -0,0 +1,163 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v2"
+)
+
+// RedactedValue replaces the value of secret config fields in redacted output.
+const RedactedValue = "[REDACTED]"
+
+// IsSecretField reports whether a config field is tagged as secret.
+//
+// Parameters:
+//   - fieldName: The name of the config field.
+//
+// Returns:
+//   - bool: True if the field exists and is tagged with `secret:"true"`.
+func IsSecretField(fieldName string) bool {
+	field, ok := reflect.TypeOf(Config{}).FieldByName(fieldName)
+	return ok && field.Tag.Get("secret") == "true"
+}
+
+// Redacted returns a copy of the config in which all non-empty secret fields are replaced by RedactedValue.
+// In secret lists, maps and structs every non-empty string is replaced, map keys are kept; other non-string
+// values are reset to their zero value. Empty secret fields stay empty, so it remains visible whether a secret is set.
+//
+// Returns:
+//   - Config: The redacted copy.
+func (config Config) Redacted() Config {
+	redacted := config
+	redactedValue := reflect.ValueOf(&redacted).Elem()
+	configType := redactedValue.Type()
+	for i := 0; i < configType.NumField(); i++ {
+		if configType.Field(i).Tag.Get("secret") == "true" {
+			field := redactedValue.Field(i)
+			field.Set(redactValue(field))
+		}
+	}
+	return redacted
+}
+
+// redactValue returns a redacted copy of a value of any kind; the value itself is not modified,
+// so slices and maps shared with the original config stay intact.
+//
+// Parameters:
+//   - value: The value to redact.
+//
+// Returns:
+//   - reflect.Value: The redacted copy.
+func redactValue(value reflect.Value) reflect.Value {
+	redacted := reflect.New(value.Type()).Elem()
+	switch value.Kind() {
+	case reflect.String:
+		if value.String() != "" {
+			redacted.SetString(RedactedValue)
+		}
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice {
+			if value.IsNil() {
+				return redacted
+			}
+			redacted = reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		}
+		for i := 0; i < value.Len(); i++ {
+			redacted.Index(i).Set(redactValue(value.Index(i)))
+		}
+	case reflect.Map:
+		if value.IsNil() {
+			return redacted
+		}
+		redacted = reflect.MakeMapWithSize(value.Type(), value.Len())
+		iterator := value.MapRange()
+		for iterator.Next() {
+			redacted.SetMapIndex(iterator.Key(), redactValue(iterator.Value()))
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if redacted.Field(i).CanSet() {
+				redacted.Field(i).Set(redactValue(value.Field(i)))
+			}
+		}
+	case reflect.Pointer, reflect.Interface:
+		if !value.IsNil() && value.Kind() == reflect.Pointer {
+			redacted = reflect.New(value.Type().Elem())
+			redacted.Elem().Set(redactValue(value.Elem()))
+		}
+	}
+	return redacted
+}
+
+// RedactedJSON returns the redacted config as JSON string.
+//
+// Returns:
+//   - string: The redacted config as JSON.
+//   - error: An error if the config could not be marshalled.
+func (config Config) RedactedJSON() (string, error) {
+	jsonData, err := json.Marshal(config.Redacted())
+	if err != nil {
+		return "", err
+	}
+	return string(jsonData), nil
+}
+
+// RedactedYAML returns the redacted config as YAML string.
+//
+// Returns:
+//   - string: The redacted config as YAML.
+//   - error: An error if the config could not be marshalled.
+func (config Config) RedactedYAML() (string, error) {
+	yamlData, err := yaml.Marshal(config.Redacted())
+	if err != nil {
+		return "", err
+	}
+	return string(yamlData), nil
+}
+
+// String returns the redacted config as JSON string, so that printing a config never leaks secrets.
+//
+// Returns:
+//   - string: The redacted config.
+func (config Config) String() string {
+	jsonData, err := config.RedactedJSON()
+	if err != nil {
+		return fmt.Sprintf("config could not be printed: %v", err)
+	}
+	return jsonData
+}
+
+// String returns the change with secret values redacted.
+//
+// Returns:
+//   - string: The field and its old and new value.
+func (change ConfigChange) String() string {
+	if IsSecretField(change.Field) {
+		return fmt.Sprintf("%v: %v -> %v", change.Field, RedactedValue, RedactedValue)
+	}
+	return fmt.Sprintf("%v: %v -> %v", change.Field, change.OldValue, change.NewValue)
+}
@@ -0,0 +1,343 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+////////////////////////////////
+// JSON schema
+////////////////////////////////
+
+// jsonSchemaDialect is the JSON schema version of the generated schemas.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// GenerateJSONSchema generates a JSON schema for config files.
+// The schema contains the type and description of each field, enum values and ranges derived from the
+// `validate` struct tags, and marks secret fields with "writeOnly" and "x-secret". Like the loader, it accepts
+// lists appended with the "+" suffix, references and encrypted values in typed fields, and variables without field.
+// For a service profile, the schema only contains the fields of the profile, lists the required
+// properties of the profile and contains its default values.
+//
+// Parameters:
+//   - service: The name of a service profile, or an empty string for the schema of the full config.
+//
+// Returns:
+//   - []byte: The indented JSON schema.
+//   - error: An error if the service profile does not exist.
+func GenerateJSONSchema(service string) ([]byte, error) {
+	fields, profile, err := templateFields(service)
+	if err != nil {
+		return nil, err
+	}
+
+	title := "Aali configuration"
+	if service != "" {
+		title = fmt.Sprintf("Aali %v service configuration", service)
+	}
+
+	properties := map[string]interface{}{}
+	patternProperties := map[string]interface{}{}
+	for _, field := range fields {
+		property := valueSchema(field)
+		property["description"] = FieldDescription(field.Name)
+		if field.Tag.Get("secret") == "true" {
+			property["writeOnly"] = true
+			property["x-secret"] = true
+		}
+		defaultValue, ok := profile.OptionalDefaultValues[field.Name]
+		if ok {
+			property["default"] = defaultValue
+		}
+		properties[yamlKey(field)] = property
+
+		// Deprecated aliases are accepted, but flagged by editors
+		for _, alias := range fieldAliases(field) {
+			aliasProperty := valueSchema(field)
+			aliasProperty["description"] = fmt.Sprintf("Deprecated, use %v.", yamlKey(field))
+			aliasProperty["deprecated"] = true
+			properties[alias] = aliasProperty
+		}
+
+		// Lists can be appended to the list of the included files with the "+" suffix
+		if field.Type.Kind() == reflect.Slice {
+			appendProperty := valueSchema(field)
+			appendProperty["description"] = fmt.Sprintf("Values appended to %v of the included files.", yamlKey(field))
+			patternProperties["^"+regexp.QuoteMeta(yamlKey(field)+appendSuffix)+"$"] = appendProperty
+		}
+	}
+	properties[includeKey] = map[string]interface{}{
+		"description": "Config files merged before this file, relative to it.",
+		"oneOf": []interface{}{
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		},
+	}
+
+	schema := map[string]interface{}{
+		"$schema":              jsonSchemaDialect,
+		"title":                title,
+		"type":                 "object",
+		"properties":           properties,
+		"patternProperties":    patternProperties,
+		"additionalProperties": variableSchema,
+	}
+	if len(profile.RequiredProperties) > 0 {
+		schema["required"] = profile.RequiredProperties
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// referenceSchema matches string values that are converted to the type of their field when the config is loaded:
+// values with ${...} references and encrypted ENC[...] values.
+var referenceSchema = map[string]interface{}{
+	"type":    "string",
+	"pattern": `\$\{|^ENC\[`,
+}
+
+// variableSchema allows keys without config field, which can be defined as variables for ${config:NAME} references.
+var variableSchema = map[string]interface{}{
+	"description": "Variable for ${config:NAME} references; keys that are not referenced are ignored.",
+	"type":        []string{"string", "number", "boolean"},
+}
+
+// valueSchema returns the JSON schema of the value of a config field: its type and the keywords of its `validate` tag.
+// Scalar values that are typed or restricted also accept strings with references or encrypted values,
+// which are only checked once they are resolved.
+//
+// Parameters:
+//   - field: The config field.
+//
+// Returns:
+//   - map[string]interface{}: The schema.
+func valueSchema(field reflect.StructField) map[string]interface{} {
+	typed := fieldSchema(field.Type)
+	for key, value := range validationSchema(field) {
+		typed[key] = value
+	}
+	switch field.Type.Kind() {
+	case reflect.Bool, reflect.Int:
+	case reflect.String:
+		_, hasEnum := typed["enum"]
+		_, hasPattern := typed["pattern"]
+		if !hasEnum && !hasPattern {
+			return typed
+		}
+	default:
+		return typed
+	}
+	return map[string]interface{}{"anyOf": []interface{}{typed, referenceSchema}}
+}
+
+// fieldSchema returns the JSON schema type definition of a config field type.
+//
+// Parameters:
+//   - fieldType: The Go type of the field.
+//
+// Returns:
+//   - map[string]interface{}: The type definition.
+func fieldSchema(fieldType reflect.Type) map[string]interface{} {
+	switch fieldType.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": fieldSchema(fieldType.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": fieldSchema(fieldType.Elem())}
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		for i := 0; i < fieldType.NumField(); i++ {
+			properties[yamlKey(fieldType.Field(i))] = fieldSchema(fieldType.Field(i).Type)
+		}
+		return map[string]interface{}{"type": "object", "properties": properties}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
+
+// validationSchema translates the `validate` struct tag of a field into JSON schema keywords.
+//
+// Parameters:
+//   - field: The struct field.
+//
+// Returns:
+//   - map[string]interface{}: The schema keywords.
+func validationSchema(field reflect.StructField) map[string]interface{} {
+	keywords := map[string]interface{}{}
+	tag := field.Tag.Get("validate")
+	if tag == "" {
+		return keywords
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, argument, _ := strings.Cut(rule, "=")
+		switch name {
+		case "oneof":
+			keywords["enum"] = strings.Fields(argument)
+		case "port":
+			if field.Type.Kind() == reflect.Int {
+				keywords["minimum"] = 1
+				keywords["maximum"] = 65535
+			} else {
+				keywords["pattern"] = "^[0-9]{1,5}$"
+			}
+		case "min":
+			var minimum int
+			_, err := fmt.Sscan(argument, &minimum)
+			if err == nil {
+				keywords["minimum"] = minimum
+			}
+		case "max":
+			var maximum int
+			_, err := fmt.Sscan(argument, &maximum)
+			if err == nil {
+				keywords["maximum"] = maximum
+			}
+		case "url":
+			keywords["format"] = "uri"
+		}
+	}
+	return keywords
+}
+
+////////////////////////////////
+// Config template
+////////////////////////////////
+
+// GenerateTemplate generates a commented YAML config template.
+// Every field is preceded by its description and whether it is required, secret or has a default value.
+// Fields are set to their default value if there is one, and to their zero value otherwise.
+//
+// Parameters:
+//   - service: The name of a service profile, or an empty string for a template of the full config.
+//
+// Returns:
+//   - []byte: The YAML template.
+//   - error: An error if the service profile does not exist.
+func GenerateTemplate(service string) ([]byte, error) {
+	fields, profile, err := templateFields(service)
+	if err != nil {
+		return nil, err
+	}
+
+	required := map[string]bool{}
+	for _, property := range profile.RequiredProperties {
+		required[property] = true
+	}
+
+	var buffer bytes.Buffer
+	if service != "" {
+		fmt.Fprintf(&buffer, "# Aali %v service configuration\n", service)
+	} else {
+		fmt.Fprintf(&buffer, "# Aali configuration\n")
+	}
+	fmt.Fprintf(&buffer, "# Every value can be overridden with an environment variable named %v<KEY>.\n", EnvironmentVariablePrefix)
+
+	for _, field := range fields {
+		buffer.WriteString("\n")
+		description := FieldDescription(field.Name)
+		if description != "" {
+			fmt.Fprintf(&buffer, "# %v\n", description)
+		}
+
+		var notes []string
+		if required[field.Name] {
+			notes = append(notes, "Required.")
+		}
+		if field.Tag.Get("secret") == "true" {
+			notes = append(notes, fmt.Sprintf("Secret: prefer %v%v or a secret provider over storing it in this file.", EnvironmentVariablePrefix, yamlKey(field)))
+		}
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			name, argument, _ := strings.Cut(rule, "=")
+			if name == "oneof" {
+				notes = append(notes, fmt.Sprintf("One of: %v.", strings.Join(strings.Fields(argument), ", ")))
+			}
+		}
+
+		value, hasDefault := profile.OptionalDefaultValues[field.Name]
+		if hasDefault {
+			notes = append(notes, fmt.Sprintf("Default: %v.", value))
+		} else {
+			value = reflect.Zero(field.Type).Interface()
+		}
+		if len(notes) > 0 {
+			fmt.Fprintf(&buffer, "# %v\n", strings.Join(notes, " "))
+		}
+
+		line, err := yaml.Marshal(yaml.MapSlice{{Key: yamlKey(field), Value: value}})
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(line)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// templateFields returns the config fields and the profile used for a schema or template.
+//
+// Parameters:
+//   - service: The name of a service profile, or an empty string for all fields.
+//
+// Returns:
+//   - []reflect.StructField: The fields in struct order.
+//   - ServiceProfile: The service profile; empty if service is empty.
+//   - error: An error if the service profile does not exist.
+func templateFields(service string) ([]reflect.StructField, ServiceProfile, error) {
+	configType := reflect.TypeOf(Config{})
+	profile := ServiceProfile{}
+	inScope := map[string]bool{}
+	if service != "" {
+		var err error
+		profile, err = GetServiceProfile(service)
+		if err != nil {
+			return nil, ServiceProfile{}, err
+		}
+		for _, field := range profile.Fields {
+			inScope[field] = true
+		}
+	}
+
+	fields := []reflect.StructField{}
+	for i := 0; i < configType.NumField(); i++ {
+		if isDeprecatedField(configType.Field(i)) {
+			continue
+		}
+		field := configType.Field(i)
+		if service == "" || inScope[field.Name] {
+			fields = append(fields, field)
+		}
+	}
+	return fields, profile, nil
+}
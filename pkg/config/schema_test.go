@@ -0,0 +1,278 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// TestFieldDescriptions tests that every config field is documented
+func TestFieldDescriptions(t *testing.T) {
+	configType := reflect.TypeOf(Config{})
+	for i := 0; i < configType.NumField(); i++ {
+		if !isDeprecatedField(configType.Field(i)) && FieldDescription(configType.Field(i).Name) == "" {
+			t.Errorf("config field '%v' has no description", configType.Field(i).Name)
+		}
+	}
+}
+
+// TestGenerateJSONSchema tests the GenerateJSONSchema function
+func TestGenerateJSONSchema(t *testing.T) {
+	data, err := GenerateJSONSchema("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema struct {
+		Properties map[string]map[string]interface{} `json:"properties"`
+	}
+	err = json.Unmarshal(data, &schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := 0
+	for _, property := range schema.Properties {
+		if property["deprecated"] != true {
+			current++
+		}
+	}
+	if current != reflect.TypeOf(Config{}).NumField()-2+1 {
+		t.Errorf("Expected one property per config field without the deprecated copies and include, got %d", current)
+	}
+	if schema.Properties["ANSYS_DISOC_SIGN_PUBLIC_KEY"]["deprecated"] != true {
+		t.Errorf("Expected deprecated alias ANSYS_DISOC_SIGN_PUBLIC_KEY, got %v", schema.Properties["ANSYS_DISOC_SIGN_PUBLIC_KEY"])
+	}
+	logLevel := schema.Properties["LOG_LEVEL"]["anyOf"].([]interface{})[0].(map[string]interface{})
+	if !reflect.DeepEqual(logLevel["enum"], []interface{}{"debug", "info", "warn", "error", "fatal"}) {
+		t.Errorf("Expected LOG_LEVEL enum, got %v", schema.Properties["LOG_LEVEL"])
+	}
+	if schema.Properties["LLM_API_KEY"]["x-secret"] != true {
+		t.Errorf("Expected LLM_API_KEY to be marked as secret, got %v", schema.Properties["LLM_API_KEY"])
+	}
+	if schema.Properties["WORKFLOW_CONFIG_VARIABLES"]["type"] != "object" {
+		t.Errorf("Expected WORKFLOW_CONFIG_VARIABLES to be an object, got %v", schema.Properties["WORKFLOW_CONFIG_VARIABLES"])
+	}
+
+	_, err = GenerateJSONSchema("unknown")
+	if err == nil {
+		t.Errorf("Expected error for unknown service profile")
+	}
+}
+
+// TestJSONSchemaLayeredConfig tests that config files using includes, appended lists, references and
+// encrypted values are valid against the generated schema, and that invalid values are still rejected
+func TestJSONSchemaLayeredConfig(t *testing.T) {
+	key := make([]byte, 32)
+	encrypted, err := EncryptValue(key, "6334")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.yaml": "SERVICE_NAME: aali\nPRIVATE_WORKFLOWS_FOLDERS: [/base]\n",
+		"config.yaml": "include: base.yaml\n" +
+			"FLOWKIT_HOST: flowkit\n" +
+			"EXTERNALFUNCTIONS_ENDPOINT: http://${config:FLOWKIT_HOST}:50051\n" +
+			"NUMBER_OF_WORKFLOW_WORKERS: ${AALI_TEST_WORKERS:-4}\n" +
+			"DISABLE_PUBLIC_WORKFLOWS: ${AALI_TEST_DISABLE_PUBLIC:-true}\n" +
+			"LOG_LEVEL: ${AALI_TEST_LOG_LEVEL:-debug}\n" +
+			"QDRANT_PORT: " + encrypted + "\n" +
+			"PRIVATE_WORKFLOWS_FOLDERS+: [/extra]\n",
+	}
+	writeFiles(t, dir, files)
+
+	// The fixture loads
+	config, err := Load(LoadOptions{FilePath: filepath.Join(dir, "config.yaml"), IgnoreEnvironment: true, EncryptionKey: key, StrictValidation: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.NUMBER_OF_WORKFLOW_WORKERS != 4 || !config.DISABLE_PUBLIC_WORKFLOWS || config.QDRANT_PORT != 6334 || len(config.PRIVATE_WORKFLOWS_FOLDERS) != 2 {
+		t.Fatalf("Unexpected config %+v", config)
+	}
+
+	// Every file is valid against the schema
+	data, err := GenerateJSONSchema("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]interface{}
+	err = json.Unmarshal(data, &schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		violations := schemaViolations(schema, yamlAsJSON(t, content), name)
+		if len(violations) > 0 {
+			t.Errorf("Expected %v to be valid, got %v", name, violations)
+		}
+	}
+
+	// Invalid values are still rejected
+	invalid := "LOG_LEVEL: verbose\nNUMBER_OF_WORKFLOW_WORKERS: four\nLOCAL_LOGS: 1\nPRIVATE_WORKFLOWS_FOLDERS+: /extra\n"
+	violations := schemaViolations(schema, yamlAsJSON(t, invalid), "invalid.yaml")
+	if len(violations) != 4 {
+		t.Errorf("Expected 4 violations, got %v", violations)
+	}
+}
+
+// yamlAsJSON decodes a YAML document into the types of a decoded JSON document.
+func yamlAsJSON(t *testing.T, content string) interface{} {
+	var value interface{}
+	err := yaml3.Unmarshal([]byte(content), &value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = json.Unmarshal(data, &value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+// schemaViolations checks a value against the JSON schema keywords used by GenerateJSONSchema.
+func schemaViolations(schema map[string]interface{}, value interface{}, path string) []string {
+	anyOf, ok := schema["anyOf"].([]interface{})
+	if ok {
+		for _, alternative := range anyOf {
+			if len(schemaViolations(alternative.(map[string]interface{}), value, path)) == 0 {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%v: %v matches no alternative", path, value)}
+	}
+
+	types := []interface{}{schema["type"]}
+	if list, ok := schema["type"].([]interface{}); ok {
+		types = list
+	}
+	matches := schema["type"] == nil
+	for _, name := range types {
+		switch typed := value.(type) {
+		case string:
+			matches = matches || name == "string"
+		case bool:
+			matches = matches || name == "boolean"
+		case float64:
+			matches = matches || name == "number" || (name == "integer" && typed == float64(int64(typed)))
+		case []interface{}:
+			matches = matches || name == "array"
+		case map[string]interface{}:
+			matches = matches || name == "object"
+		}
+	}
+	if !matches {
+		return []string{fmt.Sprintf("%v: %v is not of type %v", path, value, schema["type"])}
+	}
+
+	violations := []string{}
+	if enum, ok := schema["enum"].([]interface{}); ok && !slices.Contains(enum, value) {
+		violations = append(violations, fmt.Sprintf("%v: %v is not one of %v", path, value, enum))
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if text, ok := value.(string); ok && !regexp.MustCompile(pattern).MatchString(text) {
+			violations = append(violations, fmt.Sprintf("%v: %q does not match %v", path, text, pattern))
+		}
+	}
+	if number, ok := value.(float64); ok {
+		if minimum, ok := schema["minimum"].(float64); ok && number < minimum {
+			violations = append(violations, fmt.Sprintf("%v: %v is less than %v", path, number, minimum))
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && number > maximum {
+			violations = append(violations, fmt.Sprintf("%v: %v is greater than %v", path, number, maximum))
+		}
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		for i, item := range value.([]interface{}) {
+			violations = append(violations, schemaViolations(items, item, fmt.Sprintf("%v[%d]", path, i))...)
+		}
+	}
+	if object, ok := value.(map[string]interface{}); ok {
+		properties, _ := schema["properties"].(map[string]interface{})
+		patternProperties, _ := schema["patternProperties"].(map[string]interface{})
+	keys:
+		for key, item := range object {
+			if property, ok := properties[key]; ok {
+				violations = append(violations, schemaViolations(property.(map[string]interface{}), item, path+"."+key)...)
+				continue
+			}
+			for pattern, property := range patternProperties {
+				if regexp.MustCompile(pattern).MatchString(key) {
+					violations = append(violations, schemaViolations(property.(map[string]interface{}), item, path+"."+key)...)
+					continue keys
+				}
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					violations = append(violations, fmt.Sprintf("%v: unknown key %v", path, key))
+				}
+			case map[string]interface{}:
+				violations = append(violations, schemaViolations(additional, item, path+"."+key)...)
+			}
+		}
+	}
+	return violations
+}
+
+// TestGenerateTemplate tests that the generated templates are valid config files
+func TestGenerateTemplate(t *testing.T) {
+	for _, service := range ServiceProfileNames() {
+		t.Run(service, func(t *testing.T) {
+			data, err := GenerateTemplate(service)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var config Config
+			err = yaml.UnmarshalStrict(data, &config)
+			if err != nil {
+				t.Fatalf("template is not a valid config: %v\n%s", err, data)
+			}
+
+			profile, _ := GetServiceProfile(service)
+			for _, property := range profile.RequiredProperties {
+				if !strings.Contains(string(data), "\n"+property+":") {
+					t.Errorf("template misses required property %v", property)
+				}
+			}
+			err = ValidateConfig(config, nil)
+			if err != nil {
+				t.Errorf("template defaults are invalid: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,460 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+////////////////////////////////
+// Apply secrets to Config
+////////////////////////////////
+
+// secretProviderTimeout limits the time spent retrieving secrets during Load.
+const secretProviderTimeout = 30 * time.Second
+
+// ApplySecretProviders retrieves the secrets of each provider in order and sets the matching config fields.
+// Secret keys are matched against the YAML keys of the config case-insensitively, with '-' treated as '_',
+// so a mounted file named "llm-api-key" sets LLM_API_KEY. Keys without matching field are ignored.
+//
+// Parameters:
+//   - ctx: The context for the secret retrieval.
+//   - config: The configuration object to update.
+//   - providers: The secret providers; later providers override earlier ones.
+//
+// Returns:
+//   - err: An error joining all provider and conversion errors.
+func ApplySecretProviders(ctx context.Context, config *Config, providers ...SecretProvider) (err error) {
+	return applySecretProviders(ctx, config, nil, providers...)
+}
+
+// applySecretProviders applies the secrets of each provider like ApplySecretProviders.
+//
+// Parameters:
+//   - ctx: The context for the secret retrieval.
+//   - config: The configuration object to update.
+//   - provenance: Records the fields set by a secret; may be nil.
+//   - providers: The secret providers; later providers override earlier ones.
+//
+// Returns:
+//   - err: An error joining all provider and conversion errors.
+func applySecretProviders(ctx context.Context, config *Config, provenance Provenance, providers ...SecretProvider) (err error) {
+	configValue := reflect.ValueOf(config).Elem()
+
+	var errs []error
+	for _, provider := range providers {
+		secrets, err := provider.GetSecrets(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error in retrieving secrets from %v: %w", provider.Name(), err))
+			continue
+		}
+
+		for key, value := range secrets {
+			field, fieldName, ok := fieldBySecretKey(configValue, key)
+			if !ok {
+				continue
+			}
+			warnDeprecatedSecretKey(provider.Name(), key, fieldName)
+			err := setFieldFromString(field, value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid value for secret '%v' from %v: %w", key, provider.Name(), err))
+				continue
+			}
+			provenance.record(fieldName, SourceSecretProvider, provider.Name())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// secretProvidersFromConfig creates the secret providers enabled in the config.
+//
+// Parameters:
+//   - config: The configuration object.
+//
+// Returns:
+//   - []SecretProvider: The enabled providers in the order directory, .env file, HTTP vault.
+func secretProvidersFromConfig(config Config) []SecretProvider {
+	providers := []SecretProvider{}
+	if config.SECRETS_DIRECTORY != "" {
+		providers = append(providers, NewDirectorySecretProvider(config.SECRETS_DIRECTORY))
+	}
+	if config.SECRETS_DOTENV_FILE != "" {
+		providers = append(providers, NewDotEnvSecretProvider(config.SECRETS_DOTENV_FILE))
+	}
+	if config.SECRETS_HTTP_VAULT_URL != "" {
+		providers = append(providers, NewHTTPVaultSecretProvider(config.SECRETS_HTTP_VAULT_URL, config.SECRETS_HTTP_VAULT_TOKEN, nil))
+	}
+	return providers
+}
+
+// fieldBySecretKey returns the config field matching a secret key; deprecated aliases of the YAML keys match as well.
+//
+// Parameters:
+//   - configValue: The reflected config struct.
+//   - key: The secret key.
+//
+// Returns:
+//   - reflect.Value: The matching field.
+//   - string: The name of the matching field.
+//   - bool: True if a field matches.
+func fieldBySecretKey(configValue reflect.Value, key string) (reflect.Value, string, bool) {
+	normalized := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(key), "-", "_"))
+	configType := configValue.Type()
+	for i := 0; i < configType.NumField(); i++ {
+		if isDeprecatedField(configType.Field(i)) {
+			continue
+		}
+		if yamlKey(configType.Field(i)) == normalized {
+			return configValue.Field(i), configType.Field(i).Name, true
+		}
+		for _, alias := range fieldAliases(configType.Field(i)) {
+			if alias == normalized {
+				return configValue.Field(i), configType.Field(i).Name, true
+			}
+		}
+	}
+	return reflect.Value{}, "", false
+}
+
+////////////////////////////////
+// Directory secret provider
+////////////////////////////////
+
+// NewDirectorySecretProvider creates a provider reading one secret per file from a directory,
+// as mounted by Kubernetes or Docker secrets.
+//
+// Parameters:
+//   - path: The directory containing the secret files.
+//
+// Returns:
+//   - *DirectorySecretProvider: The provider.
+func NewDirectorySecretProvider(path string) *DirectorySecretProvider {
+	return &DirectorySecretProvider{Path: path}
+}
+
+// Name returns the name of the provider.
+//
+// Returns:
+//   - string: The name of the provider.
+func (provider *DirectorySecretProvider) Name() string {
+	return "secret directory " + provider.Path
+}
+
+// GetSecrets reads every regular file in the directory; the file name is the key and the content is the value.
+// Hidden files and directories are skipped and a single trailing newline is removed from the content.
+//
+// Parameters:
+//   - ctx: The context for the secret retrieval.
+//
+// Returns:
+//   - map[string]string: The secrets.
+//   - error: An error if the directory or a file cannot be read.
+func (provider *DirectorySecretProvider) GetSecrets(ctx context.Context) (map[string]string, error) {
+	entries, err := os.ReadDir(provider.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := map[string]string{}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		// Kubernetes mounts secrets as symlinks, so stat the target
+		path := filepath.Join(provider.Path, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		value := strings.TrimSuffix(string(data), "\n")
+		secrets[entry.Name()] = strings.TrimSuffix(value, "\r")
+	}
+
+	return secrets, nil
+}
+
+////////////////////////////////
+// .env secret provider
+////////////////////////////////
+
+// NewDotEnvSecretProvider creates a provider reading secrets from a .env file.
+//
+// Parameters:
+//   - path: The path to the .env file.
+//
+// Returns:
+//   - *DotEnvSecretProvider: The provider.
+func NewDotEnvSecretProvider(path string) *DotEnvSecretProvider {
+	return &DotEnvSecretProvider{Path: path}
+}
+
+// Name returns the name of the provider.
+//
+// Returns:
+//   - string: The name of the provider.
+func (provider *DotEnvSecretProvider) Name() string {
+	return ".env file " + provider.Path
+}
+
+// GetSecrets parses the .env file.
+//
+// Parameters:
+//   - ctx: The context for the secret retrieval.
+//
+// Returns:
+//   - map[string]string: The secrets.
+//   - error: An error if the file cannot be read or parsed.
+func (provider *DotEnvSecretProvider) GetSecrets(ctx context.Context) (map[string]string, error) {
+	data, err := os.ReadFile(provider.Path)
+	if err != nil {
+		return nil, err
+	}
+	return parseDotEnv(data)
+}
+
+// parseDotEnv parses the content of a .env file.
+// Supported are KEY=VALUE lines, an optional "export " prefix, comments starting with '#',
+// single quoted values (taken literally) and double quoted values (with \n, \t, \" and \\ escapes).
+//
+// Parameters:
+//   - data: The content of the file.
+//
+// Returns:
+//   - map[string]string: The parsed key value pairs.
+//   - error: An error with the line number of the first malformed line.
+func parseDotEnv(data []byte) (map[string]string, error) {
+	values, _, err := parseDotEnvLines(data)
+	return values, err
+}
+
+// parseDotEnvLines parses the content of a .env file like parseDotEnv and returns the line of every key.
+//
+// Parameters:
+//   - data: The content of the file.
+//
+// Returns:
+//   - map[string]string: The parsed key value pairs.
+//   - map[string]int: The line defining each key.
+//   - error: A *ParseError for the first malformed line.
+func parseDotEnvLines(data []byte) (map[string]string, map[string]int, error) {
+	values := map[string]string{}
+	lines := map[string]int{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, nil, &ParseError{Line: lineNumber, Message: fmt.Sprintf("expected KEY=VALUE, got %q", line)}
+		}
+
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(value, `"`):
+			end := closingQuote(value)
+			if end < 0 {
+				return nil, nil, &ParseError{Line: lineNumber, Message: fmt.Sprintf("unterminated double quoted value for %v", key)}
+			}
+			unquoted, err := strconv.Unquote(value[:end+1])
+			if err != nil {
+				return nil, nil, &ParseError{Line: lineNumber, Message: fmt.Sprintf("invalid double quoted value for %v: %v", key, err)}
+			}
+			value = unquoted
+		case strings.HasPrefix(value, "'"):
+			end := strings.Index(value[1:], "'")
+			if end < 0 {
+				return nil, nil, &ParseError{Line: lineNumber, Message: fmt.Sprintf("unterminated single quoted value for %v", key)}
+			}
+			value = value[1 : end+1]
+		default:
+			// Strip inline comments
+			index := strings.Index(value, " #")
+			if index >= 0 {
+				value = strings.TrimSpace(value[:index])
+			}
+		}
+
+		values[key] = value
+		lines[key] = lineNumber
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, nil, err
+	}
+	return values, lines, nil
+}
+
+// closingQuote returns the index of the unescaped double quote closing a value that starts with a double quote.
+//
+// Parameters:
+//   - value: The value starting with a double quote.
+//
+// Returns:
+//   - int: The index of the closing quote, or -1 if there is none.
+func closingQuote(value string) int {
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+////////////////////////////////
+// HTTP vault secret provider
+////////////////////////////////
+
+// NewHTTPVaultSecretProvider creates a provider reading secrets from an HTTP vault API.
+//
+// Parameters:
+//   - url: The URL returning the secrets as JSON object.
+//   - token: The token sent as bearer token; no authorization header is sent if empty.
+//   - httpClient: The HTTP client; a client with a 30 second timeout is used if nil.
+//
+// Returns:
+//   - *HTTPVaultSecretProvider: The provider.
+func NewHTTPVaultSecretProvider(url string, token string, httpClient *http.Client) *HTTPVaultSecretProvider {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: secretProviderTimeout}
+	}
+	return &HTTPVaultSecretProvider{URL: url, Token: token, HTTPClient: httpClient}
+}
+
+// Name returns the name of the provider.
+//
+// Returns:
+//   - string: The name of the provider.
+func (provider *HTTPVaultSecretProvider) Name() string {
+	return "HTTP vault " + provider.URL
+}
+
+// GetSecrets sends a GET request to the vault and decodes the JSON object in the response.
+// If DataPath is set, the secrets are read from the nested object at that dot separated path
+// (e.g. "data.data" for HashiCorp Vault KV v2). Non-string values are passed on as JSON.
+//
+// Parameters:
+//   - ctx: The context for the request.
+//
+// Returns:
+//   - map[string]string: The secrets.
+//   - error: An error if the request fails or the response is not a JSON object.
+func (provider *HTTPVaultSecretProvider) GetSecrets(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, provider.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating GET request: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if provider.Token != "" {
+		if provider.TokenHeader != "" {
+			req.Header.Set(provider.TokenHeader, provider.Token)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+provider.Token)
+		}
+	}
+
+	httpClient := provider.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making GET request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received non-200 status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %v", err)
+	}
+
+	var data map[string]interface{}
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON response: %v", err)
+	}
+
+	// Walk to the nested secrets object
+	if provider.DataPath != "" {
+		for _, part := range strings.Split(provider.DataPath, ".") {
+			nested, ok := data[part].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("response has no object at %q", provider.DataPath)
+			}
+			data = nested
+		}
+	}
+
+	secrets := map[string]string{}
+	for key, value := range data {
+		switch typed := value.(type) {
+		case string:
+			secrets[key] = typed
+		default:
+			encoded, err := json.Marshal(typed)
+			if err != nil {
+				return nil, err
+			}
+			secrets[key] = string(encoded)
+		}
+	}
+
+	return secrets, nil
+}
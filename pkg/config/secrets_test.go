@@ -0,0 +1,124 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestDirectorySecretProvider tests reading secrets from a mounted secret directory
+func TestDirectorySecretProvider(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"LLM_API_KEY":   "llm-key\n",
+		"github-token":  "gh-token",
+		".hidden":       "ignored",
+		"unrelated_key": "ignored",
+	}
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := Config{}
+	err := ApplySecretProviders(context.Background(), &config, NewDirectorySecretProvider(dir))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedConfig := Config{LLM_API_KEY: "llm-key", GITHUB_TOKEN: "gh-token"}
+	if !reflect.DeepEqual(config, expectedConfig) {
+		t.Errorf("Expected config %+v, got %+v", expectedConfig, config)
+	}
+}
+
+// TestParseDotEnv tests the parseDotEnv function
+func TestParseDotEnv(t *testing.T) {
+	content := `# secrets
+export LLM_API_KEY=plain # comment
+GITHUB_TOKEN="quoted \"token\"\n"
+WORKFLOW_API_KEY='single # not a comment'
+EMPTY=
+`
+	values, err := parseDotEnv([]byte(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"LLM_API_KEY":      "plain",
+		"GITHUB_TOKEN":     "quoted \"token\"\n",
+		"WORKFLOW_API_KEY": "single # not a comment",
+		"EMPTY":            "",
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected values %v, got %v", expected, values)
+	}
+
+	_, err = parseDotEnv([]byte("VALID=1\nINVALID\n"))
+	if err == nil || err.Error() != `line 2: expected KEY=VALUE, got "INVALID"` {
+		t.Errorf("Expected line number in error, got %v", err)
+	}
+}
+
+// TestHTTPVaultSecretProvider tests reading secrets from a fake HTTP vault
+func TestHTTPVaultSecretProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "vault-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": {"data": {"FLOWKIT_API_KEY": "flowkit-key", "QDRANT_PORT": 6333, "PRIVATE_WORKFLOWS_FOLDERS": ["a", "b"]}}}`))
+	}))
+	defer server.Close()
+
+	provider := NewHTTPVaultSecretProvider(server.URL, "vault-token", server.Client())
+	provider.TokenHeader = "X-Vault-Token"
+	provider.DataPath = "data.data"
+
+	config := Config{}
+	err := ApplySecretProviders(context.Background(), &config, provider)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedConfig := Config{FLOWKIT_API_KEY: "flowkit-key", QDRANT_PORT: 6333, PRIVATE_WORKFLOWS_FOLDERS: []string{"a", "b"}}
+	if !reflect.DeepEqual(config, expectedConfig) {
+		t.Errorf("Expected config %+v, got %+v", expectedConfig, config)
+	}
+
+	// Wrong token
+	provider.Token = "wrong"
+	err = ApplySecretProviders(context.Background(), &config, provider)
+	if err == nil {
+		t.Errorf("Expected error for rejected request")
+	}
+}
@@ -23,31 +23,59 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"reflect"
 	"strings"
+	"time"
 )
 
 // Config contains all the configuration settings for the Aali service.
+// Fields tagged with `secret:"true"` are redacted by String, RedactedJSON and RedactedYAML.
+// Fields tagged with `validate:"<rule>"` are checked by ValidateConfig when they are set.
+// Fields tagged with `alias:"<KEY>,..."` also accept the deprecated legacy keys (see CanonicalKey).
 type Config struct {
 
 	// Logging
 	///////////
-	LOG_LEVEL string `yaml:"LOG_LEVEL" json:"LOGLEVEL"`
+	LOG_LEVEL           string            `yaml:"LOG_LEVEL" json:"LOGLEVEL" validate:"oneof=debug info warn error fatal"`
+	LOG_LEVEL_OVERRIDES map[string]string `yaml:"LOG_LEVEL_OVERRIDES" json:"LOGLEVELOVERRIDES"`
+	CONSOLE_LOG_LEVEL   string            `yaml:"CONSOLE_LOG_LEVEL" json:"CONSOLELOGLEVEL" validate:"oneof=debug info warn error fatal"`
 	// Local Logs
-	LOCAL_LOGS          bool   `yaml:"LOCAL_LOGS" json:"LOCALLOGS"`
-	LOCAL_LOGS_LOCATION string `yaml:"LOCAL_LOGS_LOCATION" json:"LOCALLOGSLOCATION"`
+	LOCAL_LOGS                bool   `yaml:"LOCAL_LOGS" json:"LOCALLOGS"`
+	LOCAL_LOGS_LOCATION       string `yaml:"LOCAL_LOGS_LOCATION" json:"LOCALLOGSLOCATION"`
+	LOCAL_LOGS_LEVEL          string `yaml:"LOCAL_LOGS_LEVEL" json:"LOCALLOGSLEVEL" validate:"oneof=debug info warn error fatal"`
+	LOCAL_LOGS_MAX_SIZE_MB    int    `yaml:"LOCAL_LOGS_MAX_SIZE_MB" json:"LOCALLOGSMAXSIZEMB" validate:"min=0"`
+	LOCAL_LOGS_ROTATION_HOURS int    `yaml:"LOCAL_LOGS_ROTATION_HOURS" json:"LOCALLOGSROTATIONHOURS" validate:"min=0"`
+	LOCAL_LOGS_MAX_AGE_DAYS   int    `yaml:"LOCAL_LOGS_MAX_AGE_DAYS" json:"LOCALLOGSMAXAGEDAYS" validate:"min=0"`
+	LOCAL_LOGS_MAX_BACKUPS    int    `yaml:"LOCAL_LOGS_MAX_BACKUPS" json:"LOCALLOGSMAXBACKUPS" validate:"min=0"`
+	LOCAL_LOGS_COMPRESS       bool   `yaml:"LOCAL_LOGS_COMPRESS" json:"LOCALLOGSCOMPRESS"`
 	// Datadog Logs
 	DATADOG_LOGS        bool   `yaml:"DATADOG_LOGS" json:"DATADOGLOGS"`
 	STAGE               string `yaml:"STAGE" json:"STAGE"`
 	VERSION             string `yaml:"VERSION" json:"VERSION"`
 	SERVICE_NAME        string `yaml:"SERVICE_NAME" json:"SERVICENAME"`
 	ERROR_FILE_LOCATION string `yaml:"ERROR_FILE_LOCATION" json:"ERRORFILELOCATION"`
-	LOGGING_URL         string `yaml:"LOGGING_URL" json:"LOGGINGURL"`
-	LOGGING_API_KEY     string `yaml:"LOGGING_API_KEY" json:"LOGGINGAPIKEY"`
+	LOGGING_URL         string `yaml:"LOGGING_URL" json:"LOGGINGURL" validate:"url"`
+	LOGGING_API_KEY     string `yaml:"LOGGING_API_KEY" json:"LOGGINGAPIKEY" secret:"true"`
 	DATADOG_SOURCE      string `yaml:"DATADOG_SOURCE" json:"DATADOGSOURCE"`
+	DATADOG_LOG_LEVEL   string `yaml:"DATADOG_LOG_LEVEL" json:"DATADOGLOGLEVEL" validate:"oneof=debug info warn error fatal"`
 	// Datadog Metrics
-	DATADOG_METRICS bool   `yaml:"DATADOG_METRICS" json:"DATADOGMETRICS"`
-	METRICS_URL     string `yaml:"METRICS_URL" json:"METRICSURL"`
+	DATADOG_METRICS          bool   `yaml:"DATADOG_METRICS" json:"DATADOGMETRICS"`
+	METRICS_URL              string `yaml:"METRICS_URL" json:"METRICSURL" validate:"url"`
+	METRICS_INTERVAL_SECONDS int    `yaml:"METRICS_INTERVAL_SECONDS" json:"METRICSINTERVALSECONDS" validate:"min=0"`
+	// Datadog Shipping
+	DATADOG_QUEUE_SIZE             int    `yaml:"DATADOG_QUEUE_SIZE" json:"DATADOGQUEUESIZE" validate:"min=0"`
+	DATADOG_BATCH_SIZE             int    `yaml:"DATADOG_BATCH_SIZE" json:"DATADOGBATCHSIZE" validate:"min=0,max=1000"`
+	DATADOG_FLUSH_INTERVAL_SECONDS int    `yaml:"DATADOG_FLUSH_INTERVAL_SECONDS" json:"DATADOGFLUSHINTERVALSECONDS" validate:"min=0"`
+	DATADOG_MAX_RETRIES            int    `yaml:"DATADOG_MAX_RETRIES" json:"DATADOGMAXRETRIES" validate:"min=0"`
+	DATADOG_SPOOL_DIRECTORY        string `yaml:"DATADOG_SPOOL_DIRECTORY" json:"DATADOGSPOOLDIRECTORY"`
+	DATADOG_SPOOL_MAX_MB           int    `yaml:"DATADOG_SPOOL_MAX_MB" json:"DATADOGSPOOLMAXMB" validate:"min=0"`
+	// OpenTelemetry Logs
+	OTLP_LOGS      bool   `yaml:"OTLP_LOGS" json:"OTLPLOGS"`
+	OTLP_LOGS_URL  string `yaml:"OTLP_LOGS_URL" json:"OTLPLOGSURL" validate:"url"`
+	OTLP_LOG_LEVEL string `yaml:"OTLP_LOG_LEVEL" json:"OTLPLOGLEVEL" validate:"oneof=debug info warn error fatal"`
 
 	// SSL Settings
 	/////////////////
@@ -60,76 +88,96 @@ type Config struct {
 	EXTRACT_CONFIG_FROM_AZURE_KEY_VAULT bool   `yaml:"EXTRACT_CONFIG_FROM_AZURE_KEY_VAULT" json:"EXTRACTCONFIGFROMAZUREKEYVAULT"`
 	AZURE_KEY_VAULT_NAME                string `yaml:"AZURE_KEY_VAULT_NAME" json:"AZUREKEYVAULTNAME"`
 	AZURE_MANAGED_IDENTITY_ID           string `yaml:"AZURE_MANAGED_IDENTITY_ID" json:"AZUREMANAGEDIDENTITYID"`
+	// Maps Key Vault secret names to config keys, e.g. llm-api-key: LLM_API_KEY
+	AZURE_KEY_VAULT_SECRET_NAMES    map[string]string `yaml:"AZURE_KEY_VAULT_SECRET_NAMES" json:"AZUREKEYVAULTSECRETNAMES"`
+	AZURE_KEY_VAULT_TIMEOUT_SECONDS int               `yaml:"AZURE_KEY_VAULT_TIMEOUT_SECONDS" json:"AZUREKEYVAULTTIMEOUTSECONDS" validate:"min=0"`
+	AZURE_KEY_VAULT_CONCURRENCY     int               `yaml:"AZURE_KEY_VAULT_CONCURRENCY" json:"AZUREKEYVAULTCONCURRENCY" validate:"min=0"`
+	AZURE_KEY_VAULT_CACHE_TTL       int               `yaml:"AZURE_KEY_VAULT_CACHE_TTL" json:"AZUREKEYVAULTCACHETTL" validate:"min=0"` // Seconds; 0 disables the cache
+
+	// Secret Providers
+	////////////////////
+	SECRETS_DIRECTORY        string `yaml:"SECRETS_DIRECTORY" json:"SECRETSDIRECTORY"`    // Directory with one file per secret (Kubernetes/Docker secrets)
+	SECRETS_DOTENV_FILE      string `yaml:"SECRETS_DOTENV_FILE" json:"SECRETSDOTENVFILE"` // .env file with secrets
+	SECRETS_HTTP_VAULT_URL   string `yaml:"SECRETS_HTTP_VAULT_URL" json:"SECRETSHTTPVAULTURL" validate:"url"`
+	SECRETS_HTTP_VAULT_TOKEN string `yaml:"SECRETS_HTTP_VAULT_TOKEN" json:"SECRETSHTTPVAULTTOKEN" secret:"true"`
+
+	// Feature Flags
+	/////////////////
+	FEATURE_FLAGS map[string]FeatureFlag `yaml:"FEATURE_FLAGS" json:"FEATUREFLAGS"`
 
 	// Aali Agent
 	///////////////
 	PRODUCTION_MODE            bool   `yaml:"PRODUCTION_MODE" json:"PRODUCTIONMODE"` // If true, the agent error messages will be generic
-	AGENT_PORT                 string `yaml:"AGENT_PORT" json:"AGENTPORT"`
-	WORKFLOW_API_KEY           string `yaml:"WORKFLOW_API_KEY" json:"WORKFLOWAPIKEY"`
+	AGENT_PORT                 string `yaml:"AGENT_PORT" json:"AGENTPORT" validate:"port"`
+	WORKFLOW_API_KEY           string `yaml:"WORKFLOW_API_KEY" json:"WORKFLOWAPIKEY" secret:"true"`
 	WORKFLOW_STORE_PATH        string `yaml:"WORKFLOW_STORE_PATH" json:"WORKFLOWSTOREPATH"`
 	BINARY_STORE_PATH          string `yaml:"BINARY_STORE_PATH" json:"BINARYSTOREPATH"`
-	NUMBER_OF_WORKFLOW_WORKERS int    `yaml:"NUMBER_OF_WORKFLOW_WORKERS" json:"NUMBEROFWORKFLOWWORKERS"`
+	NUMBER_OF_WORKFLOW_WORKERS int    `yaml:"NUMBER_OF_WORKFLOW_WORKERS" json:"NUMBEROFWORKFLOWWORKERS" validate:"min=1"`
 	// External Function Endpoints
-	EXTERNALFUNCTIONS_ENDPOINT string `yaml:"EXTERNALFUNCTIONS_ENDPOINT" json:"EXTERNALFUNCTIONSENDPOINT"`
-	FLOWKIT_PYTHON_ENDPOINT    string `yaml:"FLOWKIT_PYTHON_ENDPOINT" json:"FLOWKITPYTHONENDPOINT"`
-	FLOWKIT_PYTHON_API_KEY     string `yaml:"FLOWKIT_PYTHON_API_KEY" json:"FLOWKITPYTHONAPIKEY"`
+	EXTERNALFUNCTIONS_ENDPOINT string `yaml:"EXTERNALFUNCTIONS_ENDPOINT" json:"EXTERNALFUNCTIONSENDPOINT" validate:"endpoint"`
+	FLOWKIT_PYTHON_ENDPOINT    string `yaml:"FLOWKIT_PYTHON_ENDPOINT" json:"FLOWKITPYTHONENDPOINT" validate:"endpoint"`
+	FLOWKIT_PYTHON_API_KEY     string `yaml:"FLOWKIT_PYTHON_API_KEY" json:"FLOWKITPYTHONAPIKEY" secret:"true"`
 	// Authentication & Authorization
 	ENABLE_AUTH                   bool   `yaml:"ENABLE_AUTH" json:"ENABLEAUTH"` // If true, the agent will require authentication/authorization for workflows
-	AZURE_AD_AUTHENTICATION_URL   string `yaml:"AZURE_AD_AUTHENTICATION_URL" json:"AZUREADAUTHENTICATIONURL"`
-	ANSYS_AUTHORIZATION_URL       string `yaml:"ANSYS_AUTHORIZATION_URL" json:"ANSYSAUTHORIZATIONURL"`
-	ANSYS_AUTHORIZATION_CRYPT_KEY string `yaml:"ANSYS_AUTHORIZATION_CRYPT_KEY" json:"ANSYSAUTHORIZATIONCRYPTKEY"`
-	ANSYS_DISCO_CRYPT_PRIVAT_KEY  string `yaml:"ANSYS_DISCO_CRYPT_PRIVAT_KEY" json:"ANSYSDISCOCRYPTPRIVATKEY"`
-	ANSYS_DISOC_SIGN_PUBLIC_KEY   string `yaml:"ANSYS_DISOC_SIGN_PUBLIC_KEY" json:"ANSYSDISOCSIGNPUBLICKEY"`
+	AZURE_AD_AUTHENTICATION_URL   string `yaml:"AZURE_AD_AUTHENTICATION_URL" json:"AZUREADAUTHENTICATIONURL" validate:"url"`
+	ANSYS_AUTHORIZATION_URL       string `yaml:"ANSYS_AUTHORIZATION_URL" json:"ANSYSAUTHORIZATIONURL" validate:"url"`
+	ANSYS_AUTHORIZATION_CRYPT_KEY string `yaml:"ANSYS_AUTHORIZATION_CRYPT_KEY" json:"ANSYSAUTHORIZATIONCRYPTKEY" secret:"true"`
+	ANSYS_DISCO_CRYPT_PRIVATE_KEY string `yaml:"ANSYS_DISCO_CRYPT_PRIVATE_KEY" json:"ANSYSDISCOCRYPTPRIVATEKEY" secret:"true" alias:"ANSYS_DISCO_CRYPT_PRIVAT_KEY"`
+	ANSYS_DISCO_SIGN_PUBLIC_KEY   string `yaml:"ANSYS_DISCO_SIGN_PUBLIC_KEY" json:"ANSYSDISCOSIGNPUBLICKEY" alias:"ANSYS_DISOC_SIGN_PUBLIC_KEY"`
+	// Deprecated: Use ANSYS_DISCO_CRYPT_PRIVATE_KEY. Copy of it set when the config is loaded; will be removed in the next release.
+	ANSYS_DISCO_CRYPT_PRIVAT_KEY string `yaml:"-" json:"-" secret:"true" deprecated:"ANSYS_DISCO_CRYPT_PRIVATE_KEY"`
+	// Deprecated: Use ANSYS_DISCO_SIGN_PUBLIC_KEY. Copy of it set when the config is loaded; will be removed in the next release.
+	ANSYS_DISOC_SIGN_PUBLIC_KEY string `yaml:"-" json:"-" deprecated:"ANSYS_DISCO_SIGN_PUBLIC_KEY"`
 	// Workflows
 	DISABLE_PUBLIC_WORKFLOWS  bool     `yaml:"DISABLE_PUBLIC_WORKFLOWS" json:"DISABLEPUBLICWORKFLOWS"`
 	LOAD_PRIVATE_WORKFLOWS    bool     `yaml:"LOAD_PRIVATE_WORKFLOWS" json:"LOADPRIVATEWORKFLOWS"`
 	GITHUB_USER               string   `yaml:"GITHUB_USER" json:"GITHUBUSER"`
-	GITHUB_TOKEN              string   `yaml:"GITHUB_TOKEN" json:"GITHUBTOKEN"`
+	GITHUB_TOKEN              string   `yaml:"GITHUB_TOKEN" json:"GITHUBTOKEN" secret:"true"`
 	PRIVATE_WORKFLOWS_FOLDERS []string `yaml:"PRIVATE_WORKFLOWS_FOLDERS" json:"PRIVATEWORKFLOWSFOLDERS"`
 	// Exec Settings
-	EXEC_ENDPOINT                        string `yaml:"EXEC_ENDPOINT" json:"EXECENDPOINT"`
-	EXEC_AGENT_API_KEY                   string `yaml:"EXEC_AGENT_API_KEY" json:"EXECAGENTAPIKEY"`
+	EXEC_ENDPOINT                        string `yaml:"EXEC_ENDPOINT" json:"EXECENDPOINT" validate:"endpoint"`
+	EXEC_AGENT_API_KEY                   string `yaml:"EXEC_AGENT_API_KEY" json:"EXECAGENTAPIKEY" secret:"true"`
 	MONGO_DB_FOR_MULTI_AGENT             bool   `yaml:"MONGO_DB_FOR_MULTI_AGENT" json:"MONGODBFORMULTIAGENT"`
-	MONGO_DB_ENDPOINT                    string `yaml:"MONGO_DB_ENDPOINT" json:"MONGODBENDPOINT"`
-	MILLISECONDS_MONGODB_UPDATE_INTERVAL int    `yaml:"MILLISECONDS_MONGODB_UPDATE_INTERVAL" json:"MILLISECONDSMONGODBUPDATEINTERVAL"`
+	MONGO_DB_ENDPOINT                    string `yaml:"MONGO_DB_ENDPOINT" json:"MONGODBENDPOINT" validate:"endpoint"`
+	MILLISECONDS_MONGODB_UPDATE_INTERVAL int    `yaml:"MILLISECONDS_MONGODB_UPDATE_INTERVAL" json:"MILLISECONDSMONGODBUPDATEINTERVAL" validate:"min=0"`
 	EXEC_FILE_STORE_PATH                 string `yaml:"EXEC_FILE_STORE_PATH" json:"EXECFILESTOREPATH"`
 	// DB Connection
-	KVDB_ENDPOINT string `yaml:"KVDB_ENDPOINT" json:"KVDBENDPOINT"`
+	KVDB_ENDPOINT string `yaml:"KVDB_ENDPOINT" json:"KVDBENDPOINT" validate:"endpoint"`
 
 	// Aali Flowkit
 	/////////////////
-	EXTERNALFUNCTIONS_GRPC_PORT string `yaml:"EXTERNALFUNCTIONS_GRPC_PORT" json:"EXTERNALFUNCTIONSGRPCPORT"`
-	FLOWKIT_API_KEY             string `yaml:"FLOWKIT_API_KEY" json:"FLOWKITAPIKEY"`
+	EXTERNALFUNCTIONS_GRPC_PORT string `yaml:"EXTERNALFUNCTIONS_GRPC_PORT" json:"EXTERNALFUNCTIONSGRPCPORT" validate:"port"`
+	FLOWKIT_API_KEY             string `yaml:"FLOWKIT_API_KEY" json:"FLOWKITAPIKEY" secret:"true"`
 	// Aali Modules
-	LLM_HANDLER_ENDPOINT  string `yaml:"LLM_HANDLER_ENDPOINT" json:"LLMHANDLERENDPOINT"`
-	KNOWLEDGE_DB_ENDPOINT string `yaml:"KNOWLEDGE_DB_ENDPOINT" json:"KNOWLEDGEDBENDPOINT"`
+	LLM_HANDLER_ENDPOINT  string `yaml:"LLM_HANDLER_ENDPOINT" json:"LLMHANDLERENDPOINT" validate:"endpoint"`
+	KNOWLEDGE_DB_ENDPOINT string `yaml:"KNOWLEDGE_DB_ENDPOINT" json:"KNOWLEDGEDBENDPOINT" validate:"endpoint"`
 
 	// Aali LLM
 	/////////////
-	WEBSERVER_PORT         string `yaml:"WEBSERVER_PORT" json:"WEBSERVERPORT"`
+	WEBSERVER_PORT         string `yaml:"WEBSERVER_PORT" json:"WEBSERVERPORT" validate:"port"`
 	MODELS_CONFIG_LOCATION string `yaml:"MODELS_CONFIG_LOCATION" json:"MODELSCONFIGLOCATION"`
-	LLM_API_KEY            string `yaml:"LLM_API_KEY" json:"LLMAPIKEY"`
+	LLM_API_KEY            string `yaml:"LLM_API_KEY" json:"LLMAPIKEY" secret:"true"`
 
 	// qdrant config
 	QDRANT_HOST string `yaml:"QDRANT_HOST" json:"QDRANTHOST"`
-	QDRANT_PORT int    `yaml:"QDRANT_PORT" json:"QDRANTPORT"`
+	QDRANT_PORT int    `yaml:"QDRANT_PORT" json:"QDRANTPORT" validate:"port"`
 
 	// graph db config
-	GRAPHDB_ADDRESS string `yaml:"GRAPHDB_ADDRESS" json:"GRAPHDBADDRESS"`
+	GRAPHDB_ADDRESS string `yaml:"GRAPHDB_ADDRESS" json:"GRAPHDBADDRESS" validate:"endpoint"`
 
 	// Aali Exec
 	//////////////
 	EXEC_ID             string `yaml:"EXEC_ID" json:"EXECID"`
-	WEBSERVER_PORT_EXEC string `yaml:"WEBSERVER_PORT_EXEC" json:"WEBSERVERPORTEXEC"`
-	EXEC_API_KEY        string `yaml:"EXEC_API_KEY" json:"EXECAPIKEY"`
+	WEBSERVER_PORT_EXEC string `yaml:"WEBSERVER_PORT_EXEC" json:"WEBSERVERPORTEXEC" validate:"port"`
+	EXEC_API_KEY        string `yaml:"EXEC_API_KEY" json:"EXECAPIKEY" secret:"true"`
 	// Python executable name
 	PYTHON_EXECUTABLE string `yaml:"PYTHON_EXECUTABLE" json:"PYTHONEXECUTABLE"`
 	BASH_EXECUTABLE   string `yaml:"BASH_EXECUTABLE" json:"BASHEXECUTABLE"`
 	// File transfer
 	WATCH_FOLDER_PATH              string `yaml:"WATCH_FOLDER_PATH" json:"WATCHFOLDERPATH"`
-	MILLISECONDS_SINCE_LAST_CHANGE int    `yaml:"MILLISECONDS_SINCE_LAST_CHANGE" json:"MILLISECONDSSINCELASTCHANGE"`
+	MILLISECONDS_SINCE_LAST_CHANGE int    `yaml:"MILLISECONDS_SINCE_LAST_CHANGE" json:"MILLISECONDSSINCELASTCHANGE" validate:"min=0"`
 	// Agent connection
-	AGENT_ENDPOINT string `yaml:"AGENT_ENDPOINT" json:"AGENTENDPOINT"`
+	AGENT_ENDPOINT string `yaml:"AGENT_ENDPOINT" json:"AGENTENDPOINT" validate:"endpoint"`
 
 	// Workflow Store
 	WORKFLOW_CONFIG_VARIABLES map[string]string `yaml:"WORKFLOW_CONFIG_VARIABLES" json:"WORKFLOWCONFIGVARIABLES"`
@@ -142,28 +190,282 @@ type Config struct {
 	KVDB_IN_MEMORY bool   `yaml:"KVDB_IN_MEMORY" json:"KVDBINMEMORY"`
 }
 
-// Initialize conifg dict
+// FeatureFlag configures a feature that is enabled for all, for targeted users and workflows, or for a percentage of them.
+type FeatureFlag struct {
+	Enabled    bool     `yaml:"enabled" json:"enabled"`       // If false, the feature is disabled for everyone
+	Users      []string `yaml:"users" json:"users"`           // User mails the feature is enabled for
+	Workflows  []string `yaml:"workflows" json:"workflows"`   // Workflow IDs the feature is enabled for
+	Percentage int      `yaml:"percentage" json:"percentage"` // Percentage of the other users or workflows the feature is enabled for
+}
+
+// FeatureTarget identifies the user and workflow a feature flag is evaluated for.
+type FeatureTarget struct {
+	UserMail   string
+	WorkflowID string
+}
+
+// LoadOptions defines the sources and requirements used by Load.
+type LoadOptions struct {
+	// FilePath is the path to the config file; defaults to AALI_CONFIG_PATH or config.yaml.
+	FilePath string
+	// OverlayFiles are merged over the config file in order; defaults to the files in AALI_CONFIG_OVERLAYS
+	// or, if neither is set, to the overlay of the stage next to the config file (e.g. config.production.yaml).
+	OverlayFiles []string
+	// Stage selects the stage overlay; defaults to the <EnvironmentPrefix>STAGE environment variable or STAGE in the config file.
+	Stage string
+	// Args are the command line arguments (without the program name) to overlay on the config.
+	Args []string
+	// Service is the name of a service profile whose required properties and default values are applied.
+	Service string
+	// RequiredProperties lists the config fields that must be set, in addition to those of the service profile.
+	RequiredProperties []string
+	// OptionalDefaultValues maps config fields to the values used when they are not set; they override the service profile defaults.
+	OptionalDefaultValues map[string]interface{}
+	// EnvironmentPrefix is the prefix of the environment variables; defaults to EnvironmentVariablePrefix.
+	EnvironmentPrefix string
+	// IgnoreEnvironment disables the environment variable overlay.
+	IgnoreEnvironment bool
+	// IgnoreAzureKeyVault disables retrieving values from Azure Key Vault.
+	IgnoreAzureKeyVault bool
+	// EncryptionKey decrypts ENC[AES256_GCM,...] values in the config files; defaults to the key in AALI_CONFIG_KEY or AALI_CONFIG_KEY_FILE.
+	EncryptionKey []byte
+	// SecretProviders are applied after the providers configured in the config itself.
+	SecretProviders []SecretProvider
+	// KeyVaultClient replaces the Azure Key Vault client created from AZURE_KEY_VAULT_NAME and AZURE_MANAGED_IDENTITY_ID.
+	KeyVaultClient KeyVaultClient
+	// StrictValidation makes violations of the `validate` struct tag rules and the cross-field rules errors.
+	// Otherwise they are logged as warnings and only missing required properties are errors.
+	StrictValidation bool
+}
+
+// SecretProvider retrieves secrets that are applied to the config by their YAML key (e.g. LLM_API_KEY).
+type SecretProvider interface {
+	// Name returns a short description of the provider used in error messages.
+	Name() string
+	// GetSecrets returns the secret values by key.
+	GetSecrets(ctx context.Context) (map[string]string, error)
+}
+
+// KeyVaultClient reads secrets from a key vault; it hides the Azure SDK from the config loading logic.
+type KeyVaultClient interface {
+	// ListSecretNames returns the names of all enabled secrets.
+	ListSecretNames(ctx context.Context) ([]string, error)
+	// GetSecret returns the current value of a secret.
+	GetSecret(ctx context.Context, name string) (string, error)
+}
+
+// KeyVaultOptions controls how secrets are read from a key vault.
+type KeyVaultOptions struct {
+	// SecretNames maps secret names to config keys; secrets without mapping match the config key with '-' for '_'
+	// or the JSON tag of a field, both case-insensitively.
+	SecretNames map[string]string
+	// Timeout limits the time spent listing and fetching secrets; defaults to 30 seconds.
+	Timeout time.Duration
+	// Concurrency is the number of secrets fetched in parallel; defaults to 8.
+	Concurrency int
+	// CacheKey identifies the vault in the process wide secret cache, e.g. its name.
+	CacheKey string
+	// CacheTTL is the time fetched secrets are reused for the same CacheKey; 0 disables the cache.
+	CacheTTL time.Duration
+}
+
+// DirectorySecretProvider reads one secret per file from a directory (Kubernetes/Docker secrets).
+type DirectorySecretProvider struct {
+	Path string
+}
+
+// DotEnvSecretProvider reads secrets from a .env file.
+type DotEnvSecretProvider struct {
+	Path string
+}
+
+// HTTPVaultSecretProvider reads secrets from a JSON object returned by an HTTP vault API.
+type HTTPVaultSecretProvider struct {
+	URL         string
+	Token       string
+	TokenHeader string // Header carrying the raw token; if empty the token is sent as "Authorization: Bearer <token>"
+	DataPath    string // Dot separated path to the secrets object in the response, e.g. "data.data"
+	HTTPClient  *http.Client
+}
+
+// ConfigChange describes a config field whose value changed during a reload.
+type ConfigChange struct {
+	Field    string
+	OldValue interface{}
+	NewValue interface{}
+}
+
+// ChangeHandler is called after a config reload with the old config, the new config and the changed fields.
+type ChangeHandler func(oldConfig *Config, newConfig *Config, changes []ConfigChange)
+
+// ValidationError contains all violations found while validating a config.
+type ValidationError struct {
+	Service    string // Name of the service profile the config was validated for, if any
+	Violations []Violation
+}
+
+// Violation describes a single config field that failed a validation rule.
+type Violation struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
+// ParseError describes an error at a position in a config file.
+type ParseError struct {
+	File    string
+	Line    int // Line of the error starting at 1; 0 if unknown
+	Column  int // Column of the error starting at 1; 0 if unknown
+	Message string
+}
+
+// ServiceProfile declares the config fields used by a service, the required ones and their default values.
+type ServiceProfile struct {
+	Name                  string
+	Fields                []string
+	RequiredProperties    []string
+	OptionalDefaultValues map[string]interface{}
+}
+
+// ScopedConfig is a read-only view of a config restricted to the fields of a service profile.
+type ScopedConfig struct {
+	service string
+	fields  map[string]bool
+	config  Config
+}
+
+// Source identifies where the value of a config field was loaded from.
+type Source string
+
+// Origin describes where the effective value of a config field comes from.
+type Origin struct {
+	Source   Source
+	Location string // The file, environment variable, flag, secret or profile that set the value, if any
+}
+
+// Provenance maps config field names to the origin of their effective value.
+type Provenance map[string]Origin
+
+// GlobalConfig is the configuration loaded by InitConfig at startup.
+// It is not updated by Reload or WatchConfigFile; use Current or Subscribe to see reloaded values.
 var GlobalConfig *Config
 
 // flagStringSlice is a custom flag type for string slices.
-type flagStringSlice []string
+// The first use of the flag replaces the current value of the slice, e.g. a default;
+// repeating the flag appends to the values given before.
+type flagStringSlice struct {
+	values *[]string
+	set    bool
+}
 
 // String returns a string representation of the flagStringSlice.
 //
 // Returns:
 //   - string: The string representation of the flagStringSlice.
 func (fss *flagStringSlice) String() string {
-	return fmt.Sprintf("%v", *fss)
+	if fss == nil || fss.values == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", *fss.values)
 }
 
-// Set sets the value of the flagStringSlice.
+// Set replaces the value of the flagStringSlice on the first call and appends to it on later calls.
 //
 // Parameters:
-//   - value: The value to set.
+//   - value: The comma separated values to set.
 //
 // Returns:
 //   - error: An error if there was an issue setting the value.
 func (fss *flagStringSlice) Set(value string) error {
-	*fss = strings.Split(value, ",")
+	if !fss.set {
+		*fss.values = nil
+		fss.set = true
+	}
+	*fss.values = append(*fss.values, strings.Split(value, ",")...)
 	return nil
 }
+
+// flagStringMap is a custom flag type for string maps given as key=value pairs.
+type flagStringMap map[string]string
+
+// String returns a string representation of the flagStringMap.
+//
+// Returns:
+//   - string: The string representation of the flagStringMap.
+func (fsm *flagStringMap) String() string {
+	if fsm == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", map[string]string(*fsm))
+}
+
+// Set adds the key=value pairs of value to the flagStringMap.
+//
+// Parameters:
+//   - value: A comma separated list of key=value pairs, or a JSON object.
+//
+// Returns:
+//   - error: An error if a pair has no '='.
+func (fsm *flagStringMap) Set(value string) error {
+	parsed, err := parseStringMap(value)
+	if err != nil {
+		return err
+	}
+	if *fsm == nil {
+		*fsm = flagStringMap{}
+	}
+	for key, val := range parsed {
+		(*fsm)[key] = val
+	}
+	return nil
+}
+
+// keyVaultCacheEntry holds the secrets fetched from a key vault.
+type keyVaultCacheEntry struct {
+	secrets map[string]string // Values by secret name
+	fetched time.Time
+}
+
+// configFiles holds the merged values of layered config files.
+type configFiles struct {
+	values  map[string]interface{} // Merged values by YAML key
+	origins map[string]string      // File that last set each YAML key
+	files   []string               // All files that were read, in merge order
+	// Position of each YAML key in the file that last set it
+	positions map[string]keyPosition
+	// Warnings for deprecated and unknown keys
+	warnings []string
+	// Unknown keys; reported as warnings unless they are referenced by another value
+	unknownKeys []unknownKey
+	// Violations for unresolved references and reference cycles
+	violations []Violation
+}
+
+// unknownKey is a top level key of a config file that is not a config field.
+type unknownKey struct {
+	key     string // The key
+	warning string // The warning reported if the key is not referenced
+}
+
+// keyPosition is the position of a key in a config file.
+type keyPosition struct {
+	line   int
+	column int
+}
+
+// interpolation resolves references in the values of layered config files.
+type interpolation struct {
+	values   map[string]interface{}  // Merged values by YAML key
+	fields   map[string]reflect.Type // Config field types by YAML key
+	resolved map[string]string       // Interpolated values of referenced fields
+	used     map[string]bool         // Keys referenced by other values
+	stack    []string                // Fields being resolved, used to detect cycles
+}
+
+// commandLineArguments holds the config values given as command line flags.
+type commandLineArguments struct {
+	config  Config   // Values of the given flags
+	names   []string // Names of the given config flags
+	fields  [][]int  // Index paths of the config fields of the given flags
+	persist bool     // True if -persist-config was given
+}
@@ -0,0 +1,471 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+////////////////////////////////
+// Validate Config
+////////////////////////////////
+
+// ValidateConfig checks for mandatory entries in the configuration and validates the config values.
+//
+// The following checks are performed and all violations are returned together:
+//   - every property in requiredProperties is set,
+//   - every set field satisfies the rule in its `validate` struct tag (see fieldRules),
+//   - the cross-field rules in crossFieldRules hold, e.g. the SSL certificate files exist when USE_SSL is set.
+//
+// Parameters:
+//   - config: The configuration object to validate.
+//   - requiredProperties: The list of required properties.
+//
+// Returns:
+//   - err: A *ValidationError listing all violations, or nil if the config is valid.
+func ValidateConfig(config Config, requiredProperties []string) (err error) {
+	return validateConfig(config, requiredProperties, nil)
+}
+
+// validateConfig validates a config like ValidateConfig, and additionally applies the field rules
+// to zero-valued fields that were set explicitly, e.g. NUMBER_OF_WORKFLOW_WORKERS: 0 in the config file.
+//
+// Parameters:
+//   - config: The configuration object to validate.
+//   - requiredProperties: The list of required properties.
+//   - explicit: The names of the fields set explicitly by a config source; may be nil.
+//
+// Returns:
+//   - err: A *ValidationError listing all violations, or nil if the config is valid.
+func validateConfig(config Config, requiredProperties []string, explicit map[string]bool) (err error) {
+	validationError := &ValidationError{}
+	validationError.Violations = append(validationError.Violations, missingProperties(config, requiredProperties)...)
+	validationError.Violations = append(validationError.Violations, checkFieldRules(config, explicit)...)
+	for _, rule := range crossFieldRules {
+		validationError.Violations = append(validationError.Violations, rule(config)...)
+	}
+
+	if len(validationError.Violations) == 0 {
+		return nil
+	}
+	return validationError
+}
+
+// validateRequiredProperties checks that each required property is set, without applying the validation rules.
+//
+// Parameters:
+//   - config: The configuration object to validate.
+//   - requiredProperties: The list of required properties.
+//
+// Returns:
+//   - err: A *ValidationError listing all missing properties, or nil if all are set.
+func validateRequiredProperties(config Config, requiredProperties []string) (err error) {
+	violations := missingProperties(config, requiredProperties)
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: violations}
+}
+
+// warnRuleViolations logs the rule violations of a validation error as warnings and returns an error
+// containing only the missing required properties. This keeps configs that were accepted before the
+// validation rules were introduced working; set LoadOptions.StrictValidation to reject them.
+//
+// Parameters:
+//   - err: The error returned by the validation.
+//
+// Returns:
+//   - error: A *ValidationError listing the missing required properties, nil if there are none,
+//     or err itself if it is not a *ValidationError.
+func warnRuleViolations(err error) error {
+	validationError, ok := err.(*ValidationError)
+	if !ok {
+		return err
+	}
+
+	required := &ValidationError{Service: validationError.Service}
+	for _, violation := range validationError.Violations {
+		if violation.Rule == "required" {
+			required.Violations = append(required.Violations, violation)
+			continue
+		}
+		log.Printf("Warning: %v", violation.Message)
+	}
+	if len(required.Violations) == 0 {
+		return nil
+	}
+	return required
+}
+
+// explicitlySetFields returns the names of the fields that were set by a config source.
+//
+// Parameters:
+//   - provenance: The provenance recorded while loading the config.
+//
+// Returns:
+//   - map[string]bool: The names of the explicitly set fields.
+func explicitlySetFields(provenance Provenance) map[string]bool {
+	explicit := map[string]bool{}
+	for name, origin := range provenance {
+		if origin.Source != SourceUnset {
+			explicit[name] = true
+		}
+	}
+	return explicit
+}
+
+// Error returns all violations, one per line, preceded by the service name if set.
+//
+// Returns:
+//   - string: The error message.
+func (validationError *ValidationError) Error() string {
+	messages := make([]string, 0, len(validationError.Violations)+1)
+	if validationError.Service != "" {
+		messages = append(messages, fmt.Sprintf("invalid configuration for the %v service:", validationError.Service))
+	}
+	for _, violation := range validationError.Violations {
+		messages = append(messages, violation.Message)
+	}
+	return strings.Join(messages, "\n")
+}
+
+// missingProperties returns a violation for every required property that is not set.
+//
+// Parameters:
+//   - config: The configuration object to validate.
+//   - requiredProperties: The list of required properties.
+//
+// Returns:
+//   - []Violation: The violations.
+func missingProperties(config Config, requiredProperties []string) []Violation {
+	configValue := reflect.ValueOf(config)
+
+	violations := []Violation{}
+	for _, property := range requiredProperties {
+		property = canonicalProperty(property, "required properties")
+		field := configValue.FieldByName(property)
+		if !field.IsValid() || field.IsZero() {
+			violations = append(violations, Violation{
+				Field:   property,
+				Rule:    "required",
+				Message: fmt.Sprintf("config.yaml is missing mandatory property '%v'", property),
+			})
+		}
+	}
+	return violations
+}
+
+////////////////////////////////
+// Field rules
+////////////////////////////////
+
+// fieldRules maps the rule names usable in `validate` struct tags to their check.
+// A check receives the field value and the rule argument (the part after '=') and returns
+// a description of the problem, or an empty string if the value is valid.
+var fieldRules = map[string]func(value reflect.Value, argument string) string{
+	"port":     checkPort,
+	"url":      checkURL,
+	"endpoint": checkEndpoint,
+	"oneof":    checkOneOf,
+	"min":      checkMin,
+	"max":      checkMax,
+}
+
+// checkFieldRules applies the `validate` struct tag rules to all fields that are set.
+// Zero-valued fields are skipped unless they are listed in explicit; empty strings are always skipped,
+// as an empty string in a config file means the value is not set.
+//
+// Parameters:
+//   - config: The configuration object to validate.
+//   - explicit: The names of the fields set explicitly by a config source; may be nil.
+//
+// Returns:
+//   - []Violation: The violations.
+func checkFieldRules(config Config, explicit map[string]bool) []Violation {
+	configValue := reflect.ValueOf(config)
+	configType := configValue.Type()
+
+	violations := []Violation{}
+	for i := 0; i < configType.NumField(); i++ {
+		tag := configType.Field(i).Tag.Get("validate")
+		field := configValue.Field(i)
+		if tag == "" || (field.IsZero() && (field.Kind() == reflect.String || !explicit[configType.Field(i).Name])) {
+			continue
+		}
+
+		for _, rule := range strings.Split(tag, ",") {
+			name, argument, _ := strings.Cut(rule, "=")
+			check, ok := fieldRules[name]
+			if !ok {
+				violations = append(violations, Violation{
+					Field:   configType.Field(i).Name,
+					Rule:    name,
+					Message: fmt.Sprintf("unknown validation rule '%v' for '%v'", name, configType.Field(i).Name),
+				})
+				continue
+			}
+			problem := check(field, argument)
+			if problem != "" {
+				violations = append(violations, Violation{
+					Field:   configType.Field(i).Name,
+					Rule:    name,
+					Message: fmt.Sprintf("config property '%v' %v", configType.Field(i).Name, problem),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// checkPort checks that a string or integer value is a valid TCP port.
+//
+// Parameters:
+//   - value: The field value.
+//   - argument: Unused.
+//
+// Returns:
+//   - string: The problem, or an empty string if the value is valid.
+func checkPort(value reflect.Value, argument string) string {
+	port := fmt.Sprint(value.Interface())
+	number, err := strconv.Atoi(port)
+	if err != nil || number < 1 || number > 65535 {
+		return fmt.Sprintf("must be a port between 1 and 65535, got %q", port)
+	}
+	return ""
+}
+
+// checkURL checks that a value is an absolute http or https URL.
+//
+// Parameters:
+//   - value: The field value.
+//   - argument: Unused.
+//
+// Returns:
+//   - string: The problem, or an empty string if the value is valid.
+func checkURL(value reflect.Value, argument string) string {
+	parsed, err := url.Parse(value.String())
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Sprintf("must be an http(s) URL, got %q", value.String())
+	}
+	return ""
+}
+
+// checkEndpoint checks that a value is either a URL with scheme and host, or a host:port address.
+//
+// Parameters:
+//   - value: The field value.
+//   - argument: Unused.
+//
+// Returns:
+//   - string: The problem, or an empty string if the value is valid.
+func checkEndpoint(value reflect.Value, argument string) string {
+	endpoint := value.String()
+	if strings.Contains(endpoint, "://") {
+		parsed, err := url.Parse(endpoint)
+		if err != nil || parsed.Host == "" {
+			return fmt.Sprintf("must be a URL or host:port address, got %q", endpoint)
+		}
+		return ""
+	}
+
+	_, port, err := net.SplitHostPort(endpoint)
+	if err != nil || checkPort(reflect.ValueOf(port), "") != "" {
+		return fmt.Sprintf("must be a URL or host:port address, got %q", endpoint)
+	}
+	return ""
+}
+
+// checkOneOf checks that a value is one of the space separated options in argument.
+// The comparison is case-insensitive, like the parsing of the log levels this rule is used for.
+//
+// Parameters:
+//   - value: The field value.
+//   - argument: The space separated list of allowed values.
+//
+// Returns:
+//   - string: The problem, or an empty string if the value is valid.
+func checkOneOf(value reflect.Value, argument string) string {
+	options := strings.Fields(argument)
+	for _, option := range options {
+		if strings.EqualFold(value.String(), option) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("must be one of [%v], got %q", strings.Join(options, ", "), value.String())
+}
+
+// checkMin checks that an integer value is at least argument.
+//
+// Parameters:
+//   - value: The field value.
+//   - argument: The minimum value.
+//
+// Returns:
+//   - string: The problem, or an empty string if the value is valid.
+func checkMin(value reflect.Value, argument string) string {
+	minimum, err := strconv.Atoi(argument)
+	if err != nil {
+		return fmt.Sprintf("has invalid validation rule 'min=%v'", argument)
+	}
+	if value.Int() < int64(minimum) {
+		return fmt.Sprintf("must be at least %d, got %d", minimum, value.Int())
+	}
+	return ""
+}
+
+// checkMax checks that an integer value is at most argument.
+//
+// Parameters:
+//   - value: The field value.
+//   - argument: The maximum value.
+//
+// Returns:
+//   - string: The problem, or an empty string if the value is valid.
+func checkMax(value reflect.Value, argument string) string {
+	maximum, err := strconv.Atoi(argument)
+	if err != nil {
+		return fmt.Sprintf("has invalid validation rule 'max=%v'", argument)
+	}
+	if value.Int() > int64(maximum) {
+		return fmt.Sprintf("must be at most %d, got %d", maximum, value.Int())
+	}
+	return ""
+}
+
+////////////////////////////////
+// Cross-field rules
+////////////////////////////////
+
+// crossFieldRules are rules spanning several config fields.
+var crossFieldRules = []func(config Config) []Violation{
+	requiredWhen("USE_SSL", "SSL_CERT_PUBLIC_KEY_FILE", "SSL_CERT_PRIVATE_KEY_FILE"),
+	filesExistWhen("USE_SSL", "SSL_CERT_PUBLIC_KEY_FILE", "SSL_CERT_PRIVATE_KEY_FILE"),
+	requiredWhen("DATADOG_LOGS", "LOGGING_URL", "LOGGING_API_KEY"),
+	requiredWhen("DATADOG_METRICS", "METRICS_URL", "LOGGING_API_KEY"),
+	requiredWhen("LOCAL_LOGS", "LOCAL_LOGS_LOCATION"),
+	requiredWhen("OTLP_LOGS", "OTLP_LOGS_URL"),
+	requiredWhen("EXTRACT_CONFIG_FROM_AZURE_KEY_VAULT", "AZURE_KEY_VAULT_NAME", "AZURE_MANAGED_IDENTITY_ID"),
+	requiredWhen("MONGO_DB_FOR_MULTI_AGENT", "MONGO_DB_ENDPOINT"),
+	validFeatureFlags,
+	validLogLevelOverrides,
+}
+
+// validLogLevelOverrides requires the levels of LOG_LEVEL_OVERRIDES to be valid log levels.
+//
+// Parameters:
+//   - config: The configuration object to validate.
+//
+// Returns:
+//   - []Violation: A violation for each invalid level.
+func validLogLevelOverrides(config Config) []Violation {
+	components := make([]string, 0, len(config.LOG_LEVEL_OVERRIDES))
+	for component := range config.LOG_LEVEL_OVERRIDES {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+
+	violations := []Violation{}
+	for _, component := range components {
+		problem := checkOneOf(reflect.ValueOf(config.LOG_LEVEL_OVERRIDES[component]), "debug info warn error fatal")
+		if problem != "" {
+			violations = append(violations, Violation{
+				Field:   "LOG_LEVEL_OVERRIDES",
+				Rule:    "oneof",
+				Message: fmt.Sprintf("log level of '%v' in 'LOG_LEVEL_OVERRIDES' %v", component, problem),
+			})
+		}
+	}
+	return violations
+}
+
+// requiredWhen creates a rule requiring fields to be set when a boolean field is true.
+//
+// Parameters:
+//   - condition: The name of the boolean field.
+//   - fields: The names of the fields that must be set.
+//
+// Returns:
+//   - func(config Config) []Violation: The rule.
+func requiredWhen(condition string, fields ...string) func(config Config) []Violation {
+	return func(config Config) []Violation {
+		configValue := reflect.ValueOf(config)
+		if !configValue.FieldByName(condition).Bool() {
+			return nil
+		}
+
+		violations := []Violation{}
+		for _, field := range fields {
+			if configValue.FieldByName(field).IsZero() {
+				violations = append(violations, Violation{
+					Field:   field,
+					Rule:    "required_when",
+					Message: fmt.Sprintf("config property '%v' is required when '%v' is true", field, condition),
+				})
+			}
+		}
+		return violations
+	}
+}
+
+// filesExistWhen creates a rule requiring the files named by fields to exist when a boolean field is true.
+// Fields that are not set are ignored; use requiredWhen to require them.
+//
+// Parameters:
+//   - condition: The name of the boolean field.
+//   - fields: The names of the fields containing file paths.
+//
+// Returns:
+//   - func(config Config) []Violation: The rule.
+func filesExistWhen(condition string, fields ...string) func(config Config) []Violation {
+	return func(config Config) []Violation {
+		configValue := reflect.ValueOf(config)
+		if !configValue.FieldByName(condition).Bool() {
+			return nil
+		}
+
+		violations := []Violation{}
+		for _, field := range fields {
+			path := configValue.FieldByName(field).String()
+			if path == "" {
+				continue
+			}
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() {
+				violations = append(violations, Violation{
+					Field:   field,
+					Rule:    "file_exists",
+					Message: fmt.Sprintf("config property '%v' must point to an existing file when '%v' is true, got %q", field, condition, path),
+				})
+			}
+		}
+		return violations
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+////////////////////////////////
+// Active config & subscriptions
+////////////////////////////////
+
+// activeConfig holds the config that was last loaded by InitConfig or a reload.
+var activeConfig atomic.Pointer[Config]
+
+// activeProvenance holds the origin of the fields of the active config.
+var activeProvenance atomic.Pointer[Provenance]
+
+// activeLoadOptions holds the options used to load the active config; reloads reuse them.
+var activeLoadOptions atomic.Pointer[LoadOptions]
+
+// reloadMutex serializes reloads.
+var reloadMutex sync.Mutex
+
+// subscriptions holds the registered change handlers.
+var subscriptions = struct {
+	sync.Mutex
+	nextId   int
+	handlers map[int]ChangeHandler
+	order    []int
+}{handlers: map[int]ChangeHandler{}}
+
+// Current returns the active configuration.
+// Reloads are only published through Current and Subscribe; GlobalConfig keeps the configuration loaded at startup.
+// The flowkit clients of this module read Current, so they use reloaded endpoints and API keys; code reading
+// GlobalConfig, and loggers created by logging.InitLogger, keep the startup values.
+//
+// Returns:
+//   - *Config: The active configuration; GlobalConfig if no config was activated yet.
+func Current() *Config {
+	config := activeConfig.Load()
+	if config == nil {
+		return GlobalConfig
+	}
+	return config
+}
+
+// setActiveConfig swaps the active configuration. GlobalConfig is not modified, so reloads do not race
+// with code reading it; InitConfig sets it once at startup.
+//
+// Parameters:
+//   - config: The new configuration.
+//   - provenance: The origin of the fields of the new configuration.
+//   - options: The options the configuration was loaded with.
+func setActiveConfig(config *Config, provenance Provenance, options LoadOptions) {
+	activeConfig.Store(config)
+	activeProvenance.Store(&provenance)
+	activeLoadOptions.Store(&options)
+}
+
+// Subscribe registers a handler that is called after every successful config reload that changed at least one field.
+// Handlers are called synchronously, in the order in which they were registered.
+//
+// Parameters:
+//   - handler: The function to call with the old config, the new config and the changed fields.
+//
+// Returns:
+//   - unsubscribe: A function that removes the handler.
+func Subscribe(handler ChangeHandler) (unsubscribe func()) {
+	subscriptions.Lock()
+	defer subscriptions.Unlock()
+
+	id := subscriptions.nextId
+	subscriptions.nextId++
+	subscriptions.handlers[id] = handler
+	subscriptions.order = append(subscriptions.order, id)
+
+	return func() {
+		subscriptions.Lock()
+		defer subscriptions.Unlock()
+		delete(subscriptions.handlers, id)
+		for i, existing := range subscriptions.order {
+			if existing == id {
+				subscriptions.order = append(subscriptions.order[:i], subscriptions.order[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// notifySubscribers calls every registered handler.
+//
+// Parameters:
+//   - oldConfig: The previous configuration.
+//   - newConfig: The new configuration.
+//   - changes: The changed fields.
+func notifySubscribers(oldConfig *Config, newConfig *Config, changes []ConfigChange) {
+	subscriptions.Lock()
+	handlers := make([]ChangeHandler, 0, len(subscriptions.order))
+	for _, id := range subscriptions.order {
+		handlers = append(handlers, subscriptions.handlers[id])
+	}
+	subscriptions.Unlock()
+
+	for _, handler := range handlers {
+		func() {
+			defer func() {
+				r := recover()
+				if r != nil {
+					log.Printf("panic in config change handler: %v", r)
+				}
+			}()
+			handler(oldConfig, newConfig, changes)
+		}()
+	}
+}
+
+////////////////////////////////
+// Reload
+////////////////////////////////
+
+// Reload loads the configuration again with the options used by InitConfig and activates it.
+// The new configuration is validated strictly before it is activated: every rule violation rejects it,
+// even if the configuration was loaded with warnings at startup. If loading or validation fails,
+// the active configuration is kept and the error is returned.
+// The new configuration is published through Current and the subscribers; GlobalConfig is not modified.
+//
+// Returns:
+//   - changes: The fields that changed.
+//   - err: An error if the configuration could not be loaded or is invalid.
+func Reload() (changes []ConfigChange, err error) {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+
+	options := LoadOptions{FilePath: defaultConfigFilePath()}
+	storedOptions := activeLoadOptions.Load()
+	if storedOptions != nil {
+		options = *storedOptions
+	}
+	options.StrictValidation = true
+
+	newConfig, provenance, err := LoadWithProvenance(options)
+	if err != nil {
+		return nil, fmt.Errorf("config reload rejected: %w", err)
+	}
+
+	oldConfig := Current()
+	if oldConfig == nil {
+		oldConfig = &Config{}
+	}
+	changes = DiffConfigs(*oldConfig, *newConfig)
+	if len(changes) == 0 {
+		// Values may still come from different sources than before
+		activeProvenance.Store(&provenance)
+		return nil, nil
+	}
+
+	setActiveConfig(newConfig, provenance, options)
+	notifySubscribers(oldConfig, newConfig, changes)
+
+	return changes, nil
+}
+
+// WatchConfigFile polls the config file used by InitConfig (AALI_CONFIG_PATH or config.yaml),
+// the files it includes and its overlays, and reloads the configuration whenever their content changes.
+// Rejected reloads are logged and written to the error file; the watcher keeps running.
+//
+// Parameters:
+//   - ctx: The context that stops the watcher when cancelled.
+//   - interval: The polling interval; defaults to 5 seconds if not positive.
+//
+// Returns:
+//   - err: An error if the config file cannot be read initially.
+func WatchConfigFile(ctx context.Context, interval time.Duration) (err error) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	options := LoadOptions{FilePath: defaultConfigFilePath()}
+	storedOptions := activeLoadOptions.Load()
+	if storedOptions != nil {
+		options = *storedOptions
+	}
+	if options.FilePath == "" {
+		options.FilePath = defaultConfigFilePath()
+	}
+
+	lastHash, err := hashConfigFiles(options)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				hash, err := hashConfigFiles(options)
+				if err != nil || hash == lastHash {
+					continue
+				}
+				lastHash = hash
+
+				changes, err := Reload()
+				if err != nil {
+					log.Println(err)
+					pan := writeStringToFile(err.Error())
+					if pan != nil {
+						log.Println(pan)
+					}
+					continue
+				}
+				for _, change := range changes {
+					log.Printf("config value changed: %v", change)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// hashConfigFiles returns the SHA-256 hash of the content of the config file, its includes and overlays.
+// If the layered files cannot be resolved, e.g. because a file is being edited, only the config file is hashed.
+//
+// Parameters:
+//   - options: The options the config is loaded with.
+//
+// Returns:
+//   - [32]byte: The hash of the file contents.
+//   - error: An error if the config file cannot be read.
+func hashConfigFiles(options LoadOptions) ([32]byte, error) {
+	prefix := options.EnvironmentPrefix
+	if prefix == "" {
+		prefix = EnvironmentVariablePrefix
+	}
+	files := []string{options.FilePath}
+	overlays, stage := layerSelection(options, prefix)
+	layered, err := readConfigFiles(options.FilePath, overlays, stage, options.EncryptionKey)
+	if err == nil {
+		files = layered.files
+	}
+
+	hash := sha256.New()
+	for _, fileName := range files {
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		fmt.Fprintf(hash, "%v\x00%d\x00", fileName, len(data))
+		hash.Write(data)
+	}
+	var sum [32]byte
+	copy(sum[:], hash.Sum(nil))
+	return sum, nil
+}
+
+// DiffConfigs compares two configurations field by field.
+//
+// Parameters:
+//   - oldConfig: The previous configuration.
+//   - newConfig: The new configuration.
+//
+// Returns:
+//   - []ConfigChange: One entry per field whose value differs, in struct order.
+func DiffConfigs(oldConfig Config, newConfig Config) []ConfigChange {
+	oldValue := reflect.ValueOf(oldConfig)
+	newValue := reflect.ValueOf(newConfig)
+	configType := oldValue.Type()
+
+	changes := []ConfigChange{}
+	for i := 0; i < configType.NumField(); i++ {
+		if isDeprecatedField(configType.Field(i)) {
+			continue
+		}
+		oldField := oldValue.Field(i).Interface()
+		newField := newValue.Field(i).Interface()
+		if !reflect.DeepEqual(oldField, newField) {
+			changes = append(changes, ConfigChange{
+				Field:    configType.Field(i).Name,
+				OldValue: oldField,
+				NewValue: newField,
+			})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,240 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDiffConfigs tests the DiffConfigs function
+func TestDiffConfigs(t *testing.T) {
+	oldConfig := Config{LOG_LEVEL: "info", PRIVATE_WORKFLOWS_FOLDERS: []string{"a"}}
+	newConfig := Config{LOG_LEVEL: "debug", PRIVATE_WORKFLOWS_FOLDERS: []string{"a"}, WORKFLOW_CONFIG_VARIABLES: map[string]string{"k": "v"}}
+
+	expected := []ConfigChange{
+		{Field: "LOG_LEVEL", OldValue: "info", NewValue: "debug"},
+		{Field: "WORKFLOW_CONFIG_VARIABLES", OldValue: map[string]string(nil), NewValue: map[string]string{"k": "v"}},
+	}
+	changes := DiffConfigs(oldConfig, newConfig)
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected changes %+v, got %+v", expected, changes)
+	}
+}
+
+// TestWatchConfigFile tests that file changes are reloaded, validated and published to subscribers
+func TestWatchConfigFile(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile := func(content string) {
+		err := os.WriteFile(fileName, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("SERVICE_NAME: aali\nLOG_LEVEL: info\n")
+
+	options := LoadOptions{FilePath: fileName, RequiredProperties: []string{"SERVICE_NAME"}, IgnoreEnvironment: true}
+	initial, provenance, err := LoadWithProvenance(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setActiveConfig(initial, provenance, options)
+	t.Cleanup(func() {
+		activeConfig.Store(nil)
+		activeLoadOptions.Store(nil)
+		GlobalConfig = nil
+	})
+
+	received := make(chan []ConfigChange, 10)
+	unsubscribe := Subscribe(func(oldConfig *Config, newConfig *Config, changes []ConfigChange) {
+		received <- changes
+	})
+	defer unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err = WatchConfigFile(ctx, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Valid change is applied
+	writeFile("SERVICE_NAME: aali\nLOG_LEVEL: debug\n")
+	select {
+	case changes := <-received:
+		expected := []ConfigChange{{Field: "LOG_LEVEL", OldValue: "info", NewValue: "debug"}}
+		if !reflect.DeepEqual(changes, expected) {
+			t.Errorf("Expected changes %+v, got %+v", expected, changes)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for config reload")
+	}
+	if Current().LOG_LEVEL != "debug" {
+		t.Errorf("Expected active LOG_LEVEL 'debug', got %q", Current().LOG_LEVEL)
+	}
+
+	// Invalid changes are rejected; the rejections are written to the error file in the working directory
+	workingDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(workingDir) }()
+	waitForRejection := func(count int, reason string) {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			content, _ := os.ReadFile("error.log")
+			if strings.Count(string(content), "config reload rejected") >= count {
+				if !strings.Contains(string(content), reason) {
+					t.Errorf("Expected the rejection to mention %q, got %q", reason, content)
+				}
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for rejected reload %d, error file: %q", count, content)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	writeFile("LOG_LEVEL: warn\n")
+	waitForRejection(1, "missing mandatory property 'SERVICE_NAME'")
+	writeFile("SERVICE_NAME: aali\nLOG_LEVEL: verbose\n")
+	waitForRejection(2, "config property 'LOG_LEVEL' must be one of")
+
+	if Current().LOG_LEVEL != "debug" {
+		t.Errorf("Expected invalid reloads to be rejected, got LOG_LEVEL %q", Current().LOG_LEVEL)
+	}
+	select {
+	case changes := <-received:
+		t.Errorf("Expected no changes to be published for invalid reloads, got %+v", changes)
+	default:
+	}
+}
+
+// TestReloadStrictValidation tests that reloads breaking a rule are rejected, even if the startup config was not strict
+func TestReloadStrictValidation(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile := func(content string) {
+		err := os.WriteFile(fileName, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("LOG_LEVEL: info\n")
+	options := LoadOptions{FilePath: fileName, IgnoreEnvironment: true}
+	initial, provenance, err := LoadWithProvenance(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setActiveConfig(initial, provenance, options)
+	t.Cleanup(func() {
+		activeConfig.Store(nil)
+		activeLoadOptions.Store(nil)
+	})
+
+	called := false
+	unsubscribe := Subscribe(func(oldConfig *Config, newConfig *Config, changes []ConfigChange) {
+		called = true
+	})
+	defer unsubscribe()
+
+	invalid := []string{
+		"LOG_LEVEL: verbose\n",
+		"LOG_LEVEL: info\nQDRANT_PORT: 70000\n",
+		"LOG_LEVEL: info\nNUMBER_OF_WORKFLOW_WORKERS: 0\n",
+		"LOG_LEVEL: info\nUSE_SSL: true\nSSL_CERT_PUBLIC_KEY_FILE: missing.crt\nSSL_CERT_PRIVATE_KEY_FILE: missing.key\n",
+	}
+	for _, content := range invalid {
+		writeFile(content)
+		_, err = Reload()
+		var validationError *ValidationError
+		if !errors.As(err, &validationError) {
+			t.Errorf("Expected reload of %q to be rejected with a *ValidationError, got %v", content, err)
+		}
+	}
+	if called || Current() != initial {
+		t.Errorf("Expected the active config to be kept and no subscriber to be called")
+	}
+}
+
+// TestReloadConcurrentReads tests that reloads do not race with readers of GlobalConfig and Current; run with -race
+func TestReloadConcurrentReads(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(fileName, []byte("LOG_LEVEL: info\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := LoadOptions{FilePath: fileName, IgnoreEnvironment: true}
+	initial, provenance, err := LoadWithProvenance(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	GlobalConfig = initial
+	setActiveConfig(initial, provenance, options)
+	t.Cleanup(func() {
+		activeConfig.Store(nil)
+		activeLoadOptions.Store(nil)
+		GlobalConfig = nil
+	})
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				_ = GlobalConfig.LOG_LEVEL
+				_ = Current().LOG_LEVEL
+			}
+		}
+	}()
+
+	for _, level := range []string{"debug", "warn", "error"} {
+		err = os.WriteFile(fileName, []byte("LOG_LEVEL: "+level+"\n"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Reload()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stop)
+	<-done
+
+	if Current().LOG_LEVEL != "error" || GlobalConfig.LOG_LEVEL != "info" {
+		t.Errorf("Expected reloads to update Current only, got %q and GlobalConfig %q", Current().LOG_LEVEL, GlobalConfig.LOG_LEVEL)
+	}
+}
@@ -0,0 +1,320 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+////////////////////////////////
+// context.Context bridge
+////////////////////////////////
+
+// NewContext returns a copy of parent carrying the ContextMap, so it travels together with deadlines and cancellation.
+//
+// Parameters:
+//   - parent: The parent context.
+//   - ctx: The ContextMap to attach; nil attaches an empty ContextMap.
+//
+// Returns:
+//   - context.Context: The context carrying the ContextMap.
+func NewContext(parent context.Context, ctx *ContextMap) context.Context {
+	if ctx == nil {
+		ctx = &ContextMap{}
+	}
+	return context.WithValue(parent, contextMapKey{}, ctx)
+}
+
+// FromContext returns the ContextMap attached to a context with NewContext.
+//
+// Parameters:
+//   - ctx: The context.
+//
+// Returns:
+//   - *ContextMap: The attached ContextMap; an empty ContextMap if none is attached.
+func FromContext(ctx context.Context) *ContextMap {
+	if ctx != nil {
+		contextMap, ok := ctx.Value(contextMapKey{}).(*ContextMap)
+		if ok && contextMap != nil {
+			return contextMap
+		}
+	}
+	return &ContextMap{}
+}
+
+// contextWithMetadata returns a context carrying a copy of its ContextMap with the accepted propagated keys
+// from a lookup set, and the remote span of the W3C trace context, if any.
+//
+// Parameters:
+//   - ctx: The context.
+//   - lookup: Returns the value of a header or metadata name, or an empty string.
+//   - accepted: The context keys to read; all propagated keys if empty.
+//
+// Returns:
+//   - context.Context: The context carrying the extended ContextMap.
+func contextWithMetadata(ctx context.Context, lookup func(name string) string, accepted []ContextKey) context.Context {
+	ctx = propagation.TraceContext{}.Extract(ctx, lookupCarrier(lookup))
+	contextMap := FromContext(ctx).Copy()
+	for _, key := range propagatedKeys {
+		if len(accepted) > 0 && !slices.Contains(accepted, key.key) {
+			continue
+		}
+		value := lookup(key.header)
+		if value != "" {
+			contextMap.Set(key.key, value)
+		}
+	}
+	return NewContext(ctx, contextMap)
+}
+
+// propagatedValues returns the values of the propagated keys of a ContextMap by header name.
+//
+// Parameters:
+//   - ctx: The ContextMap; may be nil.
+//
+// Returns:
+//   - []string: Pairs of lowercase header name and value, for keys with a non-empty value.
+func propagatedValues(ctx *ContextMap) []string {
+	pairs := []string{}
+	if ctx == nil {
+		return pairs
+	}
+	for _, key := range propagatedKeys {
+		value, ok := ctx.Get(key.key)
+		if !ok || value == nil {
+			continue
+		}
+		stringValue := fmt.Sprint(value)
+		if stringValue != "" {
+			pairs = append(pairs, key.header, stringValue)
+		}
+	}
+	return pairs
+}
+
+////////////////////////////////
+// gRPC propagation
+////////////////////////////////
+
+// UnaryClientInterceptor returns a gRPC client interceptor that sends the propagated keys of the ContextMap
+// attached to the call context as outgoing metadata.
+//
+// Returns:
+//   - grpc.UnaryClientInterceptor: The interceptor.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingContext(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor returns a gRPC client interceptor that sends the propagated keys of the ContextMap
+// attached to the stream context as outgoing metadata.
+//
+// Returns:
+//   - grpc.StreamClientInterceptor: The interceptor.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingContext(ctx), desc, cc, method, opts...)
+	}
+}
+
+// UnaryServerInterceptor returns a gRPC server interceptor that attaches a ContextMap with the propagated keys
+// of the incoming metadata to the handler context; retrieve it with FromContext.
+// The call is traced in a server span, child of the span of the client if it sent a trace context.
+//
+// The x-aali-* metadata is supplied by the client and not verified. The values, e.g. UserMail, are meant for
+// log correlation only and must not be used for authentication or authorization. Servers reachable by untrusted
+// clients can restrict the keys they accept.
+//
+// Parameters:
+//   - accepted: The context keys to accept from the metadata, e.g. InstructionGuid; all propagated keys if empty.
+//
+// Returns:
+//   - grpc.UnaryServerInterceptor: The interceptor.
+func UnaryServerInterceptor(accepted ...ContextKey) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := StartSpan(incomingContext(ctx, accepted), info.FullMethod, trace.WithSpanKind(trace.SpanKindServer))
+		resp, err := handler(ctx, req)
+		EndSpan(span, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a gRPC server interceptor that attaches a ContextMap with the propagated keys
+// of the incoming metadata to the stream context; retrieve it with FromContext.
+// The stream is traced in a server span, child of the span of the client if it sent a trace context.
+// Like for UnaryServerInterceptor, the values are supplied by the client and not verified.
+//
+// Parameters:
+//   - accepted: The context keys to accept from the metadata; all propagated keys if empty.
+//
+// Returns:
+//   - grpc.StreamServerInterceptor: The interceptor.
+func StreamServerInterceptor(accepted ...ContextKey) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := StartSpan(incomingContext(stream.Context(), accepted), info.FullMethod, trace.WithSpanKind(trace.SpanKindServer))
+		err := handler(srv, &contextServerStream{ServerStream: stream, ctx: ctx})
+		EndSpan(span, err)
+		return err
+	}
+}
+
+// Context returns the context of the stream with the attached ContextMap.
+func (stream *contextServerStream) Context() context.Context {
+	return stream.ctx
+}
+
+// outgoingContext appends the propagated keys of the attached ContextMap and the W3C trace context to the
+// outgoing metadata of a context.
+//
+// Parameters:
+//   - ctx: The call context.
+//
+// Returns:
+//   - context.Context: The context with the outgoing metadata.
+func outgoingContext(ctx context.Context) context.Context {
+	contextMap, _ := ctx.Value(contextMapKey{}).(*ContextMap)
+	pairs := propagatedValues(contextMap)
+	traceContext := propagation.MapCarrier{}
+	injectTraceContext(spanContext(ctx), traceContext)
+	for name, value := range traceContext {
+		pairs = append(pairs, name, value)
+	}
+	if len(pairs) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// incomingContext attaches a ContextMap with the accepted propagated keys of the incoming metadata to a context.
+//
+// Parameters:
+//   - ctx: The handler context.
+//   - accepted: The context keys to accept; all propagated keys if empty.
+//
+// Returns:
+//   - context.Context: The context carrying the ContextMap.
+func incomingContext(ctx context.Context, accepted []ContextKey) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return contextWithMetadata(ctx, func(name string) string {
+		values := md.Get(name)
+		if len(values) == 0 {
+			return ""
+		}
+		return values[0]
+	}, accepted)
+}
+
+////////////////////////////////
+// HTTP propagation
+////////////////////////////////
+
+// InjectHTTPHeaders sets the propagated keys of a ContextMap as headers of an outgoing request,
+// and the W3C trace context of the span whose IDs it stores.
+//
+// Parameters:
+//   - ctx: The ContextMap; may be nil.
+//   - header: The request headers.
+func InjectHTTPHeaders(ctx *ContextMap, header http.Header) {
+	pairs := propagatedValues(ctx)
+	for i := 0; i < len(pairs); i += 2 {
+		header.Set(pairs[i], pairs[i+1])
+	}
+	injectTraceContext(spanContextFromMap(ctx), propagation.HeaderCarrier(header))
+}
+
+// HTTPMiddleware returns a handler that attaches a ContextMap with the propagated keys of the request headers
+// to the request context; retrieve it with FromContext(request.Context()).
+// The request is traced in a server span, child of the span of the client if it sent a trace context.
+//
+// The x-aali-* headers are supplied by the client and not verified. The values, e.g. UserMail, are meant for
+// log correlation only and must not be used for authentication or authorization. Servers reachable by untrusted
+// clients can restrict the keys they accept.
+//
+// Parameters:
+//   - next: The handler to call.
+//   - accepted: The context keys to accept from the headers, e.g. InstructionGuid; all propagated keys if empty.
+//
+// Returns:
+//   - http.Handler: The wrapping handler.
+func HTTPMiddleware(next http.Handler, accepted ...ContextKey) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		ctx, span := StartSpan(contextWithMetadata(request.Context(), request.Header.Get, accepted), request.Method+" "+request.URL.Path, trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+		next.ServeHTTP(writer, request.WithContext(ctx))
+	})
+}
+
+// NewHTTPTransport returns a RoundTripper that sets the propagated keys of the ContextMap attached to the
+// request context and the W3C trace context as headers, for clients sending requests created with
+// http.NewRequestWithContext.
+//
+// Parameters:
+//   - base: The RoundTripper sending the requests; nil uses http.DefaultTransport.
+//
+// Returns:
+//   - http.RoundTripper: The propagating RoundTripper.
+func NewHTTPTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &contextTransport{base: base}
+}
+
+// RoundTrip sends a copy of the request carrying the headers of the attached ContextMap and the trace context.
+func (transport *contextTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	contextMap, ok := request.Context().Value(contextMapKey{}).(*ContextMap)
+	span := spanContext(request.Context())
+	if !ok && !span.IsValid() {
+		return transport.base.RoundTrip(request)
+	}
+	request = request.Clone(request.Context())
+	InjectHTTPHeaders(contextMap, request.Header)
+	injectTraceContext(span, propagation.HeaderCarrier(request.Header))
+	return transport.base.RoundTrip(request)
+}
+
+////////////////////////////////
+// Carriers
+////////////////////////////////
+
+// Get returns the value of a header or metadata name.
+func (carrier lookupCarrier) Get(key string) string {
+	return carrier(key)
+}
+
+// Set is not supported; lookup carriers are only used for extraction.
+func (carrier lookupCarrier) Set(key string, value string) {}
+
+// Keys is not supported; lookup carriers are only used for extraction.
+func (carrier lookupCarrier) Keys() []string {
+	return nil
+}
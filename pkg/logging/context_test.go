@@ -0,0 +1,190 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package logging
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// TestContextBridge tests attaching a ContextMap to a context.Context
+func TestContextBridge(t *testing.T) {
+	ctx := &ContextMap{}
+	ctx.Set(InstructionGuid, "instruction-1")
+	parent, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	attached := NewContext(parent, ctx)
+	if FromContext(attached) != ctx {
+		t.Errorf("Expected the attached ContextMap")
+	}
+	_, hasDeadline := attached.Deadline()
+	if !hasDeadline {
+		t.Errorf("Expected the deadline of the parent context")
+	}
+
+	empty := FromContext(context.Background())
+	_, ok := empty.Get(InstructionGuid)
+	if empty == nil || ok {
+		t.Errorf("Expected an empty ContextMap for a context without one")
+	}
+}
+
+// TestGRPCPropagation tests that the propagated keys travel from the client to the server interceptors
+func TestGRPCPropagation(t *testing.T) {
+	ctx := &ContextMap{}
+	ctx.Set(InstructionGuid, "instruction-1")
+	ctx.Set(ClientGuid, "client-1")
+	ctx.Set(UserMail, "user@example.com")
+	ctx.Set(Action, "not propagated")
+
+	// Client side
+	var outgoing metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		outgoing, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	callContext := metadata.AppendToOutgoingContext(NewContext(context.Background(), ctx), "x-api-key", "key")
+	err := UnaryClientInterceptor()(callContext, "/test/Method", nil, nil, nil, invoker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"x-aali-instruction-guid": "instruction-1",
+		"x-aali-client-guid":      "client-1",
+		"x-aali-user-mail":        "user@example.com",
+		"x-api-key":               "key",
+	}
+	if len(outgoing) != len(expected) {
+		t.Errorf("Expected metadata %v, got %v", expected, outgoing)
+	}
+	for name, value := range expected {
+		if values := outgoing.Get(name); len(values) != 1 || values[0] != value {
+			t.Errorf("Expected metadata %v to be %q, got %q", name, value, values)
+		}
+	}
+
+	// Server side
+	incoming := metadata.NewIncomingContext(context.Background(), outgoing)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return FromContext(ctx), nil
+	}
+	result, err := UnaryServerInterceptor()(incoming, nil, &grpc.UnaryServerInfo{}, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := result.(*ContextMap)
+	for _, key := range []ContextKey{InstructionGuid, ClientGuid, UserMail} {
+		value, _ := received.Get(key)
+		original, _ := ctx.Get(key)
+		if value != original {
+			t.Errorf("Expected %v to be %v, got %v", key, original, value)
+		}
+	}
+	if _, ok := received.Get(Action); ok {
+		t.Errorf("Expected keys that are not propagated to be missing")
+	}
+
+	// Servers can restrict the accepted keys
+	result, err = UnaryServerInterceptor(InstructionGuid)(incoming, nil, &grpc.UnaryServerInfo{}, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restricted := result.(*ContextMap)
+	if value, _ := restricted.Get(InstructionGuid); value == nil {
+		t.Errorf("Expected the accepted instruction GUID to be set")
+	}
+	if _, ok := restricted.Get(UserMail); ok {
+		t.Errorf("Expected the user mail not to be accepted")
+	}
+
+	// Streams
+	var streamed *ContextMap
+	streamHandler := func(srv interface{}, stream grpc.ServerStream) error {
+		streamed = FromContext(stream.Context())
+		return nil
+	}
+	err = StreamServerInterceptor()(nil, &testServerStream{ctx: incoming}, &grpc.StreamServerInfo{}, streamHandler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := streamed.Get(ClientGuid); value != "client-1" {
+		t.Errorf("Expected stream context to carry the client GUID, got %v", value)
+	}
+}
+
+// testServerStream is a grpc.ServerStream returning a fixed context.
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *testServerStream) Context() context.Context {
+	return stream.ctx
+}
+
+// TestHTTPPropagation tests that the propagated keys travel from the client transport to the server middleware
+func TestHTTPPropagation(t *testing.T) {
+	var received *ContextMap
+	server := httptest.NewServer(HTTPMiddleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		received = FromContext(request.Context())
+	})))
+	defer server.Close()
+
+	ctx := &ContextMap{}
+	ctx.Set(InstructionGuid, "instruction-1")
+	ctx.Set(UserMail, "user@example.com")
+	request, err := http.NewRequestWithContext(NewContext(context.Background(), ctx), http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: NewHTTPTransport(nil)}
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	if request.Header.Get("x-aali-instruction-guid") != "" {
+		t.Errorf("Expected the transport not to modify the original request")
+	}
+	for _, key := range []ContextKey{InstructionGuid, UserMail} {
+		value, _ := received.Get(key)
+		original, _ := ctx.Get(key)
+		if value != original {
+			t.Errorf("Expected %v to be %v, got %v", key, original, value)
+		}
+	}
+
+	header := http.Header{}
+	InjectHTTPHeaders(ctx, header)
+	if header.Get("X-Aali-User-Mail") != "user@example.com" || len(header) != 2 {
+		t.Errorf("Unexpected injected headers %v", header)
+	}
+}
@@ -0,0 +1,348 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+///////////////////////////////////
+// Log levels
+///////////////////////////////////
+
+// Names of the outputs of the built-in sinks, used for output levels.
+const (
+	OutputConsole = "console"
+	OutputFile    = "file"
+	OutputDatadog = "datadog"
+	OutputOTLP    = "otlp"
+)
+
+// defaultLevels is used by loggers created without NewLogger, e.g. the global logger before InitLogger.
+var defaultLevels = &levelState{level: zapcore.InfoLevel}
+
+// Levels returns the current log levels of the logger.
+//
+// Returns:
+//   - LevelSettings: The log level, the output levels and the component levels.
+func (logger *Logger) Levels() LevelSettings {
+	state := logger.levelState()
+	settings := LevelSettings{
+		Level:      state.level.String(),
+		Outputs:    map[string]string{},
+		Components: map[string]string{},
+	}
+	for output, level := range state.outputs {
+		settings.Outputs[output] = level.String()
+	}
+	for component, level := range state.components {
+		settings.Components[component] = level.String()
+	}
+	return settings
+}
+
+// SetLevel changes the log level of the logger at runtime.
+//
+// Parameters:
+//   - level: The new level (debug, info, warn, error or fatal).
+//
+// Returns:
+//   - error: An error if the level is invalid.
+func (logger *Logger) SetLevel(level string) error {
+	return logger.SetLevels(LevelSettings{Level: level})
+}
+
+// SetOutputLevel changes the minimum level of the entries written to an output at runtime.
+// Output levels only restrict the entries passing the log level; they cannot enable entries below it.
+//
+// Parameters:
+//   - output: The name of the output, e.g. OutputDatadog.
+//   - level: The new level; an empty level removes the output level.
+//
+// Returns:
+//   - error: An error if the level is invalid.
+func (logger *Logger) SetOutputLevel(output string, level string) error {
+	return logger.SetLevels(LevelSettings{Outputs: map[string]string{output: level}})
+}
+
+// SetComponentLevel changes the log level of a component at runtime, overriding the log level of the logger.
+// A component is either the value of the Component context key or a package path, which also matches its subpackages.
+//
+// Parameters:
+//   - component: The component name or package path.
+//   - level: The new level; an empty level removes the override.
+//
+// Returns:
+//   - error: An error if the level is invalid.
+func (logger *Logger) SetComponentLevel(component string, level string) error {
+	return logger.SetLevels(LevelSettings{Components: map[string]string{component: level}})
+}
+
+// SetLevels changes several log levels at once. An empty Level keeps the log level; output and component levels
+// are merged into the current ones, an empty level removes the entry. Nothing is changed if a level is invalid.
+//
+// Parameters:
+//   - settings: The levels to change.
+//
+// Returns:
+//   - error: An error if a level is invalid.
+func (logger *Logger) SetLevels(settings LevelSettings) error {
+	logger.levelMutex.Lock()
+	defer logger.levelMutex.Unlock()
+
+	current := logger.levelState()
+	state := &levelState{
+		level:      current.level,
+		outputs:    mergeLevels(current.outputs, nil),
+		components: mergeLevels(current.components, nil),
+	}
+	var err error
+	if settings.Level != "" {
+		state.level, err = parseLevel(settings.Level)
+		if err != nil {
+			return err
+		}
+	}
+	state.outputs, err = mergeLevelSettings(state.outputs, settings.Outputs)
+	if err != nil {
+		return err
+	}
+	state.components, err = mergeLevelSettings(state.components, settings.Components)
+	if err != nil {
+		return err
+	}
+	logger.levels.Store(state)
+	return nil
+}
+
+// LevelHandler returns an HTTP handler to read and change the log levels at runtime.
+//
+// GET returns the LevelSettings as JSON. PUT accepts LevelSettings as JSON, applies them like SetLevels
+// and returns the resulting settings, e.g. {"level": "debug", "components": {"github.com/ansys/aali-flowkit": "warn"}}.
+// The handler has no authentication; only expose it on an internal port.
+//
+// Returns:
+//   - http.Handler: The handler.
+func (logger *Logger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var settings LevelSettings
+			err := json.NewDecoder(r.Body).Decode(&settings)
+			if err == nil {
+				err = logger.SetLevels(settings)
+			}
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "only GET and PUT are supported"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(logger.Levels())
+	})
+}
+
+// levelSettingsFromConfig returns the log levels of a logging configuration.
+//
+// Parameters:
+//   - config: The logging configuration.
+//
+// Returns:
+//   - LevelSettings: The levels.
+func levelSettingsFromConfig(config Config) LevelSettings {
+	settings := LevelSettings{
+		Level: config.LogLevel,
+		Outputs: map[string]string{
+			OutputConsole: config.ConsoleLogLevel,
+			OutputFile:    config.LocalLogLevel,
+			OutputDatadog: config.DatadogLogLevel,
+			OutputOTLP:    config.OTLPLogLevel,
+		},
+		Components: config.LogLevelOverrides,
+	}
+	if settings.Level == "" {
+		settings.Level = zapcore.InfoLevel.String()
+	}
+	return settings
+}
+
+// levelState returns the current levels of the logger.
+//
+// Returns:
+//   - *levelState: The levels; the defaults if the logger was not created by NewLogger.
+func (logger *Logger) levelState() *levelState {
+	state := logger.levels.Load()
+	if state == nil {
+		return defaultLevels
+	}
+	return state
+}
+
+// minimumLevel returns the minimum level of the entries of a component.
+// Overrides for the Component context value take precedence over package overrides; among package overrides,
+// the longest matching package path wins.
+//
+// Parameters:
+//   - entry: The log entry.
+//
+// Returns:
+//   - zapcore.Level: The minimum level.
+func (state *levelState) minimumLevel(entry Entry) zapcore.Level {
+	if component, ok := entry.Context[string(Component)].(string); ok {
+		if level, ok := state.components[component]; ok {
+			return level
+		}
+	}
+
+	level := state.level
+	packagePath := functionPackage(entry.Function)
+	longest := -1
+	for component, componentLevel := range state.components {
+		if (packagePath == component || strings.HasPrefix(packagePath, component+"/")) && len(component) > longest {
+			level = componentLevel
+			longest = len(component)
+		}
+	}
+	return level
+}
+
+// outputEnabled reports whether an entry is written to a sink, based on the output level of the sink.
+//
+// Parameters:
+//   - sink: The sink.
+//   - level: The level of the entry.
+//
+// Returns:
+//   - bool: True if the entry is written to the sink.
+func (state *levelState) outputEnabled(sink Sink, level zapcore.Level) bool {
+	named, ok := sink.(interface{ Name() string })
+	if !ok {
+		return true
+	}
+	minimum, ok := state.outputs[named.Name()]
+	return !ok || level >= minimum
+}
+
+// functionPackage returns the package path of a fully qualified function name,
+// e.g. "github.com/ansys/aali-sharedtypes/pkg/logging" for "github.com/ansys/aali-sharedtypes/pkg/logging.(*Logger).Info".
+//
+// Parameters:
+//   - function: The function name.
+//
+// Returns:
+//   - string: The package path.
+func functionPackage(function string) string {
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot < 0 {
+		return function
+	}
+	return function[:slash+1+dot]
+}
+
+// parseLevel parses a log level; an empty level is "info".
+//
+// Parameters:
+//   - level: The level (debug, info, warn, error or fatal).
+//
+// Returns:
+//   - zapcore.Level: The level.
+//   - error: An error if the level is invalid.
+func parseLevel(level string) (zapcore.Level, error) {
+	switch strings.ToLower(level) {
+	case "":
+		return zapcore.InfoLevel, nil
+	case "debug", "info", "warn", "error", "fatal":
+		return zapcore.ParseLevel(strings.ToLower(level))
+	}
+	return zapcore.InfoLevel, fmt.Errorf("invalid log level %q, must be one of debug, info, warn, error or fatal", level)
+}
+
+// mergeLevelSettings merges level settings into a copy of a level map. An empty level removes the entry.
+//
+// Parameters:
+//   - levels: The current levels.
+//   - settings: The levels to merge by name.
+//
+// Returns:
+//   - map[string]zapcore.Level: The merged levels.
+//   - error: An error if a level is invalid.
+func mergeLevelSettings(levels map[string]zapcore.Level, settings map[string]string) (map[string]zapcore.Level, error) {
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parsed := map[string]zapcore.Level{}
+	for _, name := range names {
+		if settings[name] == "" {
+			continue
+		}
+		level, err := parseLevel(settings[name])
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", name, err)
+		}
+		parsed[name] = level
+	}
+
+	merged := mergeLevels(levels, parsed)
+	for name, level := range settings {
+		if level == "" {
+			delete(merged, name)
+		}
+	}
+	return merged, nil
+}
+
+// mergeLevels returns a copy of a level map with other levels added.
+//
+// Parameters:
+//   - levels: The levels to copy.
+//   - added: The levels to add; may be nil.
+//
+// Returns:
+//   - map[string]zapcore.Level: The merged levels.
+func mergeLevels(levels map[string]zapcore.Level, added map[string]zapcore.Level) map[string]zapcore.Level {
+	merged := make(map[string]zapcore.Level, len(levels)+len(added))
+	for name, level := range levels {
+		merged[name] = level
+	}
+	for name, level := range added {
+		merged[name] = level
+	}
+	return merged
+}
@@ -0,0 +1,145 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ansys/aali-sharedtypes/pkg/config"
+)
+
+// namedSink is a memorySink subject to the output level of its name.
+type namedSink struct {
+	memorySink
+	name string
+}
+
+func (sink *namedSink) Name() string {
+	return sink.name
+}
+
+// TestLevels tests the log level, output levels and component levels, and changing them at runtime
+func TestLevels(t *testing.T) {
+	console := &namedSink{name: OutputConsole}
+	datadog := &namedSink{name: OutputDatadog}
+	logger, err := NewLogger(Config{
+		LogLevel:          "info",
+		DatadogLogLevel:   "warn",
+		LogLevelOverrides: map[string]string{"github.com/ansys/aali-sharedtypes/pkg": "error", "indexer": "debug"},
+	}, console, datadog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The package override applies to this test package; the component override to entries of the component
+	indexer := &ContextMap{}
+	indexer.Set(Component, "indexer")
+	logger.Warn(nil, "package warn")
+	logger.Error(nil, "package error")
+	logger.Debugf(indexer, "indexer debug")
+	if len(console.entries) != 2 || console.entries[0].Message != "package error" || console.entries[1].Message != "indexer debug" {
+		t.Errorf("Unexpected console entries %+v", console.entries)
+	}
+	if len(datadog.entries) != 1 || datadog.entries[0].Message != "package error" {
+		t.Errorf("Expected output level to filter Datadog entries, got %+v", datadog.entries)
+	}
+
+	// Runtime changes
+	err = logger.SetComponentLevel("github.com/ansys/aali-sharedtypes/pkg", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = logger.SetLevel("verbose")
+	if err == nil {
+		t.Errorf("Expected error for invalid level")
+	}
+	logger.Info(nil, "info")
+	if len(console.entries) != 3 || len(datadog.entries) != 1 {
+		t.Errorf("Expected info entry only on the console, got %d and %d entries", len(console.entries), len(datadog.entries))
+	}
+	expected := LevelSettings{Level: "info", Outputs: map[string]string{OutputDatadog: "warn"}, Components: map[string]string{"indexer": "debug"}}
+	if settings := logger.Levels(); !reflect.DeepEqual(settings, expected) {
+		t.Errorf("Expected levels %+v, got %+v", expected, settings)
+	}
+}
+
+// TestLevelHandler tests reading and changing levels over HTTP
+func TestLevelHandler(t *testing.T) {
+	logger, err := NewLogger(Config{}, &memorySink{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := logger.LevelHandler()
+
+	request := httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader(`{"level": "debug", "outputs": {"file": "error"}}`))
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	var settings LevelSettings
+	err = json.Unmarshal(response.Body.Bytes(), &settings)
+	if response.Code != http.StatusOK || err != nil || settings.Level != "debug" || settings.Outputs[OutputFile] != "error" {
+		t.Errorf("Unexpected response %v %s", response.Code, response.Body)
+	}
+
+	for method, body := range map[string]string{http.MethodPut: `{"components": {"x": "loud"}}`, http.MethodPost: `{}`} {
+		response = httptest.NewRecorder()
+		handler.ServeHTTP(response, httptest.NewRequest(method, "/log/level", strings.NewReader(body)))
+		if response.Code == http.StatusOK {
+			t.Errorf("%v %v: expected error, got %s", method, body, response.Body)
+		}
+	}
+
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/log/level", nil))
+	if !strings.Contains(response.Body.String(), `"level":"debug"`) || strings.Contains(response.Body.String(), `"x"`) {
+		t.Errorf("Expected unchanged levels after invalid request, got %s", response.Body)
+	}
+}
+
+// TestApplyLevelChanges tests that level changes of config reloads are applied to the global logger
+func TestApplyLevelChanges(t *testing.T) {
+	previous := Log
+	defer func() { Log = previous }()
+	var err error
+	Log, err = NewLogger(Config{LogLevel: "info", LogLevelOverrides: map[string]string{"old": "debug"}}, &memorySink{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Log.SetOutputLevel(OutputConsole, "warn")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldConfig := &config.Config{LOG_LEVEL: "info", LOG_LEVEL_OVERRIDES: map[string]string{"old": "debug"}}
+	newConfig := &config.Config{LOG_LEVEL: "error", LOG_LEVEL_OVERRIDES: map[string]string{"new": "warn"}, SERVICE_NAME: "agent"}
+	applyLevelChanges(oldConfig, newConfig, []config.ConfigChange{{Field: "LOG_LEVEL"}, {Field: "LOG_LEVEL_OVERRIDES"}, {Field: "SERVICE_NAME"}})
+
+	expected := LevelSettings{Level: "error", Outputs: map[string]string{OutputConsole: "warn"}, Components: map[string]string{"new": "warn"}}
+	if settings := Log.Levels(); !reflect.DeepEqual(settings, expected) {
+		t.Errorf("Expected levels %+v, got %+v", expected, settings)
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package logging
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ansys/aali-sharedtypes/pkg/config"
+	"go.uber.org/zap/zapcore"
+)
+
+// memorySink collects the entries written to it.
+type memorySink struct {
+	mutex   sync.Mutex
+	entries []Entry
+	closed  bool
+}
+
+func (sink *memorySink) Write(entry Entry) error {
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+	sink.entries = append(sink.entries, entry)
+	return nil
+}
+
+func (sink *memorySink) Close() error {
+	sink.closed = true
+	return nil
+}
+
+// TestLoggerSinks tests that independently configured loggers write filtered entries with context to their own sinks
+func TestLoggerSinks(t *testing.T) {
+	debugSink := &memorySink{}
+	debugLogger, err := NewLogger(Config{LogLevel: "debug"}, debugSink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	warnSink := &memorySink{}
+	warnLogger, err := NewLogger(Config{LogLevel: "warn"}, warnSink)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &ContextMap{}
+	ctx.Set(UserMail, "alice@ansys.com")
+	for _, logger := range []*Logger{debugLogger, warnLogger} {
+		logger.Debugf(ctx, "debug %d", 1)
+		logger.Info(ctx, "info")
+		logger.Warnf(ctx, "warn %v", "x")
+		logger.Error(nil, "error")
+	}
+
+	if len(debugSink.entries) != 4 || len(warnSink.entries) != 2 {
+		t.Fatalf("Expected 4 and 2 entries, got %d and %d", len(debugSink.entries), len(warnSink.entries))
+	}
+	entry := warnSink.entries[0]
+	if entry.Level != zapcore.WarnLevel || entry.Message != "warn x" || entry.Context[string(UserMail)] != "alice@ansys.com" || len(entry.Arguments) != 1 {
+		t.Errorf("Unexpected warn entry %+v", entry)
+	}
+	if !strings.HasSuffix(entry.Caller.File, "logger_test.go") || !strings.HasSuffix(entry.Function, "TestLoggerSinks") {
+		t.Errorf("Expected caller in test, got %v (%v)", entry.Caller, entry.Function)
+	}
+	if entry.Stack != "" || !strings.Contains(warnSink.entries[1].Stack, "TestLoggerSinks") {
+		t.Errorf("Expected stack trace only for errors, got %q and %q", entry.Stack, warnSink.entries[1].Stack)
+	}
+
+	err = warnLogger.Close()
+	if err != nil || !warnSink.closed || debugSink.closed {
+		t.Errorf("Expected only the closed logger's sink to be closed (%v)", err)
+	}
+}
+
+// TestBuiltInSinks tests the console, file, Datadog and OTLP sinks
+func TestBuiltInSinks(t *testing.T) {
+	requests := make(chan *http.Request, 2)
+	bodies := make(chan []byte, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body []byte
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			reader, _ := gzip.NewReader(r.Body)
+			body, _ = io.ReadAll(reader)
+		} else {
+			body, _ = io.ReadAll(r.Body)
+		}
+		requests <- r
+		bodies <- body
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	config := Config{
+		ErrorFileLocation: filepath.Join(dir, "error.log"),
+		LocalLogs:         true,
+		LocalLogsLocation: filepath.Join(dir, "logs.log"),
+		DatadogService:    "agent",
+		DatadogAPIKey:     "key",
+		DatadogLogsURL:    server.URL + "/datadog",
+		OTLPLogsURL:       server.URL + "/v1/logs",
+		OTLPHeaders:       map[string]string{"Authorization": "Bearer token"},
+	}
+	console := &bytes.Buffer{}
+	datadog, err := NewDatadogSink(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otlp, err := NewOTLPSink(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := NewFileSink(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewLogger(config, NewConsoleSink(console), file, datadog, otlp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &ContextMap{}
+	ctx.Set(ClientGuid, "client-1")
+	logger.Info(ctx, "hello")
+	err = logger.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(console.String(), `"msg":"hello"`) || !strings.Contains(console.String(), `"clientGuid":"client-1"`) {
+		t.Errorf("Unexpected console output %q", console.String())
+	}
+	content, err := os.ReadFile(config.LocalLogsLocation)
+	if err != nil || !strings.Contains(string(content), `"message":"hello"`) || !strings.Contains(string(content), `"service":"agent"`) {
+		t.Errorf("Unexpected local log file %q (%v)", content, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		request := <-requests
+		body := <-bodies
+		switch request.URL.Path {
+		case "/datadog":
+			var entries []map[string]interface{}
+			if request.Header.Get("DD-API-KEY") != "key" || json.Unmarshal(body, &entries) != nil || entries[0]["clientGuid"] != "client-1" {
+				t.Errorf("Unexpected Datadog request %q", body)
+			}
+		case "/v1/logs":
+			if request.Header.Get("Authorization") != "Bearer token" || !strings.Contains(string(body), `"body":{"stringValue":"hello"}`) ||
+				!strings.Contains(string(body), `"severityNumber":9`) {
+				t.Errorf("Unexpected OTLP request %q", body)
+			}
+		default:
+			t.Errorf("Unexpected request to %v", request.URL.Path)
+		}
+	}
+	if _, err := os.Stat(config.ErrorFileLocation); !os.IsNotExist(err) {
+		t.Errorf("Expected no errors to be reported")
+	}
+
+	// Misconfigured remote sinks are rejected
+	_, err = NewLogger(Config{DatadogLogs: true})
+	if err == nil {
+		t.Errorf("Expected error for Datadog logs without API key")
+	}
+}
+
+// TestOTLPSinkBatching tests that OTLP entries are sent in batches and that entries written during and after Close are dropped
+func TestOTLPSinkBatching(t *testing.T) {
+	var mutex sync.Mutex
+	records := 0
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ResourceLogs []struct {
+				ScopeLogs []struct {
+					LogRecords []json.RawMessage `json:"logRecords"`
+				} `json:"scopeLogs"`
+			} `json:"resourceLogs"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			t.Errorf("Invalid OTLP request: %v", err)
+		}
+		mutex.Lock()
+		requests++
+		records += len(request.ResourceLogs[0].ScopeLogs[0].LogRecords)
+		mutex.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	sink, err := NewOTLPSink(Config{ErrorFileLocation: filepath.Join(t.TempDir(), "error.log"), OTLPLogsURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Entries are sent in batches of 100
+	for i := 0; i < 250; i++ {
+		_ = sink.Write(Entry{Level: zapcore.InfoLevel, Message: "entry"})
+	}
+	err = sink.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mutex.Lock()
+	if requests != 3 || records != 250 {
+		t.Errorf("Expected 250 records in 3 requests, got %d in %d", records, requests)
+	}
+	mutex.Unlock()
+
+	// Writes racing with Close are either sent or dropped, writes after Close are dropped
+	var writers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			for j := 0; j < 100; j++ {
+				_ = sink.Write(Entry{Level: zapcore.InfoLevel, Message: "entry"})
+			}
+		}()
+	}
+	_ = sink.Close()
+	writers.Wait()
+	err = sink.Write(Entry{Level: zapcore.InfoLevel, Message: "after close"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := sink.Stats()
+	mutex.Lock()
+	defer mutex.Unlock()
+	if stats.Sent+stats.Dropped != 651 || stats.Dropped == 0 || int(stats.Sent) != records {
+		t.Errorf("Expected all entries to be sent or dropped, got %+v and %d received records", stats, records)
+	}
+}
+
+// TestInitLoggerClosesPrevious tests that InitLogger closes the global logger it replaces
+func TestInitLoggerClosesPrevious(t *testing.T) {
+	previous := Log
+	sink := &memorySink{}
+	var err error
+	Log, err = NewLogger(Config{LogLevel: "info"}, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = Log.Close()
+		Log = previous
+		unsubscribeLevels()
+		unsubscribeLevels = nil
+	})
+
+	dir := t.TempDir()
+	InitLogger(&config.Config{ERROR_FILE_LOCATION: filepath.Join(dir, "error.log"), LOCAL_LOGS: true, LOCAL_LOGS_LOCATION: filepath.Join(dir, "first.log")})
+	if !sink.closed {
+		t.Errorf("Expected the replaced logger to be closed")
+	}
+	first := Log
+	InitLogger(&config.Config{ERROR_FILE_LOCATION: filepath.Join(dir, "error.log"), LOCAL_LOGS: true, LOCAL_LOGS_LOCATION: filepath.Join(dir, "second.log")})
+	closed := false
+	for _, sink := range first.sinks {
+		fileSink, ok := sink.(*FileSink)
+		closed = closed || (ok && fileSink.file.closed)
+	}
+	if !closed {
+		t.Errorf("Expected the log file of the replaced logger to be closed")
+	}
+}
@@ -24,14 +24,19 @@ package logging
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/ansys/aali-sharedtypes/pkg/config"
-	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -75,22 +80,14 @@ func (ctx *ContextMap) Copy() *ContextMap {
 
 // InitLogger initializes the global logger.
 //
-// The function creates a new zap logger with the specified configuration and sets the global logger variable to the new logger.
+// The function creates a new logger with the sinks enabled in the configuration and sets the global logger variable to the new logger.
+// The previous global logger is closed; entries it still receives are only written to its console sink.
+// If a sink is misconfigured, the error is written to the error file and the logger is created without the remote sinks.
 //
 // Parameters:
 //   - GlobalConfig: The global configuration from the config package.
 func InitLogger(GlobalConfig *config.Config) {
-
-	// Create a new zap logger with the specified configuration
-	config := zap.NewProductionConfig()
-	config.Level.SetLevel(zap.DebugLevel)
-	option := zap.AddCallerSkip(1)
-	config.EncoderConfig.FunctionKey = "func"
-	temp, _ := config.Build(option)
-	Log = loggerWrapper{lw: temp}
-
-	// Set the global configuration variables for the logging package
-	initLoggerConfig(Config{
+	loggerConfig := Config{
 		ErrorFileLocation: GlobalConfig.ERROR_FILE_LOCATION,
 		LogLevel:          GlobalConfig.LOG_LEVEL,
 		LocalLogs:         GlobalConfig.LOCAL_LOGS,
@@ -104,7 +101,254 @@ func InitLogger(GlobalConfig *config.Config) {
 		DatadogLogsURL:    GlobalConfig.LOGGING_URL,
 		DatadogMetrics:    GlobalConfig.DATADOG_METRICS,
 		DatadogMetricsURL: GlobalConfig.METRICS_URL,
-	})
+		MetricsInterval:   time.Duration(GlobalConfig.METRICS_INTERVAL_SECONDS) * time.Second,
+		OTLPLogs:          GlobalConfig.OTLP_LOGS,
+		OTLPLogsURL:       GlobalConfig.OTLP_LOGS_URL,
+
+		DatadogQueueSize:     GlobalConfig.DATADOG_QUEUE_SIZE,
+		DatadogBatchSize:     GlobalConfig.DATADOG_BATCH_SIZE,
+		DatadogFlushInterval: time.Duration(GlobalConfig.DATADOG_FLUSH_INTERVAL_SECONDS) * time.Second,
+		DatadogMaxRetries:    GlobalConfig.DATADOG_MAX_RETRIES,
+
+		LocalLogsMaxSize:          int64(GlobalConfig.LOCAL_LOGS_MAX_SIZE_MB) * 1024 * 1024,
+		LocalLogsRotationInterval: time.Duration(GlobalConfig.LOCAL_LOGS_ROTATION_HOURS) * time.Hour,
+		LocalLogsMaxAge:           time.Duration(GlobalConfig.LOCAL_LOGS_MAX_AGE_DAYS) * 24 * time.Hour,
+		LocalLogsMaxBackups:       GlobalConfig.LOCAL_LOGS_MAX_BACKUPS,
+		LocalLogsCompress:         GlobalConfig.LOCAL_LOGS_COMPRESS,
+
+		DatadogSpoolDirectory: GlobalConfig.DATADOG_SPOOL_DIRECTORY,
+		DatadogSpoolMaxBytes:  int64(GlobalConfig.DATADOG_SPOOL_MAX_MB) * 1024 * 1024,
+
+		ConsoleLogLevel:   GlobalConfig.CONSOLE_LOG_LEVEL,
+		LocalLogLevel:     GlobalConfig.LOCAL_LOGS_LEVEL,
+		DatadogLogLevel:   GlobalConfig.DATADOG_LOG_LEVEL,
+		OTLPLogLevel:      GlobalConfig.OTLP_LOG_LEVEL,
+		LogLevelOverrides: GlobalConfig.LOG_LEVEL_OVERRIDES,
+	}
+
+	logger, err := NewLogger(loggerConfig)
+	if err != nil {
+		reportError(loggerConfig.ErrorFileLocation, "Error occurred during InitLogger:", err)
+		loggerConfig.DatadogLogs = false
+		loggerConfig.DatadogMetrics = false
+		loggerConfig.OTLPLogs = false
+		logger, err = NewLogger(loggerConfig)
+		if err != nil {
+			logger, _ = NewLogger(Config{ErrorFileLocation: loggerConfig.ErrorFileLocation})
+		}
+	}
+	previous := Log
+	Log = logger
+
+	// Close the previous logger, so its queued entries are delivered and its files and goroutines released
+	if previous != nil && previous != logger {
+		err = previous.Close()
+		if err != nil {
+			reportError(loggerConfig.ErrorFileLocation, "Error occurred while closing the previous logger:", err)
+		}
+	}
+
+	// Apply level changes of config reloads to the global logger
+	if unsubscribeLevels != nil {
+		unsubscribeLevels()
+	}
+	unsubscribeLevels = config.Subscribe(applyLevelChanges)
+
+	// Set the global configuration variables for the logging package
+	initLoggerConfig(loggerConfig)
+}
+
+// NewLogger creates a logger from a logging configuration.
+//
+// If no sinks are given, the logger writes to stdout and to the local file, Datadog and OTLP sinks enabled in the configuration.
+// If Datadog metrics are enabled, the logger starts background goroutines aggregating and sending them; call Close to stop them.
+//
+// Parameters:
+//   - config: The configuration of the logger.
+//   - sinks: The sinks to write to instead of the sinks of the configuration.
+//
+// Returns:
+//   - *Logger: The logger.
+//   - error: An error if a sink or the metrics enabled in the configuration are misconfigured.
+func NewLogger(config Config, sinks ...Sink) (*Logger, error) {
+	if config.DatadogMetrics && (config.DatadogAPIKey == "" || config.DatadogMetricsURL == "") {
+		return nil, fmt.Errorf("'DATADOG_METRICS' set to 'true' in 'config.yaml' file but 'DATADOG_API_KEY' and/or 'DATADOG_METRICS_URL' were not defined")
+	}
+	logger := &Logger{config: config, sinks: sinks}
+	err := logger.SetLevels(levelSettingsFromConfig(config))
+	if err != nil {
+		return nil, err
+	}
+	if len(sinks) == 0 {
+		configured, err := configuredSinks(config)
+		if err != nil {
+			return nil, err
+		}
+		logger.sinks = configured
+	}
+	if config.DatadogMetrics {
+		metrics, err := newShipper("metrics", config.DatadogMetricsURL, config, encodeMetrics)
+		if err != nil {
+			if len(sinks) == 0 {
+				_ = logger.Close()
+			}
+			return nil, err
+		}
+		logger.metrics = metrics
+	}
+
+	// Metrics are aggregated in process, and sent to Datadog every interval if enabled
+	logger.registry.errorFile = config.ErrorFileLocation
+	logger.registry.buckets = append([]float64{}, config.HistogramBuckets...)
+	sort.Float64s(logger.registry.buckets)
+	logger.registry.buckets = slices.Compact(logger.registry.buckets)
+	logger.registry.hostname, _ = os.Hostname()
+	logger.registry.lastFlush = time.Now()
+	if logger.metrics != nil {
+		interval := config.MetricsInterval
+		if interval <= 0 {
+			interval = defaultMetricsInterval
+		}
+		logger.metricsStop = make(chan struct{})
+		logger.metricsDone = make(chan struct{})
+		go logger.runMetricsFlush(interval)
+	}
+	return logger, nil
+}
+
+// configuredSinks creates the stdout sink and the sinks enabled in a logging configuration.
+//
+// Parameters:
+//   - config: The logging configuration.
+//
+// Returns:
+//   - []Sink: The sinks.
+//   - error: An error if a sink is misconfigured; the sinks created before are closed.
+func configuredSinks(config Config) ([]Sink, error) {
+	sinks := []Sink{NewStdoutSink()}
+	fail := func(err error) ([]Sink, error) {
+		for _, sink := range sinks {
+			_ = sink.Close()
+		}
+		return nil, err
+	}
+
+	if config.LocalLogs {
+		sink, err := NewFileSink(config)
+		if err != nil {
+			return fail(err)
+		}
+		sinks = append(sinks, sink)
+	}
+	if config.OTLPLogs {
+		sink, err := NewOTLPSink(config)
+		if err != nil {
+			return fail(err)
+		}
+		sinks = append(sinks, sink)
+	}
+	if config.DatadogLogs {
+		sink, err := NewDatadogSink(config)
+		if err != nil {
+			return fail(err)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// Flush sends the entries and metrics queued by the sinks and waits until they are delivered or have failed.
+// Sinks without a Flush method are skipped.
+//
+// Returns:
+//   - error: The errors of the sinks that failed to deliver their entries.
+func (logger *Logger) Flush() error {
+	errs := []error{}
+	for _, sink := range logger.sinks {
+		if flusher, ok := sink.(interface{ Flush() error }); ok {
+			errs = append(errs, flusher.Flush())
+		}
+	}
+	if logger.metrics != nil {
+		logger.flushMetrics(time.Now())
+		errs = append(errs, logger.metrics.Flush())
+	}
+	return errors.Join(errs...)
+}
+
+// Close sends the queued entries and metrics, closes the sinks and stops the background goroutines.
+// Call Close before the program exits, otherwise queued entries are lost.
+//
+// Returns:
+//   - error: The errors of the sinks that failed to close.
+func (logger *Logger) Close() error {
+	errs := []error{}
+	for _, sink := range logger.sinks {
+		errs = append(errs, sink.Close())
+	}
+	if logger.metrics != nil {
+		logger.stopMetricsFlush()
+		logger.flushMetrics(time.Now())
+		errs = append(errs, logger.metrics.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// MetricsStats returns the delivery counters of the Datadog metrics.
+//
+// Returns:
+//   - DeliveryStats: The counters; zero if Datadog metrics are disabled.
+func (logger *Logger) MetricsStats() DeliveryStats {
+	if logger.metrics == nil {
+		return DeliveryStats{}
+	}
+	return logger.metrics.Stats()
+}
+
+// unsubscribeLevels removes the config reload handler registered by the last InitLogger call.
+var unsubscribeLevels func()
+
+// applyLevelChanges applies the log levels of a reloaded config to the global logger if they changed.
+// Levels changed at runtime by other means are kept unless the reload changes them as well.
+//
+// Parameters:
+//   - oldConfig: The previous config.
+//   - newConfig: The reloaded config.
+//   - changes: The changed fields.
+func applyLevelChanges(oldConfig *config.Config, newConfig *config.Config, changes []config.ConfigChange) {
+	settings := LevelSettings{Outputs: map[string]string{}, Components: map[string]string{}}
+	for _, change := range changes {
+		switch change.Field {
+		case "LOG_LEVEL":
+			settings.Level = newConfig.LOG_LEVEL
+			if settings.Level == "" {
+				settings.Level = zapcore.InfoLevel.String()
+			}
+		case "CONSOLE_LOG_LEVEL":
+			settings.Outputs[OutputConsole] = newConfig.CONSOLE_LOG_LEVEL
+		case "LOCAL_LOGS_LEVEL":
+			settings.Outputs[OutputFile] = newConfig.LOCAL_LOGS_LEVEL
+		case "DATADOG_LOG_LEVEL":
+			settings.Outputs[OutputDatadog] = newConfig.DATADOG_LOG_LEVEL
+		case "OTLP_LOG_LEVEL":
+			settings.Outputs[OutputOTLP] = newConfig.OTLP_LOG_LEVEL
+		case "LOG_LEVEL_OVERRIDES":
+			for component := range oldConfig.LOG_LEVEL_OVERRIDES {
+				settings.Components[component] = ""
+			}
+			for component, level := range newConfig.LOG_LEVEL_OVERRIDES {
+				settings.Components[component] = level
+			}
+		}
+	}
+	if settings.Level == "" && len(settings.Outputs) == 0 && len(settings.Components) == 0 {
+		return
+	}
+
+	err := Log.SetLevels(settings)
+	if err != nil {
+		reportError(ERROR_FILE_LOCATION, "Error occurred while applying reloaded log levels:", err)
+	}
 }
 
 // initLoggerConfig initializes the global configuration variables for the logging package.
@@ -138,31 +382,8 @@ func initLoggerConfig(config Config) {
 // Parameters:
 //   - ctx: A ContextMap containing context information to be included in the log entry.
 //   - args: The log message.
-func (logger *loggerWrapper) Fatal(ctx *ContextMap, args ...interface{}) {
-	entry := logger.lw.Check(zapcore.FatalLevel, fmt.Sprint(args...))
-	if entry != nil {
-		sendLogs(
-			ctx,
-			entry.Level,
-			entry.Time,
-			entry.Message,
-			entry.Caller,
-			entry.Stack,
-			entry.Caller.Function,
-			args...)
-	}
-
-	message := "Program terminated with Fatal Error:"
-	pan := writeStringToFile(ERROR_FILE_LOCATION, message)
-	if pan != nil {
-		panic(pan)
-	}
-	pan = writeInterfaceToFile(ERROR_FILE_LOCATION, fmt.Sprint(args...))
-	if pan != nil {
-		panic(pan)
-	}
-
-	logger.lw.Fatal(fmt.Sprint(args...))
+func (logger *Logger) Fatal(ctx *ContextMap, args ...interface{}) {
+	logger.fatal(ctx, fmt.Sprint(args...), nil)
 }
 
 // Fatalf logs a formatted message with Fatal level and terminates the program.
@@ -171,433 +392,225 @@ func (logger *loggerWrapper) Fatal(ctx *ContextMap, args ...interface{}) {
 //   - ctx: A ContextMap containing context information to be included in the log entry.
 //   - format: The format of the log message.
 //   - args: The log message.
-func (logger *loggerWrapper) Fatalf(ctx *ContextMap, format string, args ...interface{}) {
-	entry := logger.lw.Check(zapcore.FatalLevel, format)
-	if entry != nil {
-		sendLogs(
-			ctx,
-			entry.Level,
-			entry.Time,
-			fmt.Sprintf(format, args...),
-			entry.Caller,
-			entry.Stack,
-			entry.Caller.Function,
-			args...)
-	}
-
-	message := "Program terminated with Fatal Error:"
-	pan := writeStringToFile(ERROR_FILE_LOCATION, message)
-	if pan != nil {
-		panic(pan)
-	}
-	pan = writeInterfaceToFile(ERROR_FILE_LOCATION, fmt.Sprintf(format, args...))
-	if pan != nil {
-		panic(pan)
-	}
-
-	fields := []zap.Field{zap.Any("Arguments", args)}
-	logger.lw.Fatal(fmt.Sprintf(format, args...), fields...)
+func (logger *Logger) Fatalf(ctx *ContextMap, format string, args ...interface{}) {
+	logger.fatal(ctx, fmt.Sprintf(format, args...), args)
 }
 
-// Error logs a message with Error level if the global log level is not set to "fatal".
+// Error logs a message with Error level if the log level is not set to "fatal".
 //
 // Parameters:
 //   - ctx: A ContextMap containing context information to be included in the log entry.
 //   - args: The log message.
-func (logger *loggerWrapper) Error(ctx *ContextMap, args ...interface{}) {
-	if LOG_LEVEL == "fatal" {
-		return
-	}
-
-	logger.lw.Error(fmt.Sprint(args...))
-
-	entry := logger.lw.Check(zapcore.ErrorLevel, fmt.Sprint(args...))
-	if entry != nil {
-		go sendLogs(
-			ctx,
-			entry.Level,
-			entry.Time,
-			entry.Message,
-			entry.Caller,
-			entry.Stack,
-			entry.Caller.Function,
-			args...)
-	}
+func (logger *Logger) Error(ctx *ContextMap, args ...interface{}) {
+	logger.log(ctx, zapcore.ErrorLevel, fmt.Sprint(args...), nil)
 }
 
-// Errorf logs a formatted message with Error level if the global log level is not set to "fatal".
+// Errorf logs a formatted message with Error level if the log level is not set to "fatal".
 //
 // Parameters:
 //   - ctx: A ContextMap containing context information to be included in the log entry.
 //   - format: The format of the log message.
 //   - args: The log message.
-func (logger *loggerWrapper) Errorf(ctx *ContextMap, format string, args ...interface{}) {
-	if LOG_LEVEL == "fatal" {
-		return
-	}
-
-	fields := []zap.Field{zap.Any("Arguments", args)}
-	logger.lw.Error(fmt.Sprintf(format, args...), fields...)
-
-	entry := logger.lw.Check(zapcore.ErrorLevel, format)
-	if entry != nil {
-		go sendLogs(
-			ctx,
-			entry.Level,
-			entry.Time,
-			fmt.Sprintf(format, args...),
-			entry.Caller,
-			entry.Stack,
-			entry.Caller.Function,
-			args...)
-	}
+func (logger *Logger) Errorf(ctx *ContextMap, format string, args ...interface{}) {
+	logger.log(ctx, zapcore.ErrorLevel, fmt.Sprintf(format, args...), args)
 }
 
-// Warn logs a message with Error level if the global log level is not set to "error".
+// Warn logs a message with Warn level if the log level is not set to "error" or "fatal".
 //
 // Parameters:
 //   - ctx: A ContextMap containing context information to be included in the log entry.
 //   - args: The log message.
-func (logger *loggerWrapper) Warn(ctx *ContextMap, args ...interface{}) {
-	if (LOG_LEVEL == "fatal") || (LOG_LEVEL == "error") {
-		return
-	}
-
-	logger.lw.Warn(fmt.Sprint(args...))
-
-	entry := logger.lw.Check(zapcore.WarnLevel, fmt.Sprint(args...))
-	if entry != nil {
-		go sendLogs(
-			ctx,
-			entry.Level,
-			entry.Time,
-			entry.Message,
-			entry.Caller,
-			entry.Stack,
-			entry.Caller.Function,
-			args...)
-	}
+func (logger *Logger) Warn(ctx *ContextMap, args ...interface{}) {
+	logger.log(ctx, zapcore.WarnLevel, fmt.Sprint(args...), nil)
 }
 
-// Warnf logs a message with Error level if the global log level is not set to "error".
+// Warnf logs a formatted message with Warn level if the log level is not set to "error" or "fatal".
 //
 // Parameters:
 //   - ctx: A ContextMap containing context information to be included in the log entry.
 //   - format: The format of the log message.
 //   - args: The log message.
-func (logger *loggerWrapper) Warnf(ctx *ContextMap, format string, args ...interface{}) {
-	if (LOG_LEVEL == "fatal") || (LOG_LEVEL == "error") {
-		return
-	}
-
-	fields := []zap.Field{zap.Any("Arguments", args)}
-	logger.lw.Warn(fmt.Sprintf(format, args...), fields...)
-
-	entry := logger.lw.Check(zapcore.WarnLevel, format)
-	if entry != nil {
-		go sendLogs(
-			ctx,
-			entry.Level,
-			entry.Time,
-			fmt.Sprintf(format, args...),
-			entry.Caller,
-			entry.Stack,
-			entry.Caller.Function,
-			args...)
-	}
+func (logger *Logger) Warnf(ctx *ContextMap, format string, args ...interface{}) {
+	logger.log(ctx, zapcore.WarnLevel, fmt.Sprintf(format, args...), args)
 }
 
-// Info logs a message with Error level if the global log level is not set to "warn".
+// Info logs a message with Info level if the log level is not set to "warn", "error" or "fatal".
 //
 // Parameters:
 //   - ctx: A ContextMap containing context information to be included in the log entry.
 //   - args: The log message.
-func (logger *loggerWrapper) Info(ctx *ContextMap, args ...interface{}) {
-	if (LOG_LEVEL == "fatal") || (LOG_LEVEL == "error") || (LOG_LEVEL == "warn") {
-		return
-	}
-
-	logger.lw.Info(fmt.Sprint(args...))
-
-	entry := logger.lw.Check(zapcore.InfoLevel, fmt.Sprint(args...))
-	if entry != nil {
-		go sendLogs(
-			ctx,
-			entry.Level,
-			entry.Time,
-			entry.Message,
-			entry.Caller,
-			entry.Stack,
-			entry.Caller.Function,
-			args...)
-	}
+func (logger *Logger) Info(ctx *ContextMap, args ...interface{}) {
+	logger.log(ctx, zapcore.InfoLevel, fmt.Sprint(args...), nil)
 }
 
-// Infof logs a message with Error level if the global log level is not set to "warn".
+// Infof logs a formatted message with Info level if the log level is not set to "warn", "error" or "fatal".
 //
 // Parameters:
 //   - ctx: A ContextMap containing context information to be included in the log entry.
 //   - format: The format of the log message.
 //   - args: The log message.
-func (logger *loggerWrapper) Infof(ctx *ContextMap, format string, args ...interface{}) {
-	if (LOG_LEVEL == "fatal") || (LOG_LEVEL == "error") || (LOG_LEVEL == "warn") {
-		return
-	}
-
-	fields := []zap.Field{zap.Any("Arguments", args)}
-	logger.lw.Info(fmt.Sprintf(format, args...), fields...)
-
-	entry := logger.lw.Check(zapcore.InfoLevel, format)
-	if entry != nil {
-		go sendLogs(
-			ctx,
-			entry.Level,
-			entry.Time,
-			fmt.Sprintf(format, args...),
-			entry.Caller,
-			entry.Stack,
-			entry.Caller.Function,
-			args...)
-	}
+func (logger *Logger) Infof(ctx *ContextMap, format string, args ...interface{}) {
+	logger.log(ctx, zapcore.InfoLevel, fmt.Sprintf(format, args...), args)
 }
 
-// Debugf logs a formatted message with Debug level if the global log level is set to "debug."
+// Debugf logs a formatted message with Debug level if the log level is set to "debug".
 //
 // Parameters:
 //   - ctx: A ContextMap containing context information to be included in the log entry.
 //   - format: The format of the log message.
 //   - args: The log message.
-func (logger *loggerWrapper) Debugf(ctx *ContextMap, format string, args ...interface{}) {
-	if LOG_LEVEL != "debug" {
-		return
-	}
+func (logger *Logger) Debugf(ctx *ContextMap, format string, args ...interface{}) {
+	logger.log(ctx, zapcore.DebugLevel, fmt.Sprintf(format, args...), args)
+}
 
-	fields := []zap.Field{zap.Any("Arguments", args)}
-	logger.lw.Debug(fmt.Sprintf(format, args...), fields...)
+// fatal writes a Fatal entry to all sinks, records it in the error file, closes the sinks and terminates the program.
+//
+// Parameters:
+//   - ctx: A ContextMap containing context information to be included in the log entry.
+//   - message: The log message.
+//   - args: The arguments of a formatted message.
+func (logger *Logger) fatal(ctx *ContextMap, message string, args []interface{}) {
+	logger.write(logger.newEntry(ctx, zapcore.FatalLevel, message, args))
 
-	entry := logger.lw.Check(zapcore.DebugLevel, format)
-	if entry != nil {
-		go sendLogs(
-			ctx,
-			entry.Level,
-			entry.Time,
-			fmt.Sprintf(format, args...),
-			entry.Caller,
-			entry.Stack,
-			entry.Caller.Function,
-			args...)
+	pan := writeStringToFile(logger.config.ErrorFileLocation, "Program terminated with Fatal Error:")
+	if pan != nil {
+		panic(pan)
 	}
+	pan = writeInterfaceToFile(logger.config.ErrorFileLocation, message)
+	if pan != nil {
+		panic(pan)
+	}
+
+	_ = logger.Close()
+	os.Exit(1)
 }
 
-// Metrics sends a metric event with the specified name and count to Datadog if Datadog metrics are enabled.
+// log writes an entry to the sinks if its level is enabled for the calling component and the outputs.
 //
 // Parameters:
-//   - name: The name of the metric.
-//   - count: The value of the metric.
-func (logger *loggerWrapper) Metrics(name string, count float64) {
-	if !DATADOG_METRICS {
+//   - ctx: A ContextMap containing context information to be included in the log entry.
+//   - level: The level of the entry.
+//   - message: The log message.
+//   - args: The arguments of a formatted message.
+func (logger *Logger) log(ctx *ContextMap, level zapcore.Level, message string, args []interface{}) {
+	state := logger.levelState()
+	if len(state.components) == 0 && level < state.level {
 		return
 	}
-
-	go sendMetrics(name, count)
+	entry := logger.newEntry(ctx, level, message, args)
+	if level < state.minimumLevel(entry) {
+		return
+	}
+	logger.write(entry)
 }
 
-///////////////////////////////////
-// Datadog logging helper functions
-///////////////////////////////////
-
-// sendLogs sends log entries to Datadog or writes them to a local file, depending on the global configuration settings. It formats log entries and prepares them for transmission.
-
-// The function constructs a log entry with the specified parameters and context, and then sends it to Datadog if enabled in the global configuration. It also writes log entries to a local file if local logs are enabled.
-// If any errors occur during this process, they are logged and written to the local error log file.
+// newEntry creates a log entry for the caller of the logging function.
+//
 // Parameters:
 //   - ctx: A ContextMap containing context information to be included in the log entry.
-//   - level: The log entry's severity level (e.g., Debug, Info, Error).
-//   - time: The timestamp of the log entry.
+//   - level: The level of the entry.
 //   - message: The log message.
-//   - caller: Information about the caller of the log entry.
-//   - stack: The stack trace of the log entry.
-//   - function: The function where the log entry was created.
-//   - arguments: Additional log entry arguments.
-func sendLogs(ctx *ContextMap, level zapcore.Level, time time.Time, message string, caller zapcore.EntryCaller, stack string, function string, arguments ...interface{}) {
-	defer func() {
-		r := recover()
-		if r != nil {
-			message := "Error occurred during sendLogs:"
-			pan := writeStringToFile(ERROR_FILE_LOCATION, message)
-			if pan != nil {
-				panic(pan)
-			}
-			pan = writeInterfaceToFile(ERROR_FILE_LOCATION, r)
-			if pan != nil {
-				panic(pan)
-			}
-			return
-		}
-	}()
-	// Convert everything to string
-	levelString := levelToString(level)
-	timeString := timeToString(time)
-	callerString := entryCallerToString(caller)
-
-	// Create rest API call body structure
-	body := []map[string]interface{}{
-		{
-			"ddsource":  DATADOG_SOURCE,
-			"ddtags":    "env:" + DATADOG_STAGE + ",version:" + DATADOG_VERSION,
-			"message":   message,
-			"time":      timeString,
-			"service":   DATADOG_SERVICE_NAME,
-			"caller":    callerString,
-			"stack":     stack,
-			"function":  function,
-			"status":    levelString,
-			"arguments": arguments,
-		},
-	}
-
-	// Append body with context
-	ctx.data.Range(func(key, value interface{}) bool {
-		body[0][string(key.(ContextKey))] = value
-		return true
-	})
-
-	// Convert body to JSON
-	bodyJSON, err := mapsToJSONBytes(body)
-	if err != nil {
-		message := "Error occurred during mapsToJSONBytes in sendLogs: %v"
-		pan := writeStringToFile(ERROR_FILE_LOCATION, fmt.Sprintf(message, body))
-		if pan != nil {
-			panic(pan)
-		}
-		pan2 := writeInterfaceToFile(ERROR_FILE_LOCATION, err)
-		if pan2 != nil {
-			panic(pan2)
-		}
-	}
-
-	if LOCAL_LOGS {
-
-		// Write logs to local file
-		err := writeInterfaceToFile(LOCAL_LOGS_LOCATION, body)
-		if err != nil {
-			message := "Error occurred in writeInterfaceToFile:"
-			pan := writeStringToFile(ERROR_FILE_LOCATION, message)
-			if pan != nil {
-				panic(pan)
-			}
-			pan2 := writeInterfaceToFile(ERROR_FILE_LOCATION, err)
-			if pan2 != nil {
-				panic(pan2)
-			}
+//   - args: The arguments of a formatted message.
+//
+// Returns:
+//   - Entry: The log entry.
+func (logger *Logger) newEntry(ctx *ContextMap, level zapcore.Level, message string, args []interface{}) Entry {
+	// Skip newEntry, log or fatal, and the logging function
+	pc, file, line, ok := runtime.Caller(3)
+	entry := Entry{
+		Level:     level,
+		Time:      time.Now(),
+		Message:   message,
+		Caller:    zapcore.NewEntryCaller(pc, file, line, ok),
+		Arguments: args,
+		Context:   map[string]interface{}{},
+	}
+	if function := runtime.FuncForPC(pc); ok && function != nil {
+		entry.Function = function.Name()
+		entry.Caller.Function = entry.Function
+	}
+	if level >= zapcore.ErrorLevel {
+		entry.Stack = stacktrace(4)
+	}
+	if ctx != nil {
+		ctx.data.Range(func(key, value interface{}) bool {
+			entry.Context[string(key.(ContextKey))] = value
+			return true
+		})
+		spanContext := spanContextFromMap(ctx)
+		if spanContext.IsValid() {
+			entry.TraceID = spanContext.TraceID().String()
+			entry.SpanID = spanContext.SpanID().String()
 		}
-
 	}
+	return entry
+}
 
-	if DATADOG_LOGS {
-		if DATADOG_API_KEY == "" || DATADOG_LOGS_URL == "" {
-			message := "'DATADOG_LOGS' set to 'true' in 'config.yaml' file but 'DATADOG_API_KEY' and/or 'DATADOG_LOGS_URL' were not defined"
-			pan := writeStringToFile(ERROR_FILE_LOCATION, message)
-			if pan != nil {
-				panic(pan)
-			}
-			panic(message)
+// write passes an entry to the sinks whose output level it passes. Errors of the sinks are written to the error file.
+//
+// Parameters:
+//   - entry: The log entry.
+func (logger *Logger) write(entry Entry) {
+	state := logger.levelState()
+	for _, sink := range logger.sinks {
+		if !state.outputEnabled(sink, entry.Level) {
+			continue
 		}
-		// Send POST call to datadog
-		_, err2 := sendPostRequestToDatadog(DATADOG_LOGS_URL, bodyJSON, DATADOG_API_KEY)
-		if err2 != nil {
-			message := "Error occurred during sendPostRequestToDatadog in sendLogs:"
-			pan := writeStringToFile(ERROR_FILE_LOCATION, message)
-			if pan != nil {
-				panic(pan)
-			}
-			pan2 := writeInterfaceToFile(ERROR_FILE_LOCATION, err2)
-			if pan2 != nil {
-				panic(pan2)
-			}
+		err := sink.Write(entry)
+		if err != nil {
+			reportError(logger.config.ErrorFileLocation, fmt.Sprintf("Error occurred during %T.Write:", sink), err)
 		}
 	}
 }
 
-// sendMetrics sends a metric to Datadog using the specified name and count. The function creates a metric object, converts it to JSON, and sends a POST request to Datadog's metrics endpoint.
-//
-// The function constructs a Metrics object containing the metric data and associated resource information. It then converts the object to JSON and sends it as a POST request to Datadog for metrics reporting. Any errors encountered during this process are logged and written to the local error log file.
+// stacktrace returns the stack of the current goroutine in the format of zap, one function and location per frame.
 //
 // Parameters:
-//   - name: The name of the metric.
-//   - count: The value of the metric.
-func sendMetrics(name string, count float64) {
-	defer func() {
-		r := recover()
-		if r != nil {
-			message := "Error occurred during sendMetrics:"
-			err := writeStringToFile(ERROR_FILE_LOCATION, message)
-			if err != nil {
-				fmt.Println(err)
-			}
-			err = writeInterfaceToFile(ERROR_FILE_LOCATION, r)
-			if err != nil {
-				fmt.Println(err)
-			}
-			return
-		}
-	}()
-
-	// Create metrics object
-	metrics := Metrics{
-		Series: []Metric{
-			{
-				Metric: name,
-				Type:   0,
-				Points: []Point{
-					{
-						Timestamp: time.Now().Unix(),
-						Value:     count,
-					},
-				},
-				Resources: []Resource{
-					{
-						Type: "host",
-					},
-				},
-			},
-		},
-	}
-
-	// Convert to json
-	jsonBody, err := json.Marshal(metrics)
-	if err != nil {
-		fmt.Println("Error creating JSON:", err)
-		return
-	}
-
-	// Send POST call to datadog
-	_, err2 := sendPostRequestToDatadog(DATADOG_METRICS_URL, jsonBody, DATADOG_API_KEY)
-	if err2 != nil {
-		message := "Error occurred during sendPostRequestToDatadog in sendMetrics:"
-		pan := writeStringToFile(ERROR_FILE_LOCATION, message)
-		if pan != nil {
-			panic(pan)
-		}
-		pan2 := writeInterfaceToFile(ERROR_FILE_LOCATION, err2)
-		if pan2 != nil {
-			panic(pan2)
+//   - skip: The number of frames to skip.
+//
+// Returns:
+//   - string: The stack trace.
+func stacktrace(skip int) string {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(skip+1, pcs)])
+	lines := []string{}
+	for {
+		frame, more := frames.Next()
+		lines = append(lines, fmt.Sprintf("%s\n\t%s:%d", frame.Function, frame.File, frame.Line))
+		if !more {
+			break
 		}
 	}
+	return strings.Join(lines, "\n")
 }
 
-// sendPostRequestToDatadog sends the metric or logs post request to Datadog.
+///////////////////////////////////
+// Datadog logging helper functions
+///////////////////////////////////
+
+// sendPostRequestToDatadog sends the metric or logs post request to Datadog. The body is gzip compressed.
 //
 // Parameters:
+//   - client: The HTTP client used to send the request.
 //   - url: The URL to which the request is sent.
 //   - requestBody: The request body.
 //   - apiKey: The Datadog API key.
 //
 // Returns:
 //   - *http.Response: The response from the POST request.
-//   - error: An error if the POST request fails.
-func sendPostRequestToDatadog(url string, requestBody []byte, apiKey string) (*http.Response, error) {
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(requestBody))
+//   - error: An error if the POST request fails; it wraps a *StatusError if the request is not accepted.
+func sendPostRequestToDatadog(client *http.Client, url string, requestBody []byte, apiKey string) (*http.Response, error) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write(requestBody)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, &compressed)
 	if err != nil {
 		return nil, err
 	}
@@ -605,22 +618,15 @@ func sendPostRequestToDatadog(url string, requestBody []byte, apiKey string) (*h
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("DD-API-KEY", apiKey)
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
 
-	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode != 202 {
-		message := "Response of sendPostRequestToDatadog is unequal to 202 Acceptepted:"
-		err := writeInterfaceToFile(ERROR_FILE_LOCATION, message)
-		if err != nil {
-			fmt.Println(err)
-		}
-		err = writeInterfaceToFile(ERROR_FILE_LOCATION, resp.Status)
-		if err != nil {
-			fmt.Println(err)
-		}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return resp, fmt.Errorf("response of sendPostRequestToDatadog is unequal to 202 Accepted: %w", newStatusError(resp))
 	}
 
 	return resp, nil
@@ -710,17 +716,11 @@ func writeInterfaceToFile(filename string, data interface{}) error {
 	defer file.Close()
 
 	// write to file
-	jsonData, err := json.Marshal(data)
+	line, err := fileLine(data)
 	if err != nil {
 		return err
 	}
-
-	// add time
-	timestamp := timeToString(time.Now())
-
-	// write to file
-	line := fmt.Sprintf("%s: %s\n", timestamp, string(jsonData))
-	_, err = file.Write([]byte(line))
+	_, err = file.Write(line)
 	if err != nil {
 		return err
 	}
@@ -728,6 +728,26 @@ func writeInterfaceToFile(filename string, data interface{}) error {
 	return nil
 }
 
+// fileLine formats structured data as a line of a log file: a timestamp followed by the data in JSON format.
+//
+// Parameters:
+//   - data: The structured data.
+//
+// Returns:
+//   - []byte: The line, including the line break.
+//   - error: An error if the data cannot be converted to JSON.
+func fileLine(data interface{}) ([]byte, error) {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// add time
+	timestamp := timeToString(time.Now())
+
+	return []byte(fmt.Sprintf("%s: %s\n", timestamp, string(jsonData))), nil
+}
+
 // writeStringToFile appends a string message to a file, including a timestamp.
 //
 // Parameters:
@@ -765,3 +785,22 @@ func writeStringToFile(filename string, data string) error {
 	_, err = fmt.Fprintln(file, data)
 	return err
 }
+
+// reportError writes a message and an error to the error file; if the error file cannot be written, both are printed.
+//
+// Parameters:
+//   - filename: The name of the error file.
+//   - message: The message describing where the error occurred.
+//   - err: The error.
+func reportError(filename string, message string, err interface{}) {
+	if e, ok := err.(error); ok {
+		err = e.Error()
+	}
+	pan := writeStringToFile(filename, message)
+	if pan == nil {
+		pan = writeInterfaceToFile(filename, err)
+	}
+	if pan != nil {
+		fmt.Println(message, err)
+	}
+}
@@ -0,0 +1,478 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults of the metrics aggregation, used for the options that are not set.
+const defaultMetricsInterval = 10 * time.Second
+
+// defaultHistogramBuckets are the upper bounds of the Prometheus default histogram buckets, suited for latencies in seconds.
+var defaultHistogramBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// invalidPrometheusName matches the characters not allowed in Prometheus metric and label names.
+var invalidPrometheusName = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+
+////////////////////////////////
+// Recording metrics
+////////////////////////////////
+
+// Count adds a value to a counter, e.g. the number of handled requests.
+// Counters are aggregated in process and sent to Datadog as the increase over each interval.
+//
+// Parameters:
+//   - name: The name of the metric.
+//   - value: The increment; negative values are ignored.
+//   - tags: Tags of the series in the form "key:value", e.g. "endpoint:/run".
+func (logger *Logger) Count(name string, value float64, tags ...string) {
+	if value < 0 {
+		return
+	}
+	logger.registry.record(name, "counter", tags, func(series *metricSeries) {
+		series.value += value
+	})
+}
+
+// Gauge sets the current value of a gauge, e.g. the depth of a queue.
+//
+// Parameters:
+//   - name: The name of the metric.
+//   - value: The current value.
+//   - tags: Tags of the series in the form "key:value".
+func (logger *Logger) Gauge(name string, value float64, tags ...string) {
+	logger.registry.record(name, "gauge", tags, func(series *metricSeries) {
+		series.value = value
+	})
+}
+
+// Histogram records an observation of a histogram, e.g. the latency of a request in seconds.
+// Datadog receives the count, sum, average, minimum and maximum of each interval as <name>.count, <name>.sum,
+// <name>.avg, <name>.min and <name>.max; Prometheus receives the buckets of HistogramBuckets.
+//
+// Parameters:
+//   - name: The name of the metric.
+//   - value: The observed value.
+//   - tags: Tags of the series in the form "key:value".
+func (logger *Logger) Histogram(name string, value float64, tags ...string) {
+	logger.registry.record(name, "histogram", tags, func(series *metricSeries) {
+		if series.count == series.flushedCount || value < series.min {
+			series.min = value
+		}
+		if series.count == series.flushedCount || value > series.max {
+			series.max = value
+		}
+		series.count++
+		series.sum += value
+		index := sort.SearchFloat64s(logger.registry.bucketBounds(), value)
+		series.bucketCounts[index]++
+	})
+}
+
+// Metrics adds a value to a counter.
+//
+// Deprecated: Use Count, Gauge or Histogram, which support tags.
+//
+// Parameters:
+//   - name: The name of the metric.
+//   - count: The value of the metric.
+func (logger *Logger) Metrics(name string, count float64) {
+	logger.Count(name, count)
+}
+
+// record updates the series of a metric and tag set, creating it if it does not exist.
+// A metric name keeps the kind it was first recorded with; updates with another kind are reported to the error file.
+// Names that only differ from an existing name in characters sanitized for Prometheus, e.g. "a.b" and "a_b",
+// are also reported to the error file, as they would be exposed as the same metric.
+//
+// Parameters:
+//   - name: The name of the metric.
+//   - kind: The kind of the metric: counter, gauge or histogram.
+//   - tags: The tags of the series.
+//   - update: Updates the series; called while the registry is locked.
+func (registry *metricsRegistry) record(name string, kind string, tags []string, update func(series *metricSeries)) {
+	tags = normalizeTags(tags)
+	key := name + "|" + strings.Join(tags, ",")
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	if registry.series == nil {
+		registry.series = map[string]*metricSeries{}
+	}
+	series, ok := registry.series[key]
+	if !ok {
+		for _, existing := range registry.series {
+			if existing.name != name && prometheusName(existing.name) == prometheusName(name) {
+				reportError(registry.errorFile, "Error occurred during metric recording:", fmt.Errorf("metric '%v' collides with metric '%v'", name, existing.name))
+				return
+			}
+			if existing.name == name && existing.kind != kind {
+				reportError(registry.errorFile, "Error occurred during metric recording:", fmt.Errorf("metric '%v' is a %v, not a %v", name, existing.kind, kind))
+				return
+			}
+		}
+		series = &metricSeries{name: name, kind: kind, tags: tags}
+		if kind == "histogram" {
+			series.bucketCounts = make([]uint64, len(registry.bucketBounds())+1)
+		}
+		registry.series[key] = series
+	} else if series.kind != kind {
+		reportError(registry.errorFile, "Error occurred during metric recording:", fmt.Errorf("metric '%v' is a %v, not a %v", name, series.kind, kind))
+		return
+	}
+	update(series)
+}
+
+// bucketBounds returns the upper bounds of the histogram buckets; the last bucket has no upper bound.
+//
+// Returns:
+//   - []float64: The sorted upper bounds.
+func (registry *metricsRegistry) bucketBounds() []float64 {
+	if len(registry.buckets) == 0 {
+		return defaultHistogramBuckets
+	}
+	return registry.buckets
+}
+
+// normalizeTags sorts and deduplicates the tags of a series, so the order of the tags does not matter.
+//
+// Parameters:
+//   - tags: The tags.
+//
+// Returns:
+//   - []string: The sorted unique tags.
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag != "" {
+			normalized = append(normalized, tag)
+		}
+	}
+	sort.Strings(normalized)
+	unique := normalized[:0]
+	for i, tag := range normalized {
+		if i == 0 || tag != normalized[i-1] {
+			unique = append(unique, tag)
+		}
+	}
+	return unique
+}
+
+////////////////////////////////
+// Datadog
+////////////////////////////////
+
+// runMetricsFlush queues the aggregated metrics for Datadog every interval until the logger is closed.
+//
+// Parameters:
+//   - interval: The aggregation interval.
+func (logger *Logger) runMetricsFlush(interval time.Duration) {
+	defer close(logger.metricsDone)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			logger.flushMetrics(time.Now())
+		case <-logger.metricsStop:
+			return
+		}
+	}
+}
+
+// stopMetricsFlush stops the goroutine started by runMetricsFlush, if any.
+func (logger *Logger) stopMetricsFlush() {
+	if logger.metricsStop == nil {
+		return
+	}
+	logger.metricsStopOnce.Do(func() {
+		close(logger.metricsStop)
+		<-logger.metricsDone
+	})
+}
+
+// flushMetrics queues the metrics aggregated since the last flush for Datadog, if Datadog metrics are enabled.
+//
+// Parameters:
+//   - now: The time of the data points.
+func (logger *Logger) flushMetrics(now time.Time) {
+	if logger.metrics == nil {
+		return
+	}
+	for _, metric := range logger.registry.datadogSeries(now) {
+		item, err := json.Marshal(metric)
+		if err != nil {
+			reportError(logger.config.ErrorFileLocation, "Error creating JSON in flushMetrics:", err)
+			continue
+		}
+		logger.metrics.enqueue(item)
+	}
+}
+
+// datadogSeries returns the Datadog series of the metrics since the last flush and starts a new interval.
+// Counters and histograms without new values are skipped; gauges are sent with their last value.
+//
+// Parameters:
+//   - now: The time of the data points.
+//
+// Returns:
+//   - []Metric: The series, sorted by name and tags.
+func (registry *metricsRegistry) datadogSeries(now time.Time) []Metric {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	interval := int64(defaultMetricsInterval / time.Second)
+	if !registry.lastFlush.IsZero() {
+		interval = int64(math.Max(1, math.Round(now.Sub(registry.lastFlush).Seconds())))
+	}
+	registry.lastFlush = now
+
+	var resources []Resource
+	if registry.hostname != "" {
+		resources = []Resource{{Name: registry.hostname, Type: "host"}}
+	}
+	newMetric := func(name string, metricType MetricType, value float64, tags []string) Metric {
+		metric := Metric{
+			Metric:    name,
+			Type:      metricType,
+			Points:    []Point{{Timestamp: now.Unix(), Value: value}},
+			Tags:      tags,
+			Resources: resources,
+		}
+		if metricType == MetricTypeCount {
+			metric.Interval = interval
+		}
+		return metric
+	}
+
+	metrics := []Metric{}
+	for _, series := range registry.sortedSeries() {
+		switch series.kind {
+		case "counter":
+			if series.value == series.flushedValue {
+				continue
+			}
+			metrics = append(metrics, newMetric(series.name, MetricTypeCount, series.value-series.flushedValue, series.tags))
+			series.flushedValue = series.value
+		case "gauge":
+			metrics = append(metrics, newMetric(series.name, MetricTypeGauge, series.value, series.tags))
+		case "histogram":
+			count := series.count - series.flushedCount
+			if count == 0 {
+				continue
+			}
+			sum := series.sum - series.flushedSum
+			metrics = append(metrics,
+				newMetric(series.name+".count", MetricTypeCount, float64(count), series.tags),
+				newMetric(series.name+".sum", MetricTypeCount, sum, series.tags),
+				newMetric(series.name+".avg", MetricTypeGauge, sum/float64(count), series.tags),
+				newMetric(series.name+".min", MetricTypeGauge, series.min, series.tags),
+				newMetric(series.name+".max", MetricTypeGauge, series.max, series.tags),
+			)
+			series.flushedCount = series.count
+			series.flushedSum = series.sum
+		}
+	}
+	return metrics
+}
+
+// sortedSeries returns the series sorted by name and tags; the registry must be locked.
+//
+// Returns:
+//   - []*metricSeries: The series.
+func (registry *metricsRegistry) sortedSeries() []*metricSeries {
+	keys := make([]string, 0, len(registry.series))
+	for key := range registry.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	series := make([]*metricSeries, 0, len(keys))
+	for _, key := range keys {
+		series = append(series, registry.series[key])
+	}
+	return series
+}
+
+////////////////////////////////
+// Prometheus
+////////////////////////////////
+
+// MetricsHandler returns a handler exposing the counters, gauges and histograms of the logger in the Prometheus
+// text format, to be served at e.g. /metrics. Metric names and tag keys are sanitized for Prometheus (see
+// prometheusLabels). The values are totals since the start of the process.
+//
+// Returns:
+//   - http.Handler: The handler.
+func (logger *Logger) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = writer.Write([]byte(logger.registry.prometheusText()))
+	})
+}
+
+// prometheusText formats the metrics in the Prometheus text exposition format.
+//
+// Returns:
+//   - string: The exposition.
+func (registry *metricsRegistry) prometheusText() string {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	// Series are grouped by their Prometheus name, so each metric has a single TYPE line; record rejects colliding
+	// names, series of another metric with the same Prometheus name are skipped in case one slips through
+	names := []string{}
+	groups := map[string][]*metricSeries{}
+	for _, series := range registry.sortedSeries() {
+		name := prometheusName(series.name)
+		group, ok := groups[name]
+		if !ok {
+			names = append(names, name)
+		} else if group[0].name != series.name || group[0].kind != series.kind {
+			continue
+		}
+		groups[name] = append(group, series)
+	}
+	sort.Strings(names)
+
+	var builder strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&builder, "# TYPE %v %v\n", name, groups[name][0].kind)
+		for _, series := range groups[name] {
+			registry.writePrometheusSeries(&builder, name, series)
+		}
+	}
+	return builder.String()
+}
+
+// writePrometheusSeries writes the samples of a series in the Prometheus text exposition format.
+//
+// Parameters:
+//   - builder: The exposition being built.
+//   - name: The Prometheus name of the metric.
+//   - series: The series.
+func (registry *metricsRegistry) writePrometheusSeries(builder *strings.Builder, name string, series *metricSeries) {
+	labels := prometheusLabels(series.tags)
+	switch series.kind {
+	case "counter", "gauge":
+		fmt.Fprintf(builder, "%v%v %v\n", name, formatLabels(labels), formatFloat(series.value))
+	case "histogram":
+		cumulative := uint64(0)
+		for i, bound := range registry.bucketBounds() {
+			cumulative += series.bucketCounts[i]
+			fmt.Fprintf(builder, "%v_bucket%v %v\n", name, formatLabels(append(labels, "le", formatFloat(bound))), cumulative)
+		}
+		fmt.Fprintf(builder, "%v_bucket%v %v\n", name, formatLabels(append(labels, "le", "+Inf")), series.count)
+		fmt.Fprintf(builder, "%v_sum%v %v\n", name, formatLabels(labels), formatFloat(series.sum))
+		fmt.Fprintf(builder, "%v_count%v %v\n", name, formatLabels(labels), series.count)
+	}
+}
+
+// prometheusName replaces the characters not allowed in Prometheus names, e.g. dots, with underscores.
+//
+// Parameters:
+//   - name: The metric or tag name.
+//
+// Returns:
+//   - string: The Prometheus name.
+func prometheusName(name string) string {
+	name = invalidPrometheusName.ReplaceAllString(name, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// prometheusLabels converts tags of the form "key:value" to label name and value pairs sorted by name.
+// Prometheus does not allow repeated label names, so the values of repeated keys are joined with commas,
+// e.g. "env:a" and "env:b" become env="a,b". Tags without a value, e.g. "canary", become canary="true",
+// so the series stays distinct from the one without the tag.
+//
+// Parameters:
+//   - tags: The sorted tags.
+//
+// Returns:
+//   - []string: Pairs of label name and value.
+func prometheusLabels(tags []string) []string {
+	names := []string{}
+	values := map[string][]string{}
+	for _, tag := range tags {
+		key, value, ok := strings.Cut(tag, ":")
+		if key == "" {
+			continue
+		}
+		if !ok {
+			value = "true"
+		}
+		name := strings.ReplaceAll(prometheusName(key), ":", "_")
+		if _, exists := values[name]; !exists {
+			names = append(names, name)
+		}
+		values[name] = append(values[name], value)
+	}
+	sort.Strings(names)
+
+	labels := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		labels = append(labels, name, strings.Join(values[name], ","))
+	}
+	return labels
+}
+
+// formatLabels formats label pairs as {name="value",...}, escaping the values.
+//
+// Parameters:
+//   - labels: Pairs of label name and value.
+//
+// Returns:
+//   - string: The formatted labels; empty if there are none.
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+`="`+escaper.Replace(labels[i+1])+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// formatFloat formats a sample value or bucket bound.
+//
+// Parameters:
+//   - value: The value.
+//
+// Returns:
+//   - string: The shortest representation of the value.
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
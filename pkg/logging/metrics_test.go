@@ -0,0 +1,191 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMetricsDatadog tests the aggregation of counters, gauges and histograms and their Datadog series
+func TestMetricsDatadog(t *testing.T) {
+	server := newDatadogServer(t)
+	logger, err := NewLogger(Config{
+		ErrorFileLocation: filepath.Join(t.TempDir(), "error.log"),
+		DatadogAPIKey:     "key",
+		DatadogMetrics:    true,
+		DatadogMetricsURL: server.URL,
+		MetricsInterval:   time.Hour,
+	}, &memorySink{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close()
+
+	// The order of the tags does not matter; a metric name keeps its kind
+	logger.Count("requests", 1, "endpoint:/run", "method:POST")
+	logger.Count("requests", 2, "method:POST", "endpoint:/run")
+	logger.Gauge("requests", 5)
+	logger.Gauge("queue.depth", 3)
+	logger.Gauge("queue.depth", 7)
+	logger.Histogram("latency", 0.4)
+	logger.Histogram("latency", 0.2)
+	err = logger.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]struct {
+		metricType MetricType
+		value      float64
+	}{
+		"requests":      {MetricTypeCount, 3},
+		"queue.depth":   {MetricTypeGauge, 7},
+		"latency.count": {MetricTypeCount, 2},
+		"latency.sum":   {MetricTypeCount, 0.6000000000000001},
+		"latency.avg":   {MetricTypeGauge, 0.30000000000000004},
+		"latency.min":   {MetricTypeGauge, 0.2},
+		"latency.max":   {MetricTypeGauge, 0.4},
+	}
+	series := receivedSeries(t, server)
+	if len(series) != len(expected) {
+		t.Fatalf("Expected %d series, got %+v", len(expected), series)
+	}
+	for _, metric := range series {
+		want, ok := expected[metric.Metric]
+		if !ok || metric.Type != want.metricType || len(metric.Points) != 1 || metric.Points[0].Value != want.value {
+			t.Errorf("Unexpected series %+v", metric)
+		}
+		if metric.Type == MetricTypeCount && metric.Interval < 1 {
+			t.Errorf("Expected interval for count series %+v", metric)
+		}
+	}
+	// Series are sorted by name and tags
+	requests := series[len(series)-1]
+	if requests.Metric != "requests" || !reflect.DeepEqual(requests.Tags, []string{"endpoint:/run", "method:POST"}) {
+		t.Errorf("Expected sorted tags, got %+v", requests)
+	}
+
+	// Without new values, only the gauge is sent again
+	err = logger.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	series = receivedSeries(t, server)[len(expected):]
+	if len(series) != 1 || series[0].Metric != "queue.depth" || series[0].Points[0].Value != 7 {
+		t.Errorf("Expected only the gauge, got %+v", series)
+	}
+}
+
+// receivedSeries decodes the series of the metrics requests received by a Datadog server.
+func receivedSeries(t *testing.T, server *datadogServer) []Metric {
+	series := []Metric{}
+	for _, body := range server.received() {
+		var metrics Metrics
+		err := json.Unmarshal(body, &metrics)
+		if err != nil {
+			t.Fatal(err)
+		}
+		series = append(series, metrics.Series...)
+	}
+	return series
+}
+
+// TestMetricsHandler tests the Prometheus text exposition
+func TestMetricsHandler(t *testing.T) {
+	logger, err := NewLogger(Config{HistogramBuckets: []float64{1, 0.5}}, &memorySink{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Count("http.requests", 2, "code:200", "path:/run")
+	logger.Count("http.requests", 1, "code:500", "path:/run", "canary")
+	logger.Count("http.requests", 5, "code:500", "path:/run")
+	logger.Count("http.requests", 3, "code:200", "env:b", "env:a")
+	logger.Gauge("queue_depth", 4, `name:a"b`)
+	logger.Histogram("latency", 0.3)
+	logger.Histogram("latency", 0.7)
+	logger.Histogram("latency", 3)
+
+	recorder := httptest.NewRecorder()
+	logger.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	expected := `# TYPE http_requests counter
+http_requests{canary="true",code="500",path="/run"} 1
+http_requests{code="200",env="a,b"} 3
+http_requests{code="200",path="/run"} 2
+http_requests{code="500",path="/run"} 5
+# TYPE latency histogram
+latency_bucket{le="0.5"} 1
+latency_bucket{le="1"} 2
+latency_bucket{le="+Inf"} 3
+latency_sum 4
+latency_count 3
+# TYPE queue_depth gauge
+queue_depth{name="a\"b"} 4
+`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Expected exposition:\n%v\ngot:\n%v", expected, body)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "text/plain; version=0.0.4; charset=utf-8" {
+		t.Errorf("Unexpected content type %v", contentType)
+	}
+}
+
+// TestMetricsHandlerCollisions tests that names colliding once sanitized and duplicate buckets are not exposed twice
+func TestMetricsHandlerCollisions(t *testing.T) {
+	errorFile := filepath.Join(t.TempDir(), "error.log")
+	logger, err := NewLogger(Config{ErrorFileLocation: errorFile, HistogramBuckets: []float64{1, 1}}, &memorySink{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Count("a.b", 1)
+	logger.Gauge("a_b", 2)
+	logger.Histogram("latency", 0.5)
+
+	recorder := httptest.NewRecorder()
+	logger.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	expected := `# TYPE a_b counter
+a_b 1
+# TYPE latency histogram
+latency_bucket{le="1"} 1
+latency_bucket{le="+Inf"} 1
+latency_sum 0.5
+latency_count 1
+`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Expected exposition:\n%v\ngot:\n%v", expected, body)
+	}
+	content, err := os.ReadFile(errorFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "metric 'a_b' collides with metric 'a.b'") {
+		t.Errorf("Expected the collision in the error file, got %q", content)
+	}
+}
@@ -0,0 +1,430 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package logging
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+///////////////////////////////////
+// Rotating log file
+///////////////////////////////////
+
+// defaultMaxSize is the size at which a rotating file is rotated if no size is configured.
+const defaultMaxSize = 100 * 1024 * 1024
+
+// rotationTimeFormat is the timestamp of rotated files; it sorts in chronological order and is valid in file names.
+const rotationTimeFormat = "2006-01-02T15-04-05.000"
+
+// NewRotatingFile opens a rotating file for appending, creating it and its directory if needed.
+//
+// Parameters:
+//   - path: The path of the file.
+//   - options: The rotation and retention options.
+//
+// Returns:
+//   - *RotatingFile: The file.
+//   - error: An error if the file cannot be opened.
+func NewRotatingFile(path string, options RotationOptions) (*RotatingFile, error) {
+	if options.MaxSize <= 0 {
+		options.MaxSize = defaultMaxSize
+	}
+	file := &RotatingFile{path: path, options: options, now: time.Now, rename: os.Rename}
+	err := file.open()
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// Write appends data to the file. The file is rotated before the write if the data would exceed the maximum size,
+// or if the rotation interval has passed since the file was opened. If the rotation fails, the data is appended
+// to the current file and the error is written to the error file.
+//
+// Parameters:
+//   - data: The data to write; it is never split between two files.
+//
+// Returns:
+//   - int: The number of bytes written.
+//   - error: An error if the file is closed or cannot be opened or written.
+func (file *RotatingFile) Write(data []byte) (int, error) {
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+
+	if file.closed {
+		return 0, os.ErrClosed
+	}
+	if file.file != nil && file.dueForRotation(int64(len(data))) {
+		err := file.rotate()
+		if err != nil {
+			reportError(file.options.ErrorFileLocation, "Error occurred while rotating log file:", err)
+		}
+	}
+	if file.file == nil {
+		// A previous rotation could not reopen the file
+		err := file.open()
+		if err != nil {
+			return 0, err
+		}
+	}
+	written, err := file.file.Write(data)
+	file.size += int64(written)
+	return written, err
+}
+
+// Rotate closes the current file, renames it with a timestamp and opens a new file.
+// If the file cannot be renamed, the current file is reopened and writing continues there.
+//
+// Returns:
+//   - error: An error if the file cannot be renamed or reopened.
+func (file *RotatingFile) Rotate() error {
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+
+	if file.closed {
+		return os.ErrClosed
+	}
+	if file.file == nil {
+		return file.open()
+	}
+	return file.rotate()
+}
+
+// Sync commits the written data to disk.
+//
+// Returns:
+//   - error: An error if the file cannot be synced.
+func (file *RotatingFile) Sync() error {
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+
+	if file.file == nil {
+		return os.ErrClosed
+	}
+	return file.file.Sync()
+}
+
+// Close closes the file and waits for the compression and cleanup of rotated files.
+//
+// Returns:
+//   - error: An error if the file cannot be closed.
+func (file *RotatingFile) Close() error {
+	file.mutex.Lock()
+	file.closed = true
+	var err error
+	if file.file != nil {
+		err = file.file.Close()
+		file.file = nil
+	}
+	file.mutex.Unlock()
+
+	file.background.Wait()
+	return err
+}
+
+// open opens the file for appending. For an existing file, the modification time counts as opening time,
+// so time-based rotation also happens across restarts.
+//
+// Returns:
+//   - error: An error if the file cannot be opened.
+func (file *RotatingFile) open() error {
+	err := os.MkdirAll(filepath.Dir(file.path), 0755)
+	if err != nil {
+		return err
+	}
+	handle, err := os.OpenFile(file.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := handle.Stat()
+	if err != nil {
+		_ = handle.Close()
+		return err
+	}
+
+	file.file = handle
+	file.size = info.Size()
+	file.openedAt = file.now()
+	if info.Size() > 0 {
+		file.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+// dueForRotation reports whether the file has to be rotated before writing data of a size.
+// Time-based rotation happens at multiples of the interval, e.g. at midnight UTC for a 24 hour interval.
+//
+// Parameters:
+//   - size: The size of the data to write.
+//
+// Returns:
+//   - bool: True if the file has to be rotated.
+func (file *RotatingFile) dueForRotation(size int64) bool {
+	if file.size == 0 {
+		return false
+	}
+	if file.size+size > file.options.MaxSize {
+		return true
+	}
+	interval := file.options.Interval
+	return interval > 0 && !file.now().Truncate(interval).Equal(file.openedAt.Truncate(interval))
+}
+
+// rotate renames the current file with a timestamp, opens a new file and starts the compression and cleanup
+// of the rotated files in the background. The caller must hold the mutex.
+//
+// If the file cannot be renamed or the new file cannot be opened, the file at the original path is reopened
+// for appending, and the rotation is tried again after another MaxSize bytes or rotation interval.
+//
+// Returns:
+//   - error: The error of the rotation; file.file is only nil afterwards if the file cannot be reopened either.
+func (file *RotatingFile) rotate() error {
+	err := file.file.Close()
+	file.file = nil
+	rotated := file.rotatedName(file.now())
+	if err == nil {
+		err = file.rename(file.path, rotated)
+	}
+	if err == nil {
+		err = file.open()
+	}
+	if err != nil {
+		openErr := file.open()
+		if openErr == nil {
+			file.size = 0
+			file.openedAt = file.now()
+		}
+		return errors.Join(err, openErr)
+	}
+
+	file.background.Add(1)
+	go func() {
+		defer file.background.Done()
+
+		// Compression and cleanup are serialized, so a cleanup does not remove a file being compressed
+		file.cleanupMutex.Lock()
+		defer file.cleanupMutex.Unlock()
+		if file.options.Compress {
+			// The file may have been removed already by the cleanup of a later rotation
+			err := compressFile(rotated)
+			if err != nil && !os.IsNotExist(err) {
+				reportError(file.options.ErrorFileLocation, "Error occurred while compressing rotated log file:", err)
+			}
+		}
+		file.cleanup()
+	}()
+	return nil
+}
+
+// rotatedName returns an unused name for a rotated file, e.g. "logs-2006-01-02T15-04-05.000.log" for "logs.log".
+//
+// Parameters:
+//   - now: The time of the rotation.
+//
+// Returns:
+//   - string: The path of the rotated file.
+func (file *RotatingFile) rotatedName(now time.Time) string {
+	extension := filepath.Ext(file.path)
+	base := strings.TrimSuffix(file.path, extension) + "-" + now.UTC().Format(rotationTimeFormat)
+	name := base + extension
+	counter := 0
+	// Continue after the newest backup rotated in the same millisecond, even if older ones were removed
+	rotated, err := file.rotatedFiles()
+	if err == nil && len(rotated) > 0 {
+		newest, newestCounter, ok := file.parseRotatedName(filepath.Base(rotated[0]))
+		if ok && newest.Equal(now.UTC().Truncate(time.Millisecond)) {
+			counter = newestCounter + 1
+			name = fmt.Sprintf("%v.%d%v", base, counter, extension)
+		}
+	}
+	for fileExists(name) || fileExists(name+".gz") {
+		counter++
+		name = fmt.Sprintf("%v.%d%v", base, counter, extension)
+	}
+	return name
+}
+
+// cleanup removes the rotated files exceeding MaxBackups, oldest first, and those older than MaxAge.
+// The caller must hold the cleanup mutex.
+func (file *RotatingFile) cleanup() {
+	if file.options.MaxBackups <= 0 && file.options.MaxAge <= 0 {
+		return
+	}
+	rotated, err := file.rotatedFiles()
+	if err != nil {
+		reportError(file.options.ErrorFileLocation, "Error occurred while listing rotated log files:", err)
+		return
+	}
+
+	now := file.now()
+	for i, name := range rotated {
+		tooMany := file.options.MaxBackups > 0 && i >= file.options.MaxBackups
+		tooOld := false
+		if file.options.MaxAge > 0 {
+			info, err := os.Stat(name)
+			tooOld = err == nil && now.Sub(info.ModTime()) > file.options.MaxAge
+		}
+		if tooMany || tooOld {
+			err := os.Remove(name)
+			if err != nil && !os.IsNotExist(err) {
+				reportError(file.options.ErrorFileLocation, "Error occurred while removing rotated log file:", err)
+			}
+		}
+	}
+}
+
+// rotatedFiles returns the rotated files of the file, compressed or not, newest first.
+// Files are ordered by the timestamp in their name, then by the counter rotatedName adds to files rotated
+// within the same millisecond, e.g. "logs-2006-01-02T15-04-05.000.1.log" is newer than "logs-2006-01-02T15-04-05.000.log".
+//
+// Returns:
+//   - []string: The paths of the rotated files.
+//   - error: An error if the directory cannot be read.
+func (file *RotatingFile) rotatedFiles() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Dir(file.path))
+	if err != nil {
+		return nil, err
+	}
+
+	type rotatedFile struct {
+		path    string
+		time    time.Time
+		counter int
+	}
+	rotated := []rotatedFile{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		rotatedAt, counter, ok := file.parseRotatedName(entry.Name())
+		if !ok {
+			continue
+		}
+		rotated = append(rotated, rotatedFile{path: filepath.Join(filepath.Dir(file.path), entry.Name()), time: rotatedAt, counter: counter})
+	}
+	sort.Slice(rotated, func(i, j int) bool {
+		if !rotated[i].time.Equal(rotated[j].time) {
+			return rotated[i].time.After(rotated[j].time)
+		}
+		return rotated[i].counter > rotated[j].counter
+	})
+
+	paths := make([]string, 0, len(rotated))
+	for _, file := range rotated {
+		paths = append(paths, file.path)
+	}
+	return paths, nil
+}
+
+// parseRotatedName parses the name of a rotated file of the file, compressed or not.
+//
+// Parameters:
+//   - name: The base name of the file, e.g. "logs-2006-01-02T15-04-05.000.1.log.gz".
+//
+// Returns:
+//   - time.Time: The time of the rotation.
+//   - int: The counter added to files rotated within the same millisecond, 0 if there is none.
+//   - bool: Whether the name is the name of a rotated file of the file.
+func (file *RotatingFile) parseRotatedName(name string) (time.Time, int, bool) {
+	extension := filepath.Ext(file.path)
+	prefix := strings.TrimSuffix(filepath.Base(file.path), extension) + "-"
+	name = strings.TrimSuffix(name, ".gz")
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, extension) {
+		return time.Time{}, 0, false
+	}
+	timestamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), extension)
+	if len(timestamp) < len(rotationTimeFormat) {
+		return time.Time{}, 0, false
+	}
+	rotatedAt, err := time.Parse(rotationTimeFormat, timestamp[:len(rotationTimeFormat)])
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	suffix := timestamp[len(rotationTimeFormat):]
+	if suffix == "" {
+		return rotatedAt, 0, true
+	}
+	counter, err := strconv.Atoi(strings.TrimPrefix(suffix, "."))
+	if err != nil || !strings.HasPrefix(suffix, ".") || counter < 1 {
+		return time.Time{}, 0, false
+	}
+	return rotatedAt, counter, true
+}
+
+// compressFile compresses a file with gzip to a file with the ".gz" extension and removes the original.
+//
+// Parameters:
+//   - path: The path of the file.
+//
+// Returns:
+//   - error: An error if the file cannot be compressed.
+func compressFile(path string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	target, err := os.OpenFile(path+".gz.tmp", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	writer := gzip.NewWriter(target)
+	_, err = io.Copy(writer, source)
+	if err == nil {
+		err = writer.Close()
+	}
+	if closeErr := target.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(path+".gz.tmp", path+".gz")
+	}
+	if err != nil {
+		_ = os.Remove(path + ".gz.tmp")
+		return err
+	}
+	_ = source.Close()
+	return os.Remove(path)
+}
+
+// fileExists reports whether a file exists.
+//
+// Parameters:
+//   - path: The path of the file.
+//
+// Returns:
+//   - bool: True if the file exists.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
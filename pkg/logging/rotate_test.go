@@ -0,0 +1,293 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package logging
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// rotatingFileClock is a fake clock for rotating files.
+type rotatingFileClock struct {
+	mutex sync.Mutex
+	time  time.Time
+}
+
+func (clock *rotatingFileClock) now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	clock.time = clock.time.Add(time.Millisecond)
+	return clock.time
+}
+
+func (clock *rotatingFileClock) set(t time.Time) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	clock.time = t
+}
+
+// readLines returns the lines of a log file, decompressing it if needed.
+func readLines(t *testing.T, path string) []string {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		reader, err = gzip.NewReader(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := []string{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
+
+// TestRotatingFileSize tests size-based rotation with compression and backup count retention
+func TestRotatingFileSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.log")
+	file, err := NewRotatingFile(path, RotationOptions{MaxSize: 100, MaxBackups: 2, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := &rotatingFileClock{time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	file.now = clock.now
+
+	for i := 0; i < 10; i++ {
+		_, err = fmt.Fprintf(file, "line %02d %030d\n", i, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = file.Write([]byte("closed\n"))
+	if err == nil {
+		t.Errorf("Expected error when writing to a closed file")
+	}
+
+	rotated, err := file.rotatedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rotated) != 2 || !strings.HasSuffix(rotated[0], ".log.gz") {
+		t.Fatalf("Expected 2 compressed backups, got %v", rotated)
+	}
+	if lines := readLines(t, rotated[0]); len(lines) != 2 || !strings.HasPrefix(lines[0], "line 06") {
+		t.Errorf("Expected newest backup to contain lines 6 and 7, got %q", lines)
+	}
+	if lines := readLines(t, path); len(lines) != 2 || !strings.HasPrefix(lines[1], "line 09") {
+		t.Errorf("Expected current file to contain lines 8 and 9, got %q", lines)
+	}
+}
+
+// TestRotatingFileSameTimestamp tests that backups rotated within the same millisecond are ordered by their counter
+func TestRotatingFileSameTimestamp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.log")
+	file, err := NewRotatingFile(path, RotationOptions{MaxSize: 10, MaxBackups: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	file.now = func() time.Time { return now }
+
+	for i := 0; i < 4; i++ {
+		_, err = fmt.Fprintf(file, "line %02d\n", i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.background.Wait()
+	}
+	err = file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := file.rotatedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rotated) != 1 || !strings.HasSuffix(rotated[0], ".000.2.log") {
+		t.Fatalf("Expected only the newest backup, got %v", rotated)
+	}
+	if lines := readLines(t, rotated[0]); len(lines) != 1 || lines[0] != "line 02" {
+		t.Errorf("Expected newest backup to contain line 2, got %q", lines)
+	}
+}
+
+// TestRotatingFileRenameFailure tests that writing continues in the current file if it cannot be renamed
+func TestRotatingFileRenameFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logs.log")
+	errorFile := filepath.Join(dir, "error.log")
+	file, err := NewRotatingFile(path, RotationOptions{MaxSize: 20, ErrorFileLocation: errorFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The rotated files are renamed into a regular file, which cannot be written as directory
+	blocker := filepath.Join(dir, "blocker")
+	err = os.WriteFile(blocker, nil, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.rename = func(oldPath string, newPath string) error {
+		return os.Rename(oldPath, filepath.Join(blocker, filepath.Base(newPath)))
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err = fmt.Fprintf(file, "line %02d %010d\n", i, 0)
+		if err != nil {
+			t.Fatalf("Expected write to succeed despite the failed rotation, got %v", err)
+		}
+	}
+	err = file.Rotate()
+	if err == nil {
+		t.Errorf("Expected error of the failed rotation")
+	}
+	_, err = fmt.Fprintf(file, "line %02d %010d\n", 3, 0)
+	if err != nil {
+		t.Fatalf("Expected write to succeed after the failed rotation, got %v", err)
+	}
+	err = file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := readLines(t, path); len(lines) != 4 || !strings.HasPrefix(lines[3], "line 03") {
+		t.Errorf("Expected all lines in the current file, got %q", lines)
+	}
+	content, err := os.ReadFile(errorFile)
+	if err != nil || !strings.Contains(string(content), "rotating log file") {
+		t.Errorf("Expected the rotation error to be reported, got %q (%v)", content, err)
+	}
+}
+
+// TestRotatingFileTime tests time-based rotation, also of a file written before a restart, and age retention
+func TestRotatingFileTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.log")
+	err := os.WriteFile(path, []byte("yesterday\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	yesterday := time.Date(2025, 1, 1, 23, 0, 0, 0, time.UTC)
+	err = os.Chtimes(path, yesterday, yesterday)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock := &rotatingFileClock{time: time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)}
+	file, err := NewRotatingFile(path, RotationOptions{Interval: 24 * time.Hour, MaxAge: 48 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.now = clock.now
+	_, err = file.Write([]byte("today\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = file.Write([]byte("still today\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.background.Wait()
+	rotated, err := file.rotatedFiles()
+	if err != nil || len(rotated) != 1 {
+		t.Fatalf("Expected one rotation at midnight, got %v (%v)", rotated, err)
+	}
+
+	// Three days later, the next rotation removes the backup of the first day
+	clock.set(time.Date(2025, 1, 5, 1, 0, 0, 0, time.UTC))
+	_, err = file.Write([]byte("later\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err = file.rotatedFiles()
+	if err != nil || len(rotated) != 1 || readLines(t, rotated[0])[0] != "today" {
+		t.Errorf("Expected only the backup of the second day, got %v (%v)", rotated, err)
+	}
+}
+
+// TestRotatingFileConcurrency tests that concurrent writes are neither lost nor split across rotations
+func TestRotatingFileConcurrency(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.log")
+	file, err := NewRotatingFile(path, RotationOptions{MaxSize: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wait sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wait.Add(1)
+		go func(i int) {
+			defer wait.Done()
+			for j := 0; j < 50; j++ {
+				_, err := fmt.Fprintf(file, "writer %02d line %02d\n", i, j)
+				if err != nil {
+					t.Error(err)
+				}
+			}
+		}(i)
+	}
+	wait.Wait()
+	err = file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := file.rotatedFiles()
+	if err != nil || len(rotated) < 10 {
+		t.Fatalf("Expected many rotations, got %d (%v)", len(rotated), err)
+	}
+	lines := readLines(t, path)
+	for _, name := range rotated {
+		lines = append(lines, readLines(t, name)...)
+	}
+	if len(lines) != 1000 {
+		t.Errorf("Expected 1000 lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "writer ") || len(line) != len("writer 00 line 00") {
+			t.Errorf("Unexpected line %q", line)
+		}
+	}
+}
@@ -0,0 +1,560 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+///////////////////////////////////
+// Background Datadog shipper
+///////////////////////////////////
+
+// Defaults of the Datadog shipper, used for the options that are not set.
+const (
+	defaultQueueSize      = 10000
+	defaultBatchSize      = 100
+	defaultFlushInterval  = 5 * time.Second
+	defaultMaxRetries     = 5
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 30 * time.Second
+	defaultCloseTimeout   = 10 * time.Second
+
+	// maxBatchBytes keeps batches below the 5 MB payload limit of the Datadog intake.
+	maxBatchBytes = 4 * 1024 * 1024
+)
+
+// errCloseTimeout is the delivery error of the batches that were not sent before the close deadline.
+var errCloseTimeout = errors.New("batch not sent before the close timeout elapsed")
+
+// newShipper creates a shipper sending to a Datadog intake and starts its background goroutine.
+//
+// Parameters:
+//   - name: The name of the shipped data (e.g. "logs") used in error messages and as spool subdirectory.
+//   - url: The Datadog intake URL.
+//   - config: The logging configuration providing the API key, the error file and the shipper options.
+//   - encode: The function creating the request body of a batch.
+//
+// Returns:
+//   - *shipper: The running shipper.
+//   - error: An error if the spool directory cannot be opened.
+func newShipper(name string, url string, config Config, encode func(items []json.RawMessage) ([]byte, error)) (*shipper, error) {
+	var spool *spool
+	if config.DatadogSpoolDirectory != "" {
+		var err error
+		spool, err = newSpool(filepath.Join(config.DatadogSpoolDirectory, name), config.DatadogSpoolMaxBytes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	send := func(body []byte) error {
+		_, err := sendPostRequestToDatadog(client, url, body, config.DatadogAPIKey)
+		return err
+	}
+	return startShipper(name, "Datadog", config.ErrorFileLocation, newShipperOptions(config), spool, encode, send), nil
+}
+
+// startShipper creates a shipper and starts its background goroutine.
+//
+// Parameters:
+//   - name: The name of the shipped data (e.g. "logs") used in error messages.
+//   - destination: The name of the receiver (e.g. "Datadog") used in error messages.
+//   - errorFile: The file receiving delivery errors.
+//   - options: The queue, batch and retry settings.
+//   - spool: The spool of undelivered batches; nil disables spooling.
+//   - encode: The function creating the request body of a batch.
+//   - send: The function sending a request body; a *StatusError wrapped in its error marks rejected requests.
+//
+// Returns:
+//   - *shipper: The running shipper.
+func startShipper(name string, destination string, errorFile string, options shipperOptions, spool *spool, encode func(items []json.RawMessage) ([]byte, error), send func(body []byte) error) *shipper {
+	shipper := &shipper{
+		name:        name,
+		destination: destination,
+		errorFile:   errorFile,
+		options:     options,
+		encode:      encode,
+		send:        send,
+		spool:       spool,
+		queue:       make(chan json.RawMessage, options.queueSize),
+		flushes:     make(chan chan error),
+		stop:        make(chan struct{}),
+		expired:     make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go shipper.run()
+	return shipper
+}
+
+// newShipperOptions reads the shipper options from the logging configuration and applies the defaults.
+//
+// Parameters:
+//   - config: The logging configuration.
+//
+// Returns:
+//   - shipperOptions: The options.
+func newShipperOptions(config Config) shipperOptions {
+	options := shipperOptions{
+		queueSize:      config.DatadogQueueSize,
+		batchSize:      config.DatadogBatchSize,
+		flushInterval:  config.DatadogFlushInterval,
+		maxRetries:     config.DatadogMaxRetries,
+		initialBackoff: defaultInitialBackoff,
+		maxBackoff:     defaultMaxBackoff,
+		closeTimeout:   defaultCloseTimeout,
+	}
+	if options.queueSize <= 0 {
+		options.queueSize = defaultQueueSize
+	}
+	if options.batchSize <= 0 {
+		options.batchSize = defaultBatchSize
+	}
+	if options.flushInterval <= 0 {
+		options.flushInterval = defaultFlushInterval
+	}
+	if options.maxRetries <= 0 {
+		options.maxRetries = defaultMaxRetries
+	}
+	return options
+}
+
+// enqueue adds an item to the queue without blocking. If the queue is full or the shipper is closed, the item is dropped.
+//
+// Parameters:
+//   - item: The JSON encoded item.
+//
+// Returns:
+//   - bool: True if the item was queued.
+func (shipper *shipper) enqueue(item json.RawMessage) bool {
+	shipper.closeMutex.RLock()
+	defer shipper.closeMutex.RUnlock()
+	if !shipper.closed {
+		select {
+		case shipper.queue <- item:
+			return true
+		default:
+		}
+	}
+	shipper.dropped.Add(1)
+	return false
+}
+
+// Flush sends all queued items and waits until they are delivered or have failed.
+//
+// Returns:
+//   - error: The last error of the batches sent by the flush or, if they succeeded, of the batches sent since the previous flush.
+func (shipper *shipper) Flush() error {
+	result := make(chan error, 1)
+	select {
+	case shipper.flushes <- result:
+		return <-result
+	case <-shipper.done:
+		return nil
+	}
+}
+
+// Close stops accepting items, sends the queued items and stops the background goroutine.
+// Failed requests are not retried once Close is called. Batches that are not sent within the close timeout
+// are added to the spool if one is configured and counted as failed otherwise.
+//
+// Returns:
+//   - error: Always nil; delivery errors are written to the error file.
+func (shipper *shipper) Close() error {
+	shipper.closeMutex.Lock()
+	if !shipper.closed {
+		shipper.closed = true
+		close(shipper.stop)
+		deadline := time.AfterFunc(shipper.options.closeTimeout, func() { close(shipper.expired) })
+		defer deadline.Stop()
+	}
+	shipper.closeMutex.Unlock()
+	<-shipper.done
+	return nil
+}
+
+// closeTimeoutElapsed reports whether the close timeout has elapsed, after which no more requests are sent.
+//
+// Returns:
+//   - bool: True if the close timeout has elapsed.
+func (shipper *shipper) closeTimeoutElapsed() bool {
+	select {
+	case <-shipper.expired:
+		return true
+	default:
+		return false
+	}
+}
+
+// Stats returns the delivery counters of the shipper.
+//
+// Returns:
+//   - DeliveryStats: The counters.
+func (shipper *shipper) Stats() DeliveryStats {
+	stats := DeliveryStats{
+		Queued:  len(shipper.queue),
+		Sent:    shipper.sent.Load(),
+		Dropped: shipper.dropped.Load(),
+		Failed:  shipper.failed.Load(),
+		Retries: shipper.retries.Load(),
+	}
+	if shipper.spool != nil {
+		stats.Spooled = shipper.spool.pendingItems()
+	}
+	return stats
+}
+
+// run collects queued items into batches and sends a batch when it is full, when the flush interval elapses,
+// on Flush and on Close. Spooled batches are replayed on start and when the flush interval elapses.
+func (shipper *shipper) run() {
+	defer close(shipper.done)
+	ticker := time.NewTicker(shipper.options.flushInterval)
+	defer ticker.Stop()
+	_ = shipper.replay()
+
+	for {
+		select {
+		case item := <-shipper.queue:
+			shipper.keepError(shipper.add(item))
+		case <-ticker.C:
+			shipper.keepError(shipper.sendBatch())
+			_ = shipper.replay()
+			shipper.reportDrops()
+		case result := <-shipper.flushes:
+			err := shipper.drain()
+			if err == nil {
+				err = shipper.unflushedErr
+			}
+			shipper.unflushedErr = nil
+			result <- err
+		case <-shipper.stop:
+			_ = shipper.drain()
+			shipper.reportDrops()
+			return
+		}
+	}
+}
+
+// keepError keeps a delivery error of a batch sent between flushes, so the next Flush reports it.
+//
+// Parameters:
+//   - err: The error of the batch; nil is ignored.
+func (shipper *shipper) keepError(err error) {
+	if err != nil {
+		shipper.unflushedErr = err
+	}
+}
+
+// add appends an item to the current batch and sends the batch when it is full.
+//
+// Parameters:
+//   - item: The JSON encoded item.
+//
+// Returns:
+//   - error: The error of the sent batch, if any.
+func (shipper *shipper) add(item json.RawMessage) error {
+	var err error
+	if len(shipper.batch) > 0 && shipper.batchBytes+len(item) > maxBatchBytes {
+		err = shipper.sendBatch()
+	}
+	shipper.batch = append(shipper.batch, item)
+	shipper.batchBytes += len(item)
+	if len(shipper.batch) >= shipper.options.batchSize {
+		err = errors.Join(err, shipper.sendBatch())
+	}
+	return err
+}
+
+// drain sends all items that are currently queued.
+//
+// Returns:
+//   - error: The last error of the sent batches.
+func (shipper *shipper) drain() error {
+	var lastErr error
+	for {
+		select {
+		case item := <-shipper.queue:
+			err := shipper.add(item)
+			if err != nil {
+				lastErr = err
+			}
+		default:
+			err := shipper.sendBatch()
+			if err != nil {
+				lastErr = err
+			}
+			return lastErr
+		}
+	}
+}
+
+// sendBatch sends the current batch, retrying with exponential backoff on rate limits, server errors and network errors.
+//
+// If a spool is configured, spooled batches are replayed first; while they cannot be delivered, the batch is added
+// to the spool without sending it, so batches are delivered in order. A batch that still fails with a retryable error
+// is spooled as well. Other batches that cannot be delivered are counted as failed and reported to the error file.
+//
+// Returns:
+//   - error: An error if the batch could not be delivered.
+func (shipper *shipper) sendBatch() error {
+	if len(shipper.batch) == 0 {
+		return nil
+	}
+	items := shipper.batch
+	shipper.batch = nil
+	shipper.batchBytes = 0
+
+	body, err := shipper.encode(items)
+	if err != nil {
+		shipper.fail(len(items), err)
+		return err
+	}
+	if shipper.spool != nil {
+		err = shipper.replay()
+		if err != nil {
+			return shipper.store(body, len(items), err)
+		}
+	}
+	err = shipper.post(body)
+	if err != nil {
+		if shipper.spool != nil && retryable(err) {
+			return shipper.store(body, len(items), err)
+		}
+		shipper.fail(len(items), err)
+		return err
+	}
+	shipper.sent.Add(uint64(len(items)))
+	return nil
+}
+
+// replay sends the spooled batches in order, each with a single attempt, and removes the delivered ones.
+// Replaying stops at the first batch failing with a retryable error; batches rejected otherwise are removed and counted as failed.
+//
+// Returns:
+//   - error: The error of the batch that stopped the replay.
+func (shipper *shipper) replay() error {
+	if shipper.spool == nil {
+		return nil
+	}
+	batches, err := shipper.spool.batches()
+	if err != nil {
+		return err
+	}
+
+	for _, batch := range batches {
+		if shipper.closeTimeoutElapsed() {
+			return errCloseTimeout
+		}
+		body, err := os.ReadFile(batch.path)
+		if err == nil {
+			err = shipper.send(body)
+			if err != nil && retryable(err) {
+				return err
+			}
+		}
+		if err != nil {
+			shipper.fail(batch.items, err)
+		} else {
+			shipper.sent.Add(uint64(batch.items))
+		}
+		err = os.Remove(batch.path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// store adds a batch that could not be delivered to the spool. Items of old batches removed to keep the spool
+// below its size limit are counted as dropped.
+//
+// Parameters:
+//   - body: The request body of the batch.
+//   - items: The number of items in the batch.
+//   - cause: The error that prevented the delivery.
+//
+// Returns:
+//   - error: The cause, as the batch is not delivered yet.
+func (shipper *shipper) store(body []byte, items int, cause error) error {
+	removed, err := shipper.spool.store(body, items)
+	if err != nil {
+		shipper.fail(items, errors.Join(cause, err))
+		return cause
+	}
+	if removed > 0 {
+		shipper.dropped.Add(uint64(removed))
+	}
+	return cause
+}
+
+// fail counts the items of a batch that cannot be delivered as failed and reports the error to the error file.
+//
+// Parameters:
+//   - items: The number of items in the batch.
+//   - err: The error.
+func (shipper *shipper) fail(items int, err error) {
+	shipper.failed.Add(uint64(items))
+	reportError(shipper.errorFile, fmt.Sprintf("Error occurred during sending of %d %v to %v:", items, shipper.name, shipper.destination), err)
+}
+
+// post sends a request body, retrying with exponential backoff and jitter on retryable errors.
+// Once the shipper is closed, the backoff is interrupted and the request is not retried; after the close timeout
+// no request is sent at all.
+//
+// Parameters:
+//   - body: The request body.
+//
+// Returns:
+//   - error: The last error if all attempts failed, or errCloseTimeout.
+func (shipper *shipper) post(body []byte) error {
+	backoff := shipper.options.initialBackoff
+	for attempt := 0; ; attempt++ {
+		if shipper.closeTimeoutElapsed() {
+			return errCloseTimeout
+		}
+		err := shipper.send(body)
+		if err == nil {
+			return nil
+		}
+		if attempt >= shipper.options.maxRetries || !retryable(err) {
+			return err
+		}
+
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > wait {
+			wait = statusErr.RetryAfter
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-shipper.stop:
+			timer.Stop()
+			return err
+		}
+		shipper.retries.Add(1)
+		backoff = min(backoff*2, shipper.options.maxBackoff)
+	}
+}
+
+// reportDrops writes the number of items dropped since the last report to the error file.
+func (shipper *shipper) reportDrops() {
+	dropped := shipper.dropped.Load()
+	if dropped > shipper.reportedDrops {
+		reportError(shipper.errorFile, fmt.Sprintf("%v %v queue is full:", shipper.destination, shipper.name), fmt.Sprintf("%d %v dropped", dropped-shipper.reportedDrops, shipper.name))
+		shipper.reportedDrops = dropped
+	}
+}
+
+// retryable reports whether a failed request should be retried: on rate limits (429), server errors (5xx) and network errors.
+//
+// Parameters:
+//   - err: The error of the request.
+//
+// Returns:
+//   - bool: True if the request should be retried.
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	return true
+}
+
+// newStatusError creates the error of a rejected request from its response.
+//
+// Parameters:
+//   - resp: The response.
+//
+// Returns:
+//   - *StatusError: The error, including the wait time of the Retry-After header.
+func newStatusError(resp *http.Response) *StatusError {
+	statusErr := &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		statusErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return statusErr
+}
+
+// Error returns the status of the rejected request.
+//
+// Returns:
+//   - string: The status, e.g. "503 Service Unavailable".
+func (err *StatusError) Error() string {
+	return err.Status
+}
+
+// encodeLogs creates the body of a Datadog logs intake request, a JSON array of the entries.
+//
+// Parameters:
+//   - items: The JSON encoded log entries.
+//
+// Returns:
+//   - []byte: The request body.
+//   - error: Always nil.
+func encodeLogs(items []json.RawMessage) ([]byte, error) {
+	return joinItems("[", items, "]"), nil
+}
+
+// encodeMetrics creates the body of a Datadog metrics series request.
+//
+// Parameters:
+//   - items: The JSON encoded metrics.
+//
+// Returns:
+//   - []byte: The request body.
+//   - error: Always nil.
+func encodeMetrics(items []json.RawMessage) ([]byte, error) {
+	return joinItems(`{"series":[`, items, "]}"), nil
+}
+
+// joinItems joins JSON items with commas between a prefix and a suffix.
+//
+// Parameters:
+//   - prefix: The text before the first item.
+//   - items: The JSON encoded items.
+//   - suffix: The text after the last item.
+//
+// Returns:
+//   - []byte: The joined items.
+func joinItems(prefix string, items []json.RawMessage, suffix string) []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString(prefix)
+	for i, item := range items {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		buffer.Write(item)
+	}
+	buffer.WriteString(suffix)
+	return buffer.Bytes()
+}
@@ -0,0 +1,205 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package logging
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// datadogServer records the gzip decoded request bodies and answers with the given status codes, then with 202.
+type datadogServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	bodies   [][]byte
+	statuses []int
+}
+
+func newDatadogServer(t *testing.T, statuses ...int) *datadogServer {
+	server := &datadogServer{statuses: statuses}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil || r.Header.Get("DD-API-KEY") != "key" {
+			t.Errorf("Expected gzip body with API key (%v)", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(reader)
+
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		status := http.StatusAccepted
+		if len(server.statuses) > 0 {
+			status, server.statuses = server.statuses[0], server.statuses[1:]
+		}
+		if status == http.StatusAccepted {
+			server.bodies = append(server.bodies, body)
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (server *datadogServer) received() [][]byte {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	return server.bodies
+}
+
+// TestShipperBatching tests that entries are sent in batches on Flush and on Close
+func TestShipperBatching(t *testing.T) {
+	server := newDatadogServer(t)
+	sink, err := NewDatadogSink(Config{DatadogAPIKey: "key", DatadogLogsURL: server.URL, DatadogBatchSize: 3, DatadogFlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewLogger(Config{}, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 7; i++ {
+		logger.Infof(nil, "entry %d", i)
+	}
+	err = logger.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bodies := server.received()
+	if len(bodies) != 3 {
+		t.Fatalf("Expected 3 batches, got %d", len(bodies))
+	}
+	var entries []map[string]interface{}
+	err = json.Unmarshal(bodies[2], &entries)
+	if err != nil || len(entries) != 1 || entries[0]["message"] != "entry 6" {
+		t.Errorf("Expected last batch with the last entry, got %s (%v)", bodies[2], err)
+	}
+
+	logger.Info(nil, "last")
+	err = logger.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info(nil, "after close")
+	expected := DeliveryStats{Sent: 8, Dropped: 1}
+	if stats := sink.Stats(); stats != expected || len(server.received()) != 4 {
+		t.Errorf("Expected stats %+v after close, got %+v", expected, stats)
+	}
+}
+
+// TestShipperRetries tests that rate limits and server errors are retried and client errors are not
+func TestShipperRetries(t *testing.T) {
+	server := newDatadogServer(t, http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusAccepted, http.StatusBadRequest)
+	config := Config{ErrorFileLocation: t.TempDir() + "/error.log", DatadogAPIKey: "key", DatadogMetrics: true, DatadogMetricsURL: server.URL}
+	logger, err := NewLogger(config, &memorySink{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.metrics.options.initialBackoff = time.Millisecond
+
+	// 429 and 503 are retried
+	logger.Metrics("requests", 1)
+	err = logger.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bodies := server.received()
+	if len(bodies) != 1 || string(bodies[0]) == "" {
+		t.Fatalf("Expected metric to be delivered after retries, got %q", bodies)
+	}
+	var metrics Metrics
+	err = json.Unmarshal(bodies[0], &metrics)
+	if err != nil || len(metrics.Series) != 1 || metrics.Series[0].Metric != "requests" {
+		t.Errorf("Unexpected metrics body %s (%v)", bodies[0], err)
+	}
+
+	// 400 is not retried
+	logger.Metrics("requests", 2)
+	err = logger.Flush()
+	if err == nil {
+		t.Errorf("Expected error for rejected batch")
+	}
+	expected := DeliveryStats{Sent: 1, Failed: 1, Retries: 2}
+	if stats := logger.MetricsStats(); stats != expected {
+		t.Errorf("Expected stats %+v, got %+v", expected, stats)
+	}
+	_ = logger.Close()
+}
+
+// TestShipperCloseInterruptsBackoff tests that Close interrupts the retry backoff and stops sending after the close timeout
+func TestShipperCloseInterruptsBackoff(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	sink, err := NewDatadogSink(Config{
+		ErrorFileLocation:     filepath.Join(t.TempDir(), "error.log"),
+		DatadogAPIKey:         "key",
+		DatadogLogsURL:        server.URL,
+		DatadogBatchSize:      1,
+		DatadogFlushInterval:  time.Hour,
+		DatadogSpoolDirectory: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.shipper.options.initialBackoff = time.Hour
+	sink.shipper.options.closeTimeout = 100 * time.Millisecond
+	logger, err := NewLogger(Config{}, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first batch waits an hour before its retry, the others are queued behind it
+	for i := 0; i < 3; i++ {
+		logger.Infof(nil, "entry %d", i)
+	}
+	for requests.Load() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(300 * time.Millisecond)
+
+	// Close interrupts the backoff and spools the first batch; the replay of the spool is tried once
+	// before the second batch, and the third batch is spooled without a request after the timeout
+	start := time.Now()
+	_ = logger.Close()
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected Close to return after the close timeout, took %v", elapsed)
+	}
+	expected := DeliveryStats{Spooled: 3}
+	if stats := sink.Stats(); stats != expected || requests.Load() != 2 {
+		t.Errorf("Expected stats %+v after 2 requests, got %+v after %d requests", expected, stats, requests.Load())
+	}
+}
@@ -0,0 +1,493 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+///////////////////////////////////
+// Console sink
+///////////////////////////////////
+
+// NewStdoutSink creates a sink writing log entries as JSON lines to stdout.
+//
+// Returns:
+//   - *ConsoleSink: The sink.
+func NewStdoutSink() *ConsoleSink {
+	return NewConsoleSink(os.Stdout)
+}
+
+// NewConsoleSink creates a sink writing log entries as JSON lines to a writer, in the format of the zap production logger.
+//
+// Parameters:
+//   - writer: The writer, e.g. os.Stdout.
+//
+// Returns:
+//   - *ConsoleSink: The sink.
+func NewConsoleSink(writer io.Writer) *ConsoleSink {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.FunctionKey = "func"
+	encoder := zapcore.NewJSONEncoder(encoderConfig)
+	return &ConsoleSink{core: zapcore.NewCore(encoder, zapcore.AddSync(writer), zapcore.DebugLevel)}
+}
+
+// Write writes an entry as JSON line, including its arguments and context.
+//
+// Parameters:
+//   - entry: The log entry.
+//
+// Returns:
+//   - error: An error if the entry cannot be written.
+func (sink *ConsoleSink) Write(entry Entry) error {
+	fields := []zapcore.Field{}
+	if len(entry.Arguments) > 0 {
+		fields = append(fields, zap.Any("Arguments", entry.Arguments))
+	}
+	for key, value := range entry.Context {
+		fields = append(fields, zap.Any(key, value))
+	}
+
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+	return sink.core.Write(zapcore.Entry{
+		Level:   entry.Level,
+		Time:    entry.Time,
+		Message: entry.Message,
+		Caller:  entry.Caller,
+		Stack:   entry.Stack,
+	}, fields)
+}
+
+// Name returns the output name of the sink, used for its output level.
+//
+// Returns:
+//   - string: OutputConsole.
+func (sink *ConsoleSink) Name() string {
+	return OutputConsole
+}
+
+// Close flushes the writer if it is buffered.
+//
+// Returns:
+//   - error: An error if the writer cannot be flushed.
+func (sink *ConsoleSink) Close() error {
+	return sink.core.Sync()
+}
+
+///////////////////////////////////
+// Local file sink
+///////////////////////////////////
+
+// NewFileSink creates a sink appending log entries to LocalLogsLocation, rotated and cleaned up according to the
+// LocalLogs options of the configuration. The entries are written in the same format as they are sent to Datadog.
+//
+// Parameters:
+//   - config: The logging configuration.
+//
+// Returns:
+//   - *FileSink: The sink.
+//   - error: An error if the file cannot be opened.
+func NewFileSink(config Config) (*FileSink, error) {
+	file, err := NewRotatingFile(config.LocalLogsLocation, RotationOptions{
+		MaxSize:           config.LocalLogsMaxSize,
+		Interval:          config.LocalLogsRotationInterval,
+		MaxAge:            config.LocalLogsMaxAge,
+		MaxBackups:        config.LocalLogsMaxBackups,
+		Compress:          config.LocalLogsCompress,
+		ErrorFileLocation: config.ErrorFileLocation,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{file: file, config: config}, nil
+}
+
+// Write appends an entry to the file.
+//
+// Parameters:
+//   - entry: The log entry.
+//
+// Returns:
+//   - error: An error if the entry cannot be written.
+func (sink *FileSink) Write(entry Entry) error {
+	line, err := fileLine(datadogLogBody(sink.config, entry))
+	if err != nil {
+		return err
+	}
+	_, err = sink.file.Write(line)
+	return err
+}
+
+// Name returns the output name of the sink, used for its output level.
+//
+// Returns:
+//   - string: OutputFile.
+func (sink *FileSink) Name() string {
+	return OutputFile
+}
+
+// Close closes the file.
+//
+// Returns:
+//   - error: An error if the file cannot be closed.
+func (sink *FileSink) Close() error {
+	return sink.file.Close()
+}
+
+///////////////////////////////////
+// Datadog sink
+///////////////////////////////////
+
+// NewDatadogSink creates a sink sending log entries to the Datadog logs intake at DatadogLogsURL.
+// Entries are sent in gzip compressed batches by a background goroutine, see the Datadog options of Config.
+// If DatadogSpoolDirectory is set, batches that cannot be delivered are stored in its "logs" subdirectory and replayed later.
+//
+// Parameters:
+//   - config: The logging configuration.
+//
+// Returns:
+//   - *DatadogSink: The sink.
+//   - error: An error if the API key or the URL are missing or the spool directory cannot be created.
+func NewDatadogSink(config Config) (*DatadogSink, error) {
+	if config.DatadogAPIKey == "" || config.DatadogLogsURL == "" {
+		return nil, fmt.Errorf("'DATADOG_LOGS' set to 'true' in 'config.yaml' file but 'DATADOG_API_KEY' and/or 'DATADOG_LOGS_URL' were not defined")
+	}
+	shipper, err := newShipper("logs", config.DatadogLogsURL, config, encodeLogs)
+	if err != nil {
+		return nil, err
+	}
+	return &DatadogSink{config: config, shipper: shipper}, nil
+}
+
+// Write queues an entry for sending. If the queue is full, the entry is dropped and counted in Stats.
+//
+// Parameters:
+//   - entry: The log entry.
+//
+// Returns:
+//   - error: An error if the entry cannot be encoded.
+func (sink *DatadogSink) Write(entry Entry) error {
+	item, err := json.Marshal(datadogLogBody(sink.config, entry)[0])
+	if err != nil {
+		return err
+	}
+	sink.shipper.enqueue(item)
+	return nil
+}
+
+// Name returns the output name of the sink, used for its output level.
+//
+// Returns:
+//   - string: OutputDatadog.
+func (sink *DatadogSink) Name() string {
+	return OutputDatadog
+}
+
+// Flush sends the queued entries and waits until they are delivered or have failed.
+//
+// Returns:
+//   - error: An error if a batch could not be delivered.
+func (sink *DatadogSink) Flush() error {
+	return sink.shipper.Flush()
+}
+
+// Close sends the queued entries and stops the background goroutine; entries written afterwards are dropped.
+//
+// Returns:
+//   - error: Always nil.
+func (sink *DatadogSink) Close() error {
+	return sink.shipper.Close()
+}
+
+// Stats returns the delivery counters of the sink.
+//
+// Returns:
+//   - DeliveryStats: The counters.
+func (sink *DatadogSink) Stats() DeliveryStats {
+	return sink.shipper.Stats()
+}
+
+// datadogLogBody creates the body of the Datadog logs intake request for an entry.
+//
+// Parameters:
+//   - config: The logging configuration providing the source, service and tags.
+//   - entry: The log entry.
+//
+// Returns:
+//   - []map[string]interface{}: The request body.
+func datadogLogBody(config Config, entry Entry) []map[string]interface{} {
+	body := []map[string]interface{}{
+		{
+			"ddsource":  config.DatadogSource,
+			"ddtags":    "env:" + config.DatadogStage + ",version:" + config.DatadogVersion,
+			"message":   entry.Message,
+			"time":      timeToString(entry.Time),
+			"service":   config.DatadogService,
+			"caller":    entryCallerToString(entry.Caller),
+			"stack":     entry.Stack,
+			"function":  entry.Function,
+			"status":    levelToString(entry.Level),
+			"arguments": entry.Arguments,
+		},
+	}
+
+	// Append body with context
+	for key, value := range entry.Context {
+		body[0][key] = value
+	}
+
+	// Correlate the entry with its trace
+	if entry.TraceID != "" {
+		body[0]["dd.trace_id"] = datadogTraceID(entry.TraceID)
+		body[0]["dd.span_id"] = datadogTraceID(entry.SpanID)
+	}
+	return body
+}
+
+///////////////////////////////////
+// OTLP sink
+///////////////////////////////////
+
+// NewOTLPSink creates a sink sending log entries to an OpenTelemetry collector at OTLPLogsURL (e.g. http://localhost:4318/v1/logs).
+// OTLPHeaders are added to every request, e.g. for authentication. Entries are sent in batches from a background
+// goroutine, with the default queue, batch and retry settings of the Datadog shipper; delivery errors are written to the error file.
+//
+// Parameters:
+//   - config: The logging configuration.
+//
+// Returns:
+//   - *OTLPSink: The sink.
+//   - error: An error if the URL is missing.
+func NewOTLPSink(config Config) (*OTLPSink, error) {
+	if config.OTLPLogsURL == "" {
+		return nil, fmt.Errorf("'OTLP_LOGS' set to 'true' in 'config.yaml' file but 'OTLP_LOGS_URL' was not defined")
+	}
+	sink := &OTLPSink{config: config, client: &http.Client{Timeout: 30 * time.Second}}
+	encode := func(records []json.RawMessage) ([]byte, error) {
+		return json.Marshal(otlpLogsRequest(config, records))
+	}
+	sink.shipper = startShipper("logs", "OTLP collector", config.ErrorFileLocation, newShipperOptions(Config{}), nil, encode, sink.send)
+	return sink, nil
+}
+
+// Write queues an entry for sending. If the queue is full or the sink is closed, the entry is dropped and counted in Stats.
+//
+// Parameters:
+//   - entry: The log entry.
+//
+// Returns:
+//   - error: An error if the entry cannot be encoded.
+func (sink *OTLPSink) Write(entry Entry) error {
+	record, err := json.Marshal(otlpLogRecord(entry))
+	if err != nil {
+		return err
+	}
+	sink.shipper.enqueue(record)
+	return nil
+}
+
+// Name returns the output name of the sink, used for its output level.
+//
+// Returns:
+//   - string: OutputOTLP.
+func (sink *OTLPSink) Name() string {
+	return OutputOTLP
+}
+
+// Flush sends the queued entries and waits until they are delivered or have failed.
+//
+// Returns:
+//   - error: An error if a batch could not be delivered.
+func (sink *OTLPSink) Flush() error {
+	return sink.shipper.Flush()
+}
+
+// Close sends the queued entries and stops the background goroutine; entries written afterwards are dropped.
+//
+// Returns:
+//   - error: Always nil.
+func (sink *OTLPSink) Close() error {
+	return sink.shipper.Close()
+}
+
+// Stats returns the delivery counters of the sink.
+//
+// Returns:
+//   - DeliveryStats: The counters.
+func (sink *OTLPSink) Stats() DeliveryStats {
+	return sink.shipper.Stats()
+}
+
+// send posts an export request to the collector.
+//
+// Parameters:
+//   - body: The JSON encoded export request.
+//
+// Returns:
+//   - error: An error if the request fails or is not accepted.
+func (sink *OTLPSink) send(body []byte) error {
+	req, err := http.NewRequest("POST", sink.config.OTLPLogsURL, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range sink.config.OTLPHeaders {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := sink.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("OTLP logs export failed: %w", newStatusError(resp))
+	}
+	return nil
+}
+
+// otlpLogsRequest creates an OTLP logs export request for a batch of log records, following the JSON encoding of the OTLP protocol.
+//
+// Parameters:
+//   - config: The logging configuration providing the service attributes.
+//   - records: The JSON encoded log records.
+//
+// Returns:
+//   - map[string]interface{}: The export request.
+func otlpLogsRequest(config Config, records []json.RawMessage) map[string]interface{} {
+	resource := map[string]interface{}{
+		"attributes": []map[string]interface{}{
+			otlpAttribute("service.name", config.DatadogService),
+			otlpAttribute("service.version", config.DatadogVersion),
+			otlpAttribute("deployment.environment", config.DatadogStage),
+		},
+	}
+	return map[string]interface{}{
+		"resourceLogs": []map[string]interface{}{
+			{
+				"resource": resource,
+				"scopeLogs": []map[string]interface{}{
+					{
+						"scope":      map[string]interface{}{"name": "github.com/ansys/aali-sharedtypes/pkg/logging"},
+						"logRecords": records,
+					},
+				},
+			},
+		},
+	}
+}
+
+// otlpLogRecord creates the OTLP log record of an entry.
+//
+// Parameters:
+//   - entry: The log entry.
+//
+// Returns:
+//   - map[string]interface{}: The log record.
+func otlpLogRecord(entry Entry) map[string]interface{} {
+	attributes := []map[string]interface{}{
+		otlpAttribute("code.function", entry.Function),
+		otlpAttribute("code.filepath", entry.Caller.File),
+		otlpAttribute("code.lineno", entry.Caller.Line),
+	}
+	if entry.Stack != "" {
+		attributes = append(attributes, otlpAttribute("exception.stacktrace", entry.Stack))
+	}
+	if len(entry.Arguments) > 0 {
+		attributes = append(attributes, otlpAttribute("arguments", fmt.Sprint(entry.Arguments...)))
+	}
+	for key, value := range entry.Context {
+		attributes = append(attributes, otlpAttribute(key, value))
+	}
+
+	record := map[string]interface{}{
+		"timeUnixNano":   strconv.FormatInt(entry.Time.UnixNano(), 10),
+		"severityNumber": otlpSeverityNumber(entry.Level),
+		"severityText":   entry.Level.CapitalString(),
+		"body":           map[string]interface{}{"stringValue": entry.Message},
+		"attributes":     attributes,
+	}
+	if entry.TraceID != "" {
+		record["traceId"] = entry.TraceID
+		record["spanId"] = entry.SpanID
+	}
+	return record
+}
+
+// otlpAttribute creates an OTLP key value attribute. Strings, booleans and integers keep their type, other values are formatted as string.
+//
+// Parameters:
+//   - key: The attribute key.
+//   - value: The attribute value.
+//
+// Returns:
+//   - map[string]interface{}: The attribute.
+func otlpAttribute(key string, value interface{}) map[string]interface{} {
+	var anyValue map[string]interface{}
+	switch typed := value.(type) {
+	case string:
+		anyValue = map[string]interface{}{"stringValue": typed}
+	case bool:
+		anyValue = map[string]interface{}{"boolValue": typed}
+	case int:
+		anyValue = map[string]interface{}{"intValue": strconv.Itoa(typed)}
+	case int64:
+		anyValue = map[string]interface{}{"intValue": strconv.FormatInt(typed, 10)}
+	default:
+		anyValue = map[string]interface{}{"stringValue": fmt.Sprint(typed)}
+	}
+	return map[string]interface{}{"key": key, "value": anyValue}
+}
+
+// otlpSeverityNumber maps a log level to the OTLP severity number.
+//
+// Parameters:
+//   - level: The log level.
+//
+// Returns:
+//   - int: The severity number.
+func otlpSeverityNumber(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 5
+	case zapcore.InfoLevel:
+		return 9
+	case zapcore.WarnLevel:
+		return 13
+	case zapcore.ErrorLevel:
+		return 17
+	default:
+		return 21
+	}
+}
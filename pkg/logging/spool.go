@@ -0,0 +1,166 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+///////////////////////////////////
+// Durable delivery spool
+///////////////////////////////////
+
+// defaultSpoolMaxBytes is the size limit of a spool directory if none is configured.
+const defaultSpoolMaxBytes = 100 * 1024 * 1024
+
+// spoolFileSuffix is the extension of spooled batches; files being written end with ".tmp" instead.
+const spoolFileSuffix = ".json"
+
+// newSpool opens a spool directory, creating it if needed. Incomplete files of an interrupted write are removed.
+//
+// Parameters:
+//   - directory: The directory of the spool.
+//   - maxBytes: The maximum total size of the spooled batches; 0 uses the default of 100 MB.
+//
+// Returns:
+//   - *spool: The spool.
+//   - error: An error if the directory cannot be created or read.
+func newSpool(directory string, maxBytes int64) (*spool, error) {
+	if maxBytes <= 0 {
+		maxBytes = defaultSpoolMaxBytes
+	}
+	err := os.MkdirAll(directory, 0755)
+	if err != nil {
+		return nil, err
+	}
+	stale, err := filepath.Glob(filepath.Join(directory, "*.tmp"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range stale {
+		_ = os.Remove(file)
+	}
+	return &spool{directory: directory, maxBytes: maxBytes}, nil
+}
+
+// store writes a batch to the spool. The file name orders the batches by the time they were stored and records
+// the number of items. The file is written under a temporary name and renamed, so a crash never leaves a partial batch.
+//
+// Parameters:
+//   - body: The request body of the batch.
+//   - items: The number of items in the batch.
+//
+// Returns:
+//   - int: The number of items of older batches that were removed to keep the spool below its size limit.
+//   - error: An error if the batch cannot be written.
+func (spool *spool) store(body []byte, items int) (int, error) {
+	spool.sequence++
+	name := fmt.Sprintf("%019d-%06d-%d", time.Now().UnixNano(), spool.sequence%1000000, items)
+	path := filepath.Join(spool.directory, name+spoolFileSuffix)
+	err := os.WriteFile(path+".tmp", body, 0644)
+	if err == nil {
+		err = os.Rename(path+".tmp", path)
+	}
+	if err != nil {
+		_ = os.Remove(path + ".tmp")
+		return 0, err
+	}
+	return spool.enforceLimit()
+}
+
+// batches returns the spooled batches, oldest first.
+//
+// Returns:
+//   - []spooledBatch: The batches.
+//   - error: An error if the directory cannot be read.
+func (spool *spool) batches() ([]spooledBatch, error) {
+	entries, err := os.ReadDir(spool.directory)
+	if err != nil {
+		return nil, err
+	}
+
+	batches := []spooledBatch{}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), spoolFileSuffix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		batch := spooledBatch{path: filepath.Join(spool.directory, entry.Name())}
+		parts := strings.Split(name, "-")
+		batch.items, _ = strconv.Atoi(parts[len(parts)-1])
+		info, err := entry.Info()
+		if err == nil {
+			batch.size = info.Size()
+		}
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
+
+// pendingItems returns the number of items in the spooled batches.
+//
+// Returns:
+//   - int: The number of items.
+func (spool *spool) pendingItems() int {
+	batches, _ := spool.batches()
+	items := 0
+	for _, batch := range batches {
+		items += batch.items
+	}
+	return items
+}
+
+// enforceLimit removes the oldest batches until the spool is below its size limit.
+//
+// Returns:
+//   - int: The number of items of the removed batches.
+//   - error: An error if the directory cannot be read.
+func (spool *spool) enforceLimit() (int, error) {
+	batches, err := spool.batches()
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, batch := range batches {
+		total += batch.size
+	}
+
+	removed := 0
+	for _, batch := range batches {
+		if total <= spool.maxBytes {
+			break
+		}
+		err := os.Remove(batch.path)
+		if err != nil {
+			return removed, err
+		}
+		total -= batch.size
+		removed += batch.items
+	}
+	return removed, nil
+}
@@ -0,0 +1,134 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestSpoolReplay tests that undeliverable batches are spooled and replayed in order after a restart
+func TestSpoolReplay(t *testing.T) {
+	spoolDirectory := t.TempDir()
+	config := Config{
+		ErrorFileLocation:     filepath.Join(t.TempDir(), "error.log"),
+		DatadogAPIKey:         "key",
+		DatadogBatchSize:      1,
+		DatadogFlushInterval:  time.Hour,
+		DatadogMaxRetries:     1,
+		DatadogSpoolDirectory: spoolDirectory,
+	}
+
+	// Endpoint unavailable: the first batch fails after a retry, the second is spooled behind it
+	server := newDatadogServer(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	config.DatadogLogsURL = server.URL
+	sink, err := NewDatadogSink(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.shipper.options.initialBackoff = time.Millisecond
+	logger, err := NewLogger(Config{}, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info(nil, "first")
+	logger.Info(nil, "second")
+	err = logger.Flush()
+	if err == nil {
+		t.Errorf("Expected flush error while the endpoint is unavailable")
+	}
+	_ = logger.Close()
+	expected := DeliveryStats{Retries: 1, Spooled: 2}
+	if stats := sink.Stats(); stats != expected || len(server.received()) != 0 {
+		t.Fatalf("Expected stats %+v, got %+v", expected, stats)
+	}
+
+	// After a restart, the spooled batches are replayed in order before new ones
+	server = newDatadogServer(t)
+	config.DatadogLogsURL = server.URL
+	sink, err = NewDatadogSink(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err = NewLogger(Config{}, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info(nil, "third")
+	_ = logger.Close()
+
+	messages := []string{}
+	for _, body := range server.received() {
+		var entries []map[string]interface{}
+		err = json.Unmarshal(body, &entries)
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, entries[0]["message"].(string))
+	}
+	if len(messages) != 3 || messages[0] != "first" || messages[1] != "second" || messages[2] != "third" {
+		t.Errorf("Expected batches in order, got %v", messages)
+	}
+	expected = DeliveryStats{Sent: 3}
+	if stats := sink.Stats(); stats != expected {
+		t.Errorf("Expected stats %+v, got %+v", expected, stats)
+	}
+}
+
+// TestSpoolLimit tests that the oldest batches are removed when the spool exceeds its size limit
+func TestSpoolLimit(t *testing.T) {
+	directory := t.TempDir()
+	err := os.WriteFile(filepath.Join(directory, "interrupted.json.tmp"), []byte("partial"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spool, err := newSpool(directory, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(directory, "interrupted.json.tmp")); !os.IsNotExist(err) {
+		t.Errorf("Expected incomplete file to be removed")
+	}
+
+	for i, body := range []string{"[1,2,3,4,5,6]", "[7,8,9]", "[10,11,12,13]"} {
+		removed, err := spool.store([]byte(body), 3+i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedRemoved := 0
+		if i == 2 {
+			expectedRemoved = 3
+		}
+		if removed != expectedRemoved {
+			t.Errorf("Store %d: expected %d removed items, got %d", i, expectedRemoved, removed)
+		}
+	}
+	batches, err := spool.batches()
+	if err != nil || len(batches) != 2 || spool.pendingItems() != 9 {
+		t.Errorf("Expected the two newest batches to remain, got %+v (%v)", batches, err)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package logging
+
+import (
+	"context"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+////////////////////////////////
+// Tracing
+////////////////////////////////
+
+// StartSpan starts an OpenTelemetry span using the global tracer provider, which records nothing until the
+// service registers a provider with otel.SetTracerProvider.
+// The parent is the span of the context or, if there is none, the span whose IDs are stored in the ContextMap
+// of the context, e.g. by the server interceptors. The returned context carries a copy of the ContextMap with
+// the TraceId, SpanId and TraceFlags of the new span, so log entries and outgoing calls using it belong to the span.
+//
+// Parameters:
+//   - ctx: The parent context.
+//   - name: The name of the span.
+//   - options: Options of the span, e.g. its kind and attributes.
+//
+// Returns:
+//   - context.Context: The context carrying the span and its ContextMap.
+//   - trace.Span: The span; end it with EndSpan.
+func StartSpan(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		parent := spanContextFromMap(FromContext(ctx))
+		if parent.IsValid() {
+			ctx = trace.ContextWithRemoteSpanContext(ctx, parent)
+		}
+	}
+	ctx, span := otel.Tracer(tracerName).Start(ctx, name, options...)
+
+	contextMap := FromContext(ctx).Copy()
+	spanContext := span.SpanContext()
+	if spanContext.IsValid() {
+		contextMap.Set(TraceId, spanContext.TraceID().String())
+		contextMap.Set(SpanId, spanContext.SpanID().String())
+		contextMap.Set(TraceFlags, spanContext.TraceFlags().String())
+	}
+	return NewContext(ctx, contextMap), span
+}
+
+// EndSpan ends a span, recording the error and setting the error status if the operation failed.
+//
+// Parameters:
+//   - span: The span.
+//   - err: The error of the operation; nil if it succeeded.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// spanContext returns the span context of a context, or the span context stored in its ContextMap if it has no span.
+//
+// Parameters:
+//   - ctx: The context.
+//
+// Returns:
+//   - trace.SpanContext: The span context; invalid if there is none.
+func spanContext(ctx context.Context) trace.SpanContext {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if spanContext.IsValid() {
+		return spanContext
+	}
+	return spanContextFromMap(FromContext(ctx))
+}
+
+// spanContextFromMap rebuilds the span context from the TraceId, SpanId and TraceFlags of a ContextMap,
+// so the sampling decision of the span is kept. Without TraceFlags the span context is not sampled.
+//
+// Parameters:
+//   - ctx: The ContextMap; may be nil.
+//
+// Returns:
+//   - trace.SpanContext: The remote span context; invalid if the IDs are missing or malformed.
+func spanContextFromMap(ctx *ContextMap) trace.SpanContext {
+	if ctx == nil {
+		return trace.SpanContext{}
+	}
+	traceValue, _ := ctx.Get(TraceId)
+	spanValue, _ := ctx.Get(SpanId)
+	traceString, _ := traceValue.(string)
+	spanString, _ := spanValue.(string)
+	traceID, err := trace.TraceIDFromHex(traceString)
+	if err != nil {
+		return trace.SpanContext{}
+	}
+	spanID, err := trace.SpanIDFromHex(spanString)
+	if err != nil {
+		return trace.SpanContext{}
+	}
+	var flags trace.TraceFlags
+	flagsValue, _ := ctx.Get(TraceFlags)
+	flagsString, _ := flagsValue.(string)
+	parsed, err := strconv.ParseUint(flagsString, 16, 8)
+	if err == nil {
+		flags = trace.TraceFlags(parsed)
+	}
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: flags,
+		Remote:     true,
+	})
+}
+
+// injectTraceContext adds the W3C traceparent and tracestate of a span context to a carrier.
+//
+// Parameters:
+//   - spanContext: The span context; nothing is added if it is invalid.
+//   - carrier: The headers or metadata to add to.
+func injectTraceContext(spanContext trace.SpanContext, carrier propagation.TextMapCarrier) {
+	if !spanContext.IsValid() {
+		return
+	}
+	propagation.TraceContext{}.Inject(trace.ContextWithSpanContext(context.Background(), spanContext), carrier)
+}
+
+// datadogTraceID converts a hexadecimal OpenTelemetry trace or span ID to the decimal ID used by Datadog,
+// which consists of the lower 64 bits.
+//
+// Parameters:
+//   - id: The hexadecimal ID.
+//
+// Returns:
+//   - string: The decimal ID; empty if the ID is malformed.
+func datadogTraceID(id string) string {
+	if len(id) > 16 {
+		id = id[len(id)-16:]
+	}
+	value, err := strconv.ParseUint(id, 16, 64)
+	if err != nil {
+		return ""
+	}
+	return strconv.FormatUint(value, 10)
+}
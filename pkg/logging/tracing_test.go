@@ -0,0 +1,219 @@
+// Copyright (C) 2025 ANSYS, Inc. and/or its affiliates.
+// SPDX-License-Identifier: MIT
+//
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package logging
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// newTestTracer registers a tracer provider recording spans in memory for the duration of a test.
+func newTestTracer(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+// TestSpans tests that spans nest through the ContextMap and that log entries carry their IDs
+func TestSpans(t *testing.T) {
+	exporter := newTestTracer(t)
+	sink := &memorySink{}
+	logger, err := NewLogger(Config{LogLevel: "info"}, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &ContextMap{}
+	ctx.Set(InstructionGuid, "instruction-1")
+	parentCtx, parent := StartSpan(NewContext(context.Background(), ctx), "parent")
+
+	// The child only knows the ContextMap of the parent, like the shared clients
+	_, child := StartSpan(NewContext(context.Background(), FromContext(parentCtx)), "child")
+	logger.Info(FromContext(parentCtx), "in parent")
+	EndSpan(child, errors.New("failed"))
+	EndSpan(parent, nil)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	childSpan, parentSpan := spans[0], spans[1]
+	if childSpan.Parent.SpanID() != parentSpan.SpanContext.SpanID() || childSpan.SpanContext.TraceID() != parentSpan.SpanContext.TraceID() {
+		t.Errorf("Expected the child span to be a child of the parent span")
+	}
+	if childSpan.Status.Code != codes.Error || len(childSpan.Events) != 1 {
+		t.Errorf("Expected the error to be recorded, got %+v", childSpan.Status)
+	}
+	if _, ok := ctx.Get(TraceId); ok {
+		t.Errorf("Expected the ContextMap of the caller to be unchanged")
+	}
+
+	// Log entries
+	entry := sink.entries[0]
+	if entry.TraceID != parentSpan.SpanContext.TraceID().String() || entry.SpanID != parentSpan.SpanContext.SpanID().String() {
+		t.Errorf("Expected entry to carry the IDs of the parent span, got %q and %q", entry.TraceID, entry.SpanID)
+	}
+	body := datadogLogBody(Config{}, entry)
+	if body[0]["dd.trace_id"] != datadogTraceID(entry.TraceID) || body[0]["dd.span_id"] == "" {
+		t.Errorf("Expected Datadog trace correlation, got %v", body[0])
+	}
+	record := otlpLogRecord(entry)
+	if record["traceId"] != entry.TraceID || record["spanId"] != entry.SpanID {
+		t.Errorf("Expected OTLP trace correlation, got %v", record)
+	}
+}
+
+// TestTraceFlags tests that the sampling decision stored in a ContextMap is propagated unchanged
+func TestTraceFlags(t *testing.T) {
+	tests := []struct {
+		flags       string
+		traceparent string
+	}{
+		{"00", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+		{"01", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{"", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+	}
+	for _, tt := range tests {
+		ctx := &ContextMap{}
+		ctx.Set(TraceId, "4bf92f3577b34da6a3ce929d0e0e4736")
+		ctx.Set(SpanId, "00f067aa0ba902b7")
+		if tt.flags != "" {
+			ctx.Set(TraceFlags, tt.flags)
+		}
+		header := http.Header{}
+		InjectHTTPHeaders(ctx, header)
+		if traceparent := header.Get("traceparent"); traceparent != tt.traceparent {
+			t.Errorf("Expected traceparent %q for flags %q, got %q", tt.traceparent, tt.flags, traceparent)
+		}
+	}
+
+	// Spans started from the ContextMap store the flags of the new span
+	newTestTracer(t)
+	ctx := &ContextMap{}
+	ctx.Set(TraceId, "4bf92f3577b34da6a3ce929d0e0e4736")
+	ctx.Set(SpanId, "00f067aa0ba902b7")
+	ctx.Set(TraceFlags, "01")
+	spanCtx, span := StartSpan(NewContext(context.Background(), ctx), "child")
+	defer span.End()
+	if flags, _ := FromContext(spanCtx).Get(TraceFlags); flags != "01" {
+		t.Errorf("Expected the child span to be sampled, got flags %v", flags)
+	}
+}
+
+// TestDatadogTraceID tests the conversion to Datadog trace IDs
+func TestDatadogTraceID(t *testing.T) {
+	if id := datadogTraceID("4bf92f3577b34da6a3ce929d0e0e4736"); id != "11803532876627986230" {
+		t.Errorf("Unexpected trace ID %v", id)
+	}
+	if id := datadogTraceID("00f067aa0ba902b7"); id != "67667974448284343" {
+		t.Errorf("Unexpected span ID %v", id)
+	}
+	if id := datadogTraceID("invalid"); id != "" {
+		t.Errorf("Expected empty ID for invalid input, got %v", id)
+	}
+}
+
+// TestGRPCTracePropagation tests that the server span continues the trace of the client
+func TestGRPCTracePropagation(t *testing.T) {
+	exporter := newTestTracer(t)
+	clientCtx, clientSpan := StartSpan(context.Background(), "client", trace.WithSpanKind(trace.SpanKindClient))
+
+	var outgoing metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		outgoing, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	err := UnaryClientInterceptor()(clientCtx, "/test/Method", nil, nil, nil, invoker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outgoing.Get("traceparent")) != 1 {
+		t.Fatalf("Expected a traceparent, got %v", outgoing)
+	}
+
+	var handlerMap *ContextMap
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		handlerMap = FromContext(ctx)
+		return nil, nil
+	}
+	_, err = UnaryServerInterceptor()(metadata.NewIncomingContext(context.Background(), outgoing), nil, &grpc.UnaryServerInfo{FullMethod: "/test/Method"}, handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientSpan.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 || spans[0].Name != "/test/Method" || spans[0].SpanKind != trace.SpanKindServer {
+		t.Fatalf("Expected a server span, got %+v", spans)
+	}
+	if spans[0].Parent.SpanID() != spans[1].SpanContext.SpanID() || !spans[0].Parent.IsRemote() {
+		t.Errorf("Expected the server span to be a child of the client span")
+	}
+	if traceID, _ := handlerMap.Get(TraceId); traceID != spans[1].SpanContext.TraceID().String() {
+		t.Errorf("Expected the handler ContextMap to carry the trace ID, got %v", traceID)
+	}
+}
+
+// TestHTTPTracePropagation tests that the middleware span continues the trace of the transport
+func TestHTTPTracePropagation(t *testing.T) {
+	exporter := newTestTracer(t)
+	server := httptest.NewServer(HTTPMiddleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {})))
+	defer server.Close()
+
+	clientCtx, clientSpan := StartSpan(context.Background(), "client")
+	request, err := http.NewRequestWithContext(clientCtx, http.MethodGet, server.URL+"/path", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := (&http.Client{Transport: NewHTTPTransport(nil)}).Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	clientSpan.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 || spans[0].Name != "GET /path" {
+		t.Fatalf("Expected a server span, got %+v", spans)
+	}
+	if spans[0].Parent.SpanID() != spans[1].SpanContext.SpanID() || spans[0].SpanContext.TraceID() != spans[1].SpanContext.TraceID() {
+		t.Errorf("Expected the server span to be a child of the client span")
+	}
+}
@@ -23,9 +23,16 @@
 package logging
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
 	"sync"
+	"sync/atomic"
+	"time"
 
-	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
 )
 
 // ContextKey defines the supported context keys.
@@ -42,12 +49,19 @@ const (
 	Rest_Call_Id    ContextKey = "restCallId"
 	Rest_Call       ContextKey = "restCall"
 	UserMail        ContextKey = "userMail"
+	Component       ContextKey = "component"
+	TraceId         ContextKey = "traceId"
+	SpanId          ContextKey = "spanId"
+	TraceFlags      ContextKey = "traceFlags"
 )
 
-// Initialize the global logger variable.
-var Log loggerWrapper
+// tracerName is the name of the OpenTelemetry tracer of the shared clients.
+const tracerName = "github.com/ansys/aali-sharedtypes"
 
-// Initialize config variables
+// Log is the global logger; it writes to stdout until InitLogger configures it.
+var Log = &Logger{sinks: []Sink{NewStdoutSink()}}
+
+// Initialize config variables; they mirror the config of the global logger, Logger instances do not read them.
 var ERROR_FILE_LOCATION string
 var LOG_LEVEL string
 var LOCAL_LOGS bool
@@ -68,15 +82,149 @@ type Config struct {
 	LogLevel          string
 	LocalLogs         bool
 	LocalLogsLocation string
-	DatadogLogs       bool
-	DatadogSource     string
-	DatadogStage      string
-	DatadogVersion    string
-	DatadogService    string
-	DatadogAPIKey     string
-	DatadogLogsURL    string
-	DatadogMetrics    bool
-	DatadogMetricsURL string
+	// Rotation and retention of the local log file; zero values disable the option, MaxSize defaults to 100 MB
+	LocalLogsMaxSize          int64
+	LocalLogsRotationInterval time.Duration
+	LocalLogsMaxAge           time.Duration
+	LocalLogsMaxBackups       int
+	LocalLogsCompress         bool
+	DatadogLogs               bool
+	DatadogSource             string
+	DatadogStage              string
+	DatadogVersion            string
+	DatadogService            string
+	DatadogAPIKey             string
+	DatadogLogsURL            string
+	DatadogMetrics            bool
+	DatadogMetricsURL         string
+	// Aggregation of counters, gauges and histograms; zero values use 10 seconds and the Prometheus default buckets
+	MetricsInterval  time.Duration
+	HistogramBuckets []float64
+	// Options of the background shipping to Datadog; zero values use the defaults
+	DatadogQueueSize     int
+	DatadogBatchSize     int
+	DatadogFlushInterval time.Duration
+	DatadogMaxRetries    int
+	// Directory where batches that could not be delivered are stored and replayed from; empty disables the spool
+	DatadogSpoolDirectory string
+	DatadogSpoolMaxBytes  int64
+	// OpenTelemetry logs
+	OTLPLogs    bool
+	OTLPLogsURL string
+	OTLPHeaders map[string]string
+	// Minimum levels of the outputs and of components or packages; empty output levels write all entries passing LogLevel
+	ConsoleLogLevel   string
+	LocalLogLevel     string
+	DatadogLogLevel   string
+	OTLPLogLevel      string
+	LogLevelOverrides map[string]string
+}
+
+// Logger writes log entries to a set of sinks. Loggers are independent of each other and of the package globals,
+// so several differently configured loggers can be used in one process.
+type Logger struct {
+	config     Config
+	sinks      []Sink
+	metrics    *shipper
+	levels     atomic.Pointer[levelState]
+	levelMutex sync.Mutex
+
+	// Counters, gauges and histograms, and the goroutine sending them to Datadog every MetricsInterval
+	registry        metricsRegistry
+	metricsStop     chan struct{}
+	metricsDone     chan struct{}
+	metricsStopOnce sync.Once
+}
+
+// LevelSettings contains the log levels of a logger: the log level, the minimum levels of the outputs by
+// output name (e.g. "datadog") and the log levels of components or packages.
+type LevelSettings struct {
+	Level      string            `json:"level,omitempty"`
+	Outputs    map[string]string `json:"outputs,omitempty"`
+	Components map[string]string `json:"components,omitempty"`
+}
+
+// levelState holds the parsed log levels of a logger; it is replaced as a whole when a level changes.
+type levelState struct {
+	level      zapcore.Level
+	outputs    map[string]zapcore.Level
+	components map[string]zapcore.Level
+}
+
+// Sink is a destination of log entries, e.g. the console, a local file, Datadog or an OTLP collector.
+// Write is called for every entry that passes the log level of the logger; it may be called concurrently.
+// Sinks with a `Name() string` method are subject to the output level of that name.
+type Sink interface {
+	Write(entry Entry) error
+	Close() error
+}
+
+// Entry represents a single log entry passed to the sinks.
+type Entry struct {
+	Level     zapcore.Level
+	Time      time.Time
+	Message   string
+	Caller    zapcore.EntryCaller
+	Stack     string
+	Function  string
+	Arguments []interface{}
+	Context   map[string]interface{}
+	TraceID   string // Hexadecimal ID of the trace the entry belongs to, if any
+	SpanID    string // Hexadecimal ID of the span the entry belongs to, if any
+}
+
+// ConsoleSink writes log entries as JSON lines to a writer, e.g. stdout.
+type ConsoleSink struct {
+	mutex sync.Mutex
+	core  zapcore.Core
+}
+
+// FileSink appends log entries in the Datadog format to a rotating local file.
+type FileSink struct {
+	file   *RotatingFile
+	config Config
+}
+
+// RotatingFile is a file writer that rotates the file by size and time and removes old rotated files.
+// It keeps the file open between writes and is safe for concurrent use.
+type RotatingFile struct {
+	mutex    sync.Mutex
+	path     string
+	options  RotationOptions
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	closed   bool
+	now      func() time.Time
+	rename   func(oldPath string, newPath string) error
+
+	// Compression and cleanup of rotated files run in the background
+	background   sync.WaitGroup
+	cleanupMutex sync.Mutex
+}
+
+// RotationOptions contains the rotation and retention settings of a RotatingFile.
+type RotationOptions struct {
+	MaxSize           int64         // Size in bytes at which the file is rotated; 0 uses 100 MB
+	Interval          time.Duration // Interval at which the file is rotated, e.g. 24 hours; 0 disables time-based rotation
+	MaxAge            time.Duration // Age after which rotated files are removed; 0 keeps them
+	MaxBackups        int           // Number of rotated files that are kept; 0 keeps all
+	Compress          bool          // If true, rotated files are compressed with gzip
+	ErrorFileLocation string        // File receiving errors of the background compression and cleanup
+}
+
+// DatadogSink sends log entries to the Datadog logs intake in batches, from a background goroutine.
+type DatadogSink struct {
+	config  Config
+	shipper *shipper
+}
+
+// OTLPSink sends log entries to an OpenTelemetry collector using OTLP over HTTP with JSON encoding,
+// in batches from a background goroutine.
+type OTLPSink struct {
+	config  Config
+	client  *http.Client
+	shipper *shipper
 }
 
 // ContextMap represents a context for managing key-value pairs with specific context keys. It allows setting, retrieving,
@@ -85,11 +233,153 @@ type ContextMap struct {
 	data sync.Map
 }
 
-// loggerWrapper represents a wrapper for the zap.Logger to provide custom logging functionality.
-type loggerWrapper struct {
-	lw *zap.Logger
+// contextMapKey is the context.Context key of an attached ContextMap.
+type contextMapKey struct{}
+
+// propagatedKey maps a context key to the header and gRPC metadata name it is propagated with.
+type propagatedKey struct {
+	key    ContextKey
+	header string
+}
+
+// propagatedKeys are the context keys sent along with outgoing gRPC calls and HTTP requests.
+// Servers read them from client supplied metadata and headers, so they are not trusted.
+var propagatedKeys = []propagatedKey{
+	{InstructionGuid, "x-aali-instruction-guid"},
+	{ClientGuid, "x-aali-client-guid"},
+	{UserMail, "x-aali-user-mail"},
+	{ReaderGuid, "x-aali-reader-guid"},
+	{Rest_Call_Id, "x-aali-rest-call-id"},
+}
+
+// contextServerStream is a gRPC server stream whose context carries a ContextMap.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// lookupCarrier reads W3C trace context headers or metadata through a lookup function.
+type lookupCarrier func(name string) string
+
+// contextTransport is an HTTP RoundTripper that propagates the ContextMap of the request context as headers.
+type contextTransport struct {
+	base http.RoundTripper
+}
+
+// shipper sends JSON items to a Datadog intake or an OTLP collector in batches from a background goroutine.
+// Items are queued in a bounded queue; if the queue is full, new items are dropped and counted.
+type shipper struct {
+	name        string
+	destination string
+	errorFile   string
+	options     shipperOptions
+	encode      func(items []json.RawMessage) ([]byte, error)
+	send        func(body []byte) error
+	spool       *spool
+
+	queue      chan json.RawMessage
+	flushes    chan chan error
+	stop       chan struct{}
+	expired    chan struct{} // Closed when the close timeout has elapsed
+	done       chan struct{}
+	closeMutex sync.RWMutex
+	closed     bool
+
+	// Only accessed by the background goroutine
+	batch         []json.RawMessage
+	batchBytes    int
+	reportedDrops uint64
+	unflushedErr  error // Last delivery error since the last Flush
+
+	sent    atomic.Uint64
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+	retries atomic.Uint64
+}
+
+// shipperOptions contains the queue, batch and retry settings of a shipper.
+type shipperOptions struct {
+	queueSize      int
+	batchSize      int
+	flushInterval  time.Duration
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	closeTimeout   time.Duration
+}
+
+// DeliveryStats contains the delivery counters of the background shipping to Datadog.
+type DeliveryStats struct {
+	Queued  int    // Items waiting in the queue
+	Sent    uint64 // Items accepted by Datadog
+	Dropped uint64 // Items dropped because the queue was full or the shipper was closed
+	Failed  uint64 // Items that could not be delivered after all retries
+	Retries uint64 // Retried requests
+	Spooled int    // Items in batches waiting in the spool directory for replay
+}
+
+// spool stores batches that could not be delivered in a directory, so they can be replayed in order, also after a restart.
+// The directory must not be shared by several shippers.
+type spool struct {
+	directory string
+	maxBytes  int64
+	sequence  int
 }
 
+// spooledBatch is a batch stored in a spool directory.
+type spooledBatch struct {
+	path  string
+	items int
+	size  int64
+}
+
+// StatusError is wrapped by the errors of requests that Datadog or an OTLP collector did not accept.
+type StatusError struct {
+	StatusCode int
+	Status     string
+	RetryAfter time.Duration // Wait time requested by the Retry-After header, if any
+}
+
+// metricsRegistry aggregates the counters, gauges and histograms of a logger in process.
+// The zero value is ready to use.
+type metricsRegistry struct {
+	mutex     sync.Mutex
+	series    map[string]*metricSeries
+	buckets   []float64
+	hostname  string
+	errorFile string
+	lastFlush time.Time
+}
+
+// metricSeries is the aggregated state of a metric with one set of tags.
+// Totals are cumulative, as exposed to Prometheus; Datadog receives the difference to the last flush.
+type metricSeries struct {
+	name string
+	kind string // counter, gauge or histogram
+	tags []string
+
+	value        float64 // Total of a counter or last value of a gauge
+	flushedValue float64 // Total of a counter at the last flush
+
+	count        uint64   // Observations of a histogram
+	sum          float64  // Sum of the observations of a histogram
+	bucketCounts []uint64 // Observations per bucket upper bound, not cumulative
+	flushedCount uint64
+	flushedSum   float64
+	min          float64 // Smallest observation since the last flush
+	max          float64 // Largest observation since the last flush
+}
+
+// MetricType is the Datadog type of a metric series.
+type MetricType int
+
+const (
+	MetricTypeUnspecified MetricType = 0
+	MetricTypeCount       MetricType = 1
+	MetricTypeRate        MetricType = 2
+	MetricTypeGauge       MetricType = 3
+)
+
 // Point represents a data point in a time series metric.
 type Point struct {
 	Timestamp int64   `json:"timestamp"`
@@ -105,9 +395,11 @@ type Resource struct {
 // Metric represents a time series metric.
 type Metric struct {
 	Metric    string     `json:"metric"`
-	Type      int        `json:"type"`
+	Type      MetricType `json:"type"`
+	Interval  int64      `json:"interval,omitempty"`
 	Points    []Point    `json:"points"`
-	Resources []Resource `json:"resources"`
+	Tags      []string   `json:"tags,omitempty"`
+	Resources []Resource `json:"resources,omitempty"`
 }
 
 // Metrics represents a collection of metrics.